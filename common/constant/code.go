@@ -8,14 +8,17 @@ const (
 
 	CodeJWTParseError = 2
 
-	CodeBadRequest    = 400 // 无效请求
-	CodeUnauthorized  = 401 // 未授权
-	CodeForbidden     = 403 // 禁止访问
-	CodeNotFound      = 404 // 资源不存在
-	CodeServerError   = 500 // 服务器错误
-	CodeUserNotExist  = 100 // 用户不存在
-	CodePasswordError = 101 // 密码错误
-	CodeUserDisabled  = 102 // 用户被禁用
+	CodeBadRequest      = 400 // 无效请求
+	CodeUnauthorized    = 401 // 未授权
+	CodeForbidden       = 403 // 禁止访问
+	CodeNotFound        = 404 // 资源不存在
+	CodeConflict        = 409 // 请求冲突
+	CodeServerError     = 500 // 服务器错误
+	CodeUserNotExist    = 100 // 用户不存在
+	CodePasswordError   = 101 // 密码错误
+	CodeUserDisabled    = 102 // 用户被禁用
+	CodeCaptchaRequired = 103 // 需要验证码
+	CodeTooManyRequests = 429 // 请求过于频繁
 
 	CodeDatabaseError = 900 // 数据库错误
 	CodeCacheError    = 901 // 缓存错误
@@ -8,19 +8,138 @@ const (
 	// DefaultPassword 默认密码
 	DefaultPassword = "123456"
 
-	ID     = "ID"
-	EmpID  = "empId"
-	UserID = "userId"
+	PasswordMinLength = 8  // 密码最小长度
+	PasswordMaxLength = 64 // 密码最大长度，bcrypt 只使用前 72 字节
 
-	RedisKeyShopStatus = "shop::status"
+	// 登录防暴力破解，失败次数在窗口期内累计
+	LoginFailWindow       = 15 * 60 // 失败计数窗口，秒
+	LoginCaptchaThreshold = 3       // 用户名失败达到该次数后需要验证码，并开始递增等待
+	LoginMaxFailures      = 5       // 用户名失败达到该次数后锁定
+	LoginIPMaxFailures    = 20      // 同一 IP 失败达到该次数后锁定
+	LoginLockDuration     = 15 * 60 // 锁定时长，秒
+	LoginMaxDelay         = 30      // 两次尝试之间的最大等待，秒
+	CaptchaTTL            = 5 * 60  // 验证码有效期，秒
+
+	ID        = "ID"
+	SessionID = "SID"
+
+	// 令牌受众，管理端、用户端、骑手端的令牌互不通用
+	AudienceAdmin = "admin"
+	AudienceUser  = "user"
+	AudienceRider = "rider"
+
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+
+	RedisKeyShopStatus     = "shop::status"
+	RedisKeyShopPause      = "shop::pause"        // 忙碌暂停接单，值为恢复营业的时间戳
+	RedisKeySerial         = "serial::"           // 流水号序列
+	RedisKeyIdempotency    = "idempotency::"      // 幂等请求结果
+	RedisKeyPasswordReset  = "password::reset::"  // 需要修改初始密码的员工
+	RedisKeySession        = "session::"          // 登录会话
+	RedisKeySessionAccount = "session::account::" // 账号下的登录会话
+	RedisKeyLoginFail      = "login::fail::"      // 登录失败次数
+	RedisKeyLoginLock      = "login::lock::"      // 登录锁定
+	RedisKeyLoginDelay     = "login::delay::"     // 登录失败后的等待期
+	RedisKeyCaptcha        = "captcha::"          // 图形验证码答案
+
+	IdempotencyKeyHeader = "Idempotency-Key"
+	IdempotentReplayed   = "Idempotent-Replayed" // 响应头，表示是重放的幂等响应
+	RequestIDHeader      = "X-Request-ID"
+	RequestID            = "requestId" // 请求ID在上下文中的键
 
 	DefaultPageSize = 10 // 默认分页大小
 	DefaultPageNum  = 1  // 默认页码
 
 	WeChatLoginUrl = "https://api.weixin.qq.com/sns/jscode2session"
 
-	CacheDishKey    = "dish::"
-	CacheSetmealKey = "setmeal::"
+	CacheDishKey       = "dish::"
+	CacheSetmealKey    = "setmeal::"
+	CachePermissionKey = "permission::" // 员工权限
+)
+
+// 店铺营业相关常量
+const (
+	ShopClosed = 0 // 打烊
+	ShopOpen   = 1 // 营业中
+
+	SpecialDayOpen   = 0 // 特殊日期按指定时段营业
+	SpecialDayClosed = 1 // 特殊日期全天休息
+
+	ShopMaxPauseMinutes = 240 // 忙碌暂停最长时长，分钟
+	ShopNextOpenDays    = 14  // 计算下次营业时间时最多向后查找的天数
+)
+
+// 配送区域相关常量
+const (
+	ZoneEnable  = 1 // 区域启用
+	ZoneDisable = 0 // 区域停用
+
+	ZoneRadius  = 1 // 按配送距离划分
+	ZonePolygon = 2 // 按多边形范围划分
+)
+
+// 购物车相关常量
+const (
+	CartMaxNumber = 99 // 单行商品的最大数量
+
+	CartLineOK          = 0 // 可以下单
+	CartLineUnavailable = 1 // 商品已删除、停售或口味已变更
+	CartLineRepriced    = 2 // 当前价格与加入时不同
+)
+
+// 评价相关常量
+const (
+	ReviewShow   = 1 // 评价显示
+	ReviewHidden = 0 // 评价被商家隐藏
+
+	ReviewMinRating    = 1   // 最低星级
+	ReviewMaxRating    = 5   // 最高星级
+	ReviewMaxImages    = 9   // 每条评价最多的图片数
+	ReviewMaxImageSize = 255 // 图片地址最大长度
+
+	ReviewLowThreshold = 3.0 // 低分菜品报表默认的平均分阈值
+	ReviewLowMinCount  = 3   // 低分菜品报表默认的最少评分数
+)
+
+// 积分流水类型
+const (
+	PointsEarn    = 1 // 订单完成获得
+	PointsRedeem  = 2 // 下单抵扣
+	PointsReverse = 3 // 订单退款扣回已获得的积分
+	PointsReturn  = 4 // 订单退款退还抵扣的积分
+	PointsAdjust  = 5 // 商家人工调整
+)
+
+// 库存流水状态
+const (
+	StockDeducted = 1 // 已扣减
+	StockRestored = 2 // 订单取消，已归还
+	StockExpired  = 3 // 扣减后库存被重置，不再归还
+)
+
+// 储值钱包相关常量
+const (
+	WalletRecharge = 1 // 充值
+	WalletPay      = 2 // 订单支付
+	WalletRefund   = 3 // 订单退款
+	WalletAdjust   = 4 // 商家人工调整
+
+	RechargeUnpaid = 0 // 待支付
+	RechargePaid   = 1 // 已入账
+	RechargeClosed = 2 // 超时未支付，已关闭
+
+	RechargePrefix    = "WR" // 充值单号前缀，支付回调按前缀区分充值单和订单
+	RechargeMaxAmount = 5000 // 单次充值上限，元
+)
+
+// 菜品口味相关常量
+const (
+	FlavorSingle   = 0 // 单选
+	FlavorMultiple = 1 // 多选
+
+	FlavorOptional = 0 // 可不选
+	FlavorRequired = 1 // 必须选择
 )
 
 // 员工状态常量
@@ -66,6 +185,140 @@ const (
 	Paid   = 1 // 已支付
 	ReFund = 2 // 退款
 
-	NotifyOrder = 1 // 通知接单
-	UserRemind  = 2 // 用户催单
+	PayWeChat = 1 // 微信支付
+	PayWallet = 3 // 余额支付，2 保留给支付宝
+
+	TablewareByMeal   = 1 // 按餐量提供餐具
+	TablewareByNumber = 0 // 选择具体餐具数量
+
+	NotifyOrder   = 1 // 通知接单
+	UserRemind    = 2 // 用户催单
+	RiderAssign   = 3 // 通知骑手有新的配送订单
+	OrderChange   = 4 // 通知用户订单状态变更
+	KitchenChange = 5 // 通知后厨工单变更
+	OrderReady    = 6 // 通知商家和骑手订单已出餐
+	RefundAlert   = 7 // 通知商家退款重试次数用尽，需要人工处理
+
+	DeliveryScheduled = 0 // 预约送达
+	DeliveryImmediate = 1 // 立即送出
+
+	OrderReleased = 0 // 已进入后厨队列
+	OrderHeld     = 1 // 预约单未到出餐提前期，对后厨隐藏
+
+	MealPreparing = 0 // 后厨制作中
+	MealReady     = 1 // 已全部出餐，等待取餐
+)
+
+// 后厨工单相关常量
+const (
+	TicketQueued  = 1 // 待制作
+	TicketCooking = 2 // 制作中
+	TicketReady   = 3 // 已出餐
+
+	DefaultStation     = 0 // 未分配档口的分类归入默认档口
+	DefaultStationName = "默认档口"
+)
+
+// 打印任务相关常量
+const (
+	PrintReceipt = 1 // 顾客小票
+	PrintKitchen = 2 // 后厨单
+
+	PrintPending   = 1 // 待打印
+	PrintSucceeded = 2 // 已发送到打印机
+	PrintFailed    = 3 // 打印失败，等待重试
+
+	PrintMaxRetry = 5 // 打印最大重试次数
+)
+
+// 配送时段相关常量
+const (
+	SlotEnable  = 1 // 时段启用
+	SlotDisable = 0 // 时段停用
+
+	ScheduleDays = 2 // 可以预约今天和明天
+)
+
+// 退款单状态
+const (
+	RefundRequested  = 1 // 已申请
+	RefundProcessing = 2 // 已提交支付平台，等待回调
+	RefundSucceeded  = 3 // 退款成功
+	RefundFailed     = 4 // 退款失败，等待重试
+
+	RefundMaxRetry = 5 // 退款最大重试次数
+)
+
+// 订单状态流转的操作人类型
+const (
+	OperatorSystem = 0 // 系统（定时任务、支付回调）
+	OperatorUser   = 1 // 用户
+	OperatorAdmin  = 2 // 商家员工
+	OperatorRider  = 3 // 骑手
+)
+
+// 骑手相关常量
+const (
+	RiderEnable  = 1 // 骑手启用
+	RiderDisable = 0 // 骑手禁用
+
+	RiderOffline = 0 // 休息中
+	RiderOnline  = 1 // 接单中
+
+	RiderUnassigned = 0 // 未指派骑手
+	RiderAssigned   = 1 // 已指派，等待骑手接单
+	RiderAccepted   = 2 // 骑手已接单，前往商家
+	RiderPickedUp   = 3 // 骑手已取餐，配送中
+	RiderDelivered  = 4 // 已送达
+)
+
+// 优惠券相关常量
+const (
+	CouponEnable  = 1 // 优惠券启用
+	CouponDisable = 0 // 优惠券停用
+
+	CouponFixed     = 1 // 立减券
+	CouponPercent   = 2 // 折扣券
+	CouponThreshold = 3 // 满减券
+	CouponFreePack  = 4 // 免打包费券
+
+	CouponScopeAll      = 0 // 全部商品可用
+	CouponScopeCategory = 1 // 指定分类可用
+	CouponScopeDish     = 2 // 指定菜品可用
+
+	UserCouponUnused  = 1 // 未使用
+	UserCouponUsed    = 2 // 已使用
+	UserCouponExpired = 3 // 已过期，仅用于查询
+)
+
+// 审计日志的对象类型
+const (
+	AuditEmployee = "employee"
+	AuditRole     = "role"
+	AuditCategory = "category"
+	AuditDish     = "dish"
+	AuditSetmeal  = "setmeal"
+	AuditShop     = "shop"
+	AuditOrder    = "order"
+	AuditMember   = "member"
+	AuditWallet   = "wallet"
+)
+
+// 审计日志的操作类型
+const (
+	AuditCreate   = "create"
+	AuditUpdate   = "update"
+	AuditDelete   = "delete"
+	AuditStatus   = "status"   // 启用、禁用、起售、停售、营业状态
+	AuditPassword = "password" // 修改或重置密码
+	AuditConfirm  = "confirm"  // 接单
+	AuditReject   = "reject"   // 拒单
+	AuditCancel   = "cancel"   // 商家取消订单
+	AuditDelivery = "delivery" // 派送
+	AuditComplete = "complete" // 完成
+	AuditAssign   = "assign"   // 指派骑手
+	AuditUnlock   = "unlock"   // 解除登录锁定
+	AuditAdjust   = "adjust"   // 人工调整积分、余额
+	AuditReprint  = "reprint"  // 补打小票
+	AuditRefund   = "refund"   // 重新提交退款
 )
@@ -16,6 +16,13 @@ const (
 	MsgJWTUnKnownSigningMethod = "JWT未知的签名方法"
 	MsgJWTParseFail            = "JWT解析失败"
 	MsgJWTWithoutToken         = "JWT未携带token"
+	MsgJWTUnknownAudience      = "JWT未知的受众"
+	MsgJWTSecretMissing        = "JWT签名密钥未配置"
+	MsgJWTSignFail             = "生成令牌失败"
+	MsgSessionExpired          = "登录已失效，请重新登录"
+	MsgRefreshTokenReused      = "刷新令牌已失效，请重新登录"
+	MsgRefreshTokenSuccess     = "刷新令牌成功"
+	MsgLogoutFail              = "退出登录失败"
 
 	MsgGetAccountInfoFail = "未能获取当前账户信息"
 	MsgGetIDFail          = "获取ID失败"
@@ -27,6 +34,9 @@ const (
 
 	MsgKeyDuplicateError = "Duplicate entry"
 
+	MsgIdempotencyProcessing = "请求正在处理中，请勿重复提交"
+	MsgIdempotencyKeyReused  = "幂等键已被其他请求使用"
+
 	MsgQuerySuccess = "查询成功"
 	MsgQueryFail    = "查询失败"
 
@@ -59,6 +69,44 @@ const (
 	MsgEmployeeUpdateSuccess         = "员工信息更新成功"
 	MsgPageQueryEmployeeFail         = "员工分页查询失败"
 	MsgPageQueryEmployeeSuccess      = "员工分页查询成功"
+	MsgPasswordHashFail              = "密码加密失败"
+	MsgPasswordUpgradeFail           = "密码哈希升级失败"
+	MsgPasswordLength                = "密码长度必须为8-64位"
+	MsgPasswordTooWeak               = "密码必须同时包含字母和数字"
+	MsgPasswordIsDefault             = "不能使用默认密码"
+	MsgPasswordNotChanged            = "新密码不能与原密码相同"
+	MsgPasswordChangeRequired        = "请先修改初始密码"
+	MsgLoginFail                     = "用户名或密码错误"
+	MsgLoginLocked                   = "登录失败次数过多，请稍后再试"
+	MsgLoginTooFrequent              = "登录过于频繁，请稍后再试"
+	MsgCaptchaRequired               = "请输入验证码"
+	MsgCaptchaIncorrect              = "验证码错误或已过期"
+	MsgCaptchaFail                   = "生成验证码失败"
+	MsgEmployeeUnlockSuccess         = "账号解锁成功"
+	MsgEmployeeUnlockFail            = "账号解锁失败"
+)
+
+// 角色权限相关消息
+const (
+	MsgPermissionDenied    = "没有操作权限"
+	MsgRoleNotFound        = "角色不存在"
+	MsgRoleBuiltin         = "内置角色不能删除"
+	MsgRoleOwnerReadonly   = "店主角色不能修改"
+	MsgRoleInUse           = "角色下还有员工，不能删除"
+	MsgRoleGrantDenied     = "不能授予超出自身权限的角色"
+	MsgRoleSelfEdit        = "不能修改自己所属的角色"
+	MsgPermissionInvalid   = "无效的权限码"
+	MsgRoleCreateFail      = "新增角色失败"
+	MsgRoleUpdateFail      = "修改角色失败"
+	MsgRoleDeleteFail      = "删除角色失败"
+	MsgRoleQueryFail       = "查询角色失败"
+	MsgPermissionQueryFail = "查询权限失败"
+)
+
+// 审计日志相关消息
+const (
+	MsgAuditRecordFail = "记录操作日志失败"
+	MsgAuditQueryFail  = "查询操作日志失败"
 )
 
 // 分类相关消息
@@ -95,6 +143,13 @@ const (
 const (
 	MsgShoppingCartAddFail    = "购物车添加失败"
 	MsgShoppingCartAddSuccess = "购物车添加成功"
+	MsgCartLineNotFound       = "购物车中没有该商品"
+	MsgCartNumberInvalid      = "商品数量超出范围"
+	MsgCartUpdateFail         = "修改购物车失败"
+	MsgCartUpdateSuccess      = "修改购物车成功"
+	MsgCartRepriced           = "商品价格已变动"
+	MsgCartChanged            = "购物车中有商品价格变动，请确认后重新下单"
+	MsgCartValidateFail       = "购物车校验失败"
 )
 
 const MsgNotExistDefaultAddress = "未设置默认地址"
@@ -109,6 +164,159 @@ const (
 	MsgPayFail            = "支付失败"
 	MsgOrderNotFound      = "未查询到订单"
 	MsgOrderStatusError   = "订单状态错误"
+	MsgOrderHeld          = "预约单未到出餐时间，暂不能接单"
+	MsgOrderStatusChanged = "订单状态已变更，请刷新后重试"
+	MsgOrderAmountChanged = "订单金额已变化，请刷新后重试"
+	MsgGoodsUnavailable   = "商品已下架"
+	MsgStockNotEnough     = "商品库存不足"
 	MsgOrderCancelFail    = "订单取消失败"
 	MsgOrderCancelSuccess = "订单取消成功"
 )
+
+// 骑手相关消息
+const (
+	MsgRiderNotFound         = "骑手不存在"
+	MsgRiderDisabled         = "骑手已禁用"
+	MsgRiderLoginSuccess     = "骑手登录成功"
+	MsgRiderLoginFail        = "骑手登录失败"
+	MsgRiderLogoutSuccess    = "骑手退出成功"
+	MsgRiderUnavailable      = "暂无可接单的骑手"
+	MsgRiderOrderStatusError = "配送状态错误"
+	MsgRiderAssignSuccess    = "指派骑手成功"
+	MsgRiderAssignFail       = "指派骑手失败"
+	MsgRiderLocationFail     = "上报位置失败"
+)
+
+// 退款相关消息
+const (
+	MsgRefundNotFound    = "未查询到退款单"
+	MsgRefundFail        = "退款失败"
+	MsgRefundSuccess     = "退款成功"
+	MsgRefundExhausted   = "退款多次提交失败，需要人工处理"
+	MsgRefundStatusError = "退款已成功，不能重新提交"
+	MsgRefundResubmitted = "已重新提交退款"
+)
+
+// 优惠券相关消息
+const (
+	MsgCouponNotFound      = "优惠券不存在"
+	MsgCouponParamError    = "优惠券参数错误"
+	MsgCouponUnavailable   = "优惠券不可用"
+	MsgCouponNotMatch      = "订单不满足优惠券使用条件"
+	MsgCouponSoldOut       = "优惠券已领完"
+	MsgCouponClaimLimit    = "已达到领取上限"
+	MsgCouponCannotDelete  = "优惠券启用中或已被领取，不能删除"
+	MsgCouponEnabled       = "优惠券启用中，不能修改"
+	MsgCouponClaimSuccess  = "领取成功"
+	MsgCouponClaimFail     = "领取失败"
+	MsgCouponStatusFail    = "更新优惠券状态失败"
+	MsgCouponStatusSuccess = "更新优惠券状态成功"
+)
+
+// 配送时段相关消息
+const (
+	MsgSlotNotFound      = "配送时段不存在"
+	MsgSlotTimeInvalid   = "配送时段格式应为HH:MM，且开始时间早于结束时间"
+	MsgSlotRequired      = "预约送达需要选择配送时段"
+	MsgSlotUnavailable   = "所选配送时段不可预约"
+	MsgSlotFull          = "所选配送时段已约满"
+	MsgSlotStatusFail    = "更新配送时段状态失败"
+	MsgSlotStatusSuccess = "更新配送时段状态成功"
+	MsgDeliveryStatusErr = "无效的配送方式"
+)
+
+// 店铺营业相关消息
+const (
+	MsgShopClosed         = "店铺已打烊"
+	MsgShopPaused         = "店铺忙碌中，暂停接单"
+	MsgBusinessHoursError = "营业时段格式应为HH:MM，开始时间早于结束时间且同一天的时段不能重叠"
+	MsgSpecialDayError    = "日期格式应为YYYY-MM-DD"
+	MsgSlotOutsideHours   = "所选配送时段不在营业时间内"
+	MsgShopPauseSuccess   = "已暂停接单"
+	MsgShopResumeSuccess  = "已恢复接单"
+	MsgBusinessHoursSaved = "营业时间保存成功"
+	MsgSpecialDayNotFound = "特殊营业日不存在"
+)
+
+// 配送区域相关消息
+const (
+	MsgZoneNotFound      = "配送区域不存在"
+	MsgZoneParamError    = "配送区域参数错误"
+	MsgZoneStatusFail    = "更新配送区域状态失败"
+	MsgZoneStatusSuccess = "更新配送区域状态成功"
+	MsgOutOfRange        = "超出配送范围"
+	MsgBelowMinAmount    = "未达到起送金额"
+	MsgAddressLocateFail = "无法定位收货地址，请重新选择地址"
+	MsgShopLocateFail    = "店铺地址解析失败"
+	MsgCartPreviewFail   = "购物车结算预览失败"
+)
+
+// 评价相关消息
+const (
+	MsgReviewNotAllowed    = "只能评价自己已完成的订单"
+	MsgReviewExpired       = "已超过评价期限"
+	MsgReviewExists        = "该订单已评价"
+	MsgReviewRatingInvalid = "评分应为1到5星"
+	MsgReviewImageInvalid  = "评价图片地址不合法或数量超出限制"
+	MsgReviewItemInvalid   = "评价的商品不属于该订单"
+	MsgReviewNotFound      = "评价不存在"
+	MsgReviewSubmitFail    = "评价提交失败"
+	MsgReviewSubmitSuccess = "评价提交成功"
+	MsgReviewReplyFail     = "回复评价失败"
+	MsgReviewReplySuccess  = "回复评价成功"
+	MsgReviewStatusFail    = "更新评价状态失败"
+	MsgReviewStatusSuccess = "更新评价状态成功"
+)
+
+// 积分相关消息
+const (
+	MsgPointsNotEnough     = "积分不足"
+	MsgPointsInvalid       = "积分数量错误"
+	MsgPointsDisabled      = "暂不支持积分抵扣"
+	MsgPointsExceed        = "积分抵扣超出上限"
+	MsgPointsReasonMissing = "请填写积分调整原因"
+	MsgPointsAdjustFail    = "积分调整失败"
+	MsgPointsAdjustSuccess = "积分调整成功"
+	MsgUserNotFound        = "用户不存在"
+)
+
+// 后厨相关消息
+const (
+	MsgStationNotFound     = "档口不存在"
+	MsgStationParamError   = "档口名称不能为空，分类不能重复"
+	MsgStationCategoryUsed = "分类已分配给其他档口"
+	MsgTicketNotFound      = "工单不存在"
+	MsgTicketStatusError   = "工单状态错误"
+	MsgTicketStatusChanged = "工单状态已变更，请刷新后重试"
+	MsgTicketUpdateFail    = "更新工单状态失败"
+	MsgTicketUpdateSuccess = "更新工单状态成功"
+)
+
+// 打印相关消息
+const (
+	MsgPrintFail        = "打印失败"
+	MsgPrintSuccess     = "已发送到打印机"
+	MsgPrintStatusError = "订单未接单或已取消，不能打印"
+)
+
+// 储值钱包相关消息
+const (
+	MsgWalletNotEnough     = "余额不足"
+	MsgWalletAmountInvalid = "金额错误"
+	MsgWalletReasonMissing = "请填写余额调整原因"
+	MsgWalletAdjustFail    = "余额调整失败"
+	MsgWalletAdjustSuccess = "余额调整成功"
+	MsgRechargeFail        = "充值失败"
+	MsgRechargeNotFound    = "未查询到充值单"
+	MsgPayMethodInvalid    = "不支持的支付方式"
+)
+
+// 菜品口味相关消息
+const (
+	MsgFlavorInvalid   = "口味设置错误，口味组和选项名称不能为空或重复，加价不能为负"
+	MsgFlavorRequired  = "请选择口味"
+	MsgFlavorNotFound  = "口味选项不存在"
+	MsgFlavorSingle    = "该口味只能选择一项"
+	MsgFlavorChanged   = "商品口味已变更，请重新选择"
+	MsgFlavorUnmarshal = "口味数据解析失败"
+)
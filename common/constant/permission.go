@@ -0,0 +1,38 @@
+package constant
+
+// 权限码，格式为 资源:操作
+const (
+	PermAll = "*" // 全部权限，仅店主角色使用
+
+	PermEmployeeView     = "employee:view"     // 查看员工
+	PermEmployeeManage   = "employee:manage"   // 新增、修改、启用禁用员工
+	PermEmployeePassword = "employee:password" // 重置其他员工的密码
+	PermRoleManage       = "role:manage"       // 管理角色和权限
+	PermAuditView        = "audit:view"        // 查看操作日志
+
+	PermMenuView   = "menu:view"   // 查看分类、菜品、套餐
+	PermMenuManage = "menu:manage" // 维护分类、菜品、套餐，上传图片
+
+	PermOrderView      = "order:view"      // 查看订单
+	PermOrderOperate   = "order:operate"   // 接单、派送、完成、指派骑手、补打小票
+	PermOrderCancel    = "order:cancel"    // 拒单、取消订单、重新提交退款
+	PermKitchenOperate = "kitchen:operate" // 查看后厨工单、更新出餐进度
+
+	PermReportView   = "report:view"   // 查看统计报表和工作台
+	PermReportExport = "report:export" // 导出运营数据
+
+	PermShopManage   = "shop:manage"   // 设置营业状态
+	PermCouponManage = "coupon:manage" // 管理优惠券
+	PermReviewManage = "review:manage" // 回复、隐藏评价
+	PermMemberManage = "member:manage" // 查看会员积分和余额流水、人工调整
+	PermRiderView    = "rider:view"    // 查看骑手
+	PermRiderManage  = "rider:manage"  // 新增、修改、启用禁用骑手
+)
+
+// 内置角色编码
+const (
+	RoleOwner   = "owner"   // 店主，拥有全部权限
+	RoleManager = "manager" // 店长
+	RoleCashier = "cashier" // 收银
+	RoleKitchen = "kitchen" // 后厨
+)
@@ -1,11 +1,11 @@
 package database
 
 import (
-	"crypto/md5"
-	"encoding/hex"
 	"fmt"
 
+	"takeout/common/constant"
 	"takeout/common/global"
+	"takeout/common/utils"
 	"takeout/model/entity"
 )
 
@@ -17,10 +17,37 @@ func MigrateDB() error {
 		&entity.Category{},
 		&entity.Dish{},
 		&entity.DishFlavor{},
+		&entity.DishFlavorOption{},
 		&entity.Setmeal{},
 		&entity.SetmealDish{},
 		&entity.User{},
 		&entity.ShoppingCart{},
+		&entity.Order{},
+		&entity.OrderDetail{},
+		&entity.OrderStatusHistory{},
+		&entity.StockLog{},
+		&entity.Refund{},
+		&entity.CouponTemplate{},
+		&entity.CouponScope{},
+		&entity.UserCoupon{},
+		&entity.Rider{},
+		&entity.Role{},
+		&entity.RolePermission{},
+		&entity.AuditLog{},
+		&entity.DeliverySlot{},
+		&entity.BusinessHours{},
+		&entity.SpecialDay{},
+		&entity.DeliveryZone{},
+		&entity.Review{},
+		&entity.ReviewItem{},
+		&entity.PointsLog{},
+		&entity.Wallet{},
+		&entity.WalletLog{},
+		&entity.WalletRecharge{},
+		&entity.KitchenStation{},
+		&entity.KitchenStationCategory{},
+		&entity.KitchenTicket{},
+		&entity.PrintJob{},
 	)
 
 	if err != nil {
@@ -28,7 +55,72 @@ func MigrateDB() error {
 	}
 
 	// 初始化管理员账号
-	return initAdminAccount()
+	if err = initAdminAccount(); err != nil {
+		return err
+	}
+
+	// 初始化内置角色，放在管理员账号之后以便为其分配店主角色
+	return initRoles()
+}
+
+// builtinRoles 内置角色及其默认权限，只在角色不存在时创建，之后可由店主调整
+var builtinRoles = []struct {
+	code        string
+	name        string
+	permissions []string
+}{
+	{constant.RoleOwner, "店主", []string{constant.PermAll}},
+	{constant.RoleManager, "店长", []string{
+		constant.PermEmployeeView, constant.PermEmployeeManage, constant.PermEmployeePassword, constant.PermAuditView,
+		constant.PermMenuView, constant.PermMenuManage,
+		constant.PermOrderView, constant.PermOrderOperate, constant.PermOrderCancel, constant.PermKitchenOperate,
+		constant.PermReportView, constant.PermReportExport,
+		constant.PermShopManage, constant.PermCouponManage, constant.PermReviewManage, constant.PermMemberManage,
+		constant.PermRiderView, constant.PermRiderManage,
+	}},
+	{constant.RoleCashier, "收银", []string{
+		constant.PermMenuView,
+		constant.PermOrderView, constant.PermOrderOperate,
+		constant.PermReportView, constant.PermRiderView,
+	}},
+	{constant.RoleKitchen, "后厨", []string{
+		constant.PermMenuView, constant.PermOrderView, constant.PermKitchenOperate,
+	}},
+}
+
+// 初始化内置角色，并为还没有角色的历史员工分配角色：admin 为店主，其他员工为收银
+func initRoles() error {
+	roleIDs := make(map[string]int, len(builtinRoles))
+	for _, item := range builtinRoles {
+		role := entity.Role{Code: item.code, Name: item.name, Builtin: true}
+		result := global.DB.Where("code = ?", item.code).Attrs(role).FirstOrCreate(&role)
+		if result.Error != nil {
+			return fmt.Errorf("初始化角色失败: %w", result.Error)
+		}
+		roleIDs[item.code] = role.ID
+		if result.RowsAffected == 0 {
+			continue
+		}
+		permissions := make([]entity.RolePermission, 0, len(item.permissions))
+		for _, p := range item.permissions {
+			permissions = append(permissions, entity.RolePermission{RoleID: role.ID, Permission: p})
+		}
+		if err := global.DB.Create(&permissions).Error; err != nil {
+			return fmt.Errorf("初始化角色权限失败: %w", err)
+		}
+	}
+
+	err := global.DB.Model(&entity.Employee{}).Where("role_id = 0 AND username = ?", "admin").
+		UpdateColumn("role_id", roleIDs[constant.RoleOwner]).Error
+	if err != nil {
+		return fmt.Errorf("初始化员工角色失败: %w", err)
+	}
+	err = global.DB.Model(&entity.Employee{}).Where("role_id = 0").
+		UpdateColumn("role_id", roleIDs[constant.RoleCashier]).Error
+	if err != nil {
+		return fmt.Errorf("初始化员工角色失败: %w", err)
+	}
+	return nil
 }
 
 // 初始化管理员账号
@@ -41,17 +133,20 @@ func initAdminAccount() error {
 	}
 
 	// 创建默认管理员账号
-	md5Password := md5.Sum([]byte("123456"))
-	md5PasswordStr := hex.EncodeToString(md5Password[:])
+	password, err := utils.HashPassword(constant.DefaultPassword)
+	if err != nil {
+		return fmt.Errorf("创建管理员账号失败: %w", err)
+	}
 
 	admin := entity.Employee{
-		Username: "admin",
-		Password: md5PasswordStr,
-		Name:     "管理员",
-		Phone:    "13800000000",
-		Sex:      "1",
-		IdNumber: "110101199001010001",
-		Status:   1,
+		Username:           "admin",
+		Password:           password,
+		MustChangePassword: true,
+		Name:               "管理员",
+		Phone:              "13800000000",
+		Sex:                "1",
+		IdNumber:           "110101199001010001",
+		Status:             1,
 	}
 
 	result := global.DB.Create(&admin)
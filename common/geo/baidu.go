@@ -0,0 +1,78 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"takeout/common/utils"
+)
+
+const (
+	baiduGeocodingUrl = "https://api.map.baidu.com/geocoding/v3"
+	baiduDrivingUrl   = "https://api.map.baidu.com/directionlite/v1/driving"
+)
+
+// BaiduProvider 百度地图地理服务，距离为驾车路线距离
+type BaiduProvider struct {
+	ak string
+}
+
+func NewBaiduProvider(ak string) *BaiduProvider {
+	return &BaiduProvider{ak: ak}
+}
+
+// baiduResponse 百度接口的通用响应，status 为 0 表示成功
+type baiduResponse struct {
+	Status  int             `json:"status"`
+	Message string          `json:"message"`
+	Result  json.RawMessage `json:"result"`
+}
+
+func (p *BaiduProvider) get(url string, params map[string]string, result any) error {
+	params["output"] = "json"
+	params["ak"] = p.ak
+	body, err := utils.DoGET(url, params)
+	if err != nil {
+		return err
+	}
+	var resp baiduResponse
+	if err = json.Unmarshal([]byte(body), &resp); err != nil {
+		return err
+	}
+	if resp.Status != 0 {
+		return fmt.Errorf("百度地图接口错误 %d: %s", resp.Status, resp.Message)
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+// Geocode 地址解析
+func (p *BaiduProvider) Geocode(address string) (Point, error) {
+	var result struct {
+		Location Point `json:"location"`
+	}
+	if err := p.get(baiduGeocodingUrl, map[string]string{"address": address}, &result); err != nil {
+		return Point{}, errors.Join(ErrNoLocation, err)
+	}
+	return result.Location, nil
+}
+
+// Distance 驾车路线距离
+func (p *BaiduProvider) Distance(from, to Point) (int, error) {
+	var result struct {
+		Routes []struct {
+			Distance int `json:"distance"`
+		} `json:"routes"`
+	}
+	params := map[string]string{
+		"origin":      fmt.Sprintf("%f,%f", from.Lat, from.Lng),
+		"destination": fmt.Sprintf("%f,%f", to.Lat, to.Lng),
+		"steps_info":  "0",
+	}
+	if err := p.get(baiduDrivingUrl, params, &result); err != nil {
+		return 0, err
+	}
+	if len(result.Routes) == 0 {
+		return 0, errors.New("百度地图未返回配送路线")
+	}
+	return result.Routes[0].Distance, nil
+}
@@ -0,0 +1,90 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"takeout/common/global"
+)
+
+// 地理服务名称
+const (
+	ProviderBaidu = "baidu"
+	ProviderLocal = "local"
+)
+
+// earthRadius 地球平均半径，单位米
+const earthRadius = 6371000
+
+// ErrNoLocation 无法确定地址的坐标
+var ErrNoLocation = errors.New("无法解析地址坐标")
+
+// Point 经纬度坐标
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// IsZero 未设置坐标
+func (p Point) IsZero() bool {
+	return p.Lat == 0 && p.Lng == 0
+}
+
+// Geocoder 地址解析，将文字地址转换为坐标
+type Geocoder interface {
+	Geocode(address string) (Point, error)
+}
+
+// RouteProvider 路线规划，返回两点间的配送距离，单位米
+type RouteProvider interface {
+	Distance(from, to Point) (int, error)
+}
+
+// Provider 地理服务
+type Provider interface {
+	Geocoder
+	RouteProvider
+}
+
+var (
+	once     sync.Once
+	provider Provider
+)
+
+// GetProvider 根据配置返回地理服务，默认使用不依赖外部接口的本地实现
+func GetProvider() Provider {
+	once.Do(func() {
+		switch global.Config.Geo.Provider {
+		case ProviderBaidu:
+			provider = NewBaiduProvider(global.Config.Baidu.AK)
+		default:
+			provider = NewLocalProvider()
+		}
+	})
+	return provider
+}
+
+// Haversine 两点间的球面距离，单位米
+func Haversine(a, b Point) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// InPolygon 射线法判断点是否在多边形内，多边形顶点按顺序给出，至少三个点
+func InPolygon(p Point, polygon []Point) bool {
+	if len(polygon) < 3 {
+		return false
+	}
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,19 @@
+package geo
+
+// LocalProvider 本地地理服务，不调用外部接口
+// 只使用已保存的坐标（用户选择收货地址时由小程序定位得到），距离按球面直线距离计算
+type LocalProvider struct{}
+
+func NewLocalProvider() *LocalProvider {
+	return &LocalProvider{}
+}
+
+// Geocode 本地无法解析文字地址，调用方应使用已保存的坐标
+func (p *LocalProvider) Geocode(_ string) (Point, error) {
+	return Point{}, ErrNoLocation
+}
+
+// Distance 球面直线距离
+func (p *LocalProvider) Distance(from, to Point) (int, error) {
+	return int(Haversine(from, to)), nil
+}
@@ -15,9 +15,13 @@ type GlobalConfig struct {
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	OSS      OSSConfig      `mapstructure:"oss"`
 	Wechat   WechatConfig   `mapstructure:"wechat"`
+	Payment  PaymentConfig  `mapstructure:"payment"`
 	Shop     ShopConfig     `mapstructure:"shop"`
 	Baidu    BaiduConfig    `mapstructure:"baidu"`
+	Geo      GeoConfig      `mapstructure:"geo"`
+	Points   PointsConfig   `mapstructure:"points"`
 	Template TemplateConfig `mapstructure:"template"`
+	Printer  PrinterConfig  `mapstructure:"printer"`
 }
 
 // ServerConfig 服务器配置
@@ -71,6 +75,11 @@ type JWTConfig struct {
 	UserSecretKey  string `mapstructure:"user_secret_key"`
 	UserTTL        int    `mapstructure:"user_ttl"`
 	UserTokenName  string `mapstructure:"user_token_name"`
+	RiderSecretKey string `mapstructure:"rider_secret_key"`
+	RiderTTL       int    `mapstructure:"rider_ttl"`
+	RiderTokenName string `mapstructure:"rider_token_name"`
+	Issuer         string `mapstructure:"issuer"`
+	RefreshTTL     int    `mapstructure:"refresh_ttl"` // 刷新令牌有效期，秒
 }
 
 // OSSConfig Alibaba OSS配置
@@ -94,6 +103,21 @@ type WechatConfig struct {
 	RefundNotifyUrl       string `mapstructure:"refund-notify-url"`
 }
 
+// PaymentConfig 支付渠道配置
+type PaymentConfig struct {
+	Provider string        `mapstructure:"provider"` // wechat 或 mock
+	Mock     MockPayConfig `mapstructure:"mock"`
+}
+
+// MockPayConfig 本地模拟支付网关配置
+type MockPayConfig struct {
+	Secret          string `mapstructure:"secret"` // 签名密钥
+	AutoNotify      bool   `mapstructure:"auto_notify"`
+	NotifyDelay     int    `mapstructure:"notify_delay"` // 回调延迟，单位秒
+	NotifyUrl       string `mapstructure:"notify_url"`
+	RefundNotifyUrl string `mapstructure:"refund_notify_url"`
+}
+
 // TemplateConfig xlsx模板文件
 type TemplateConfig struct {
 	Path string `mapstructure:"path"`
@@ -101,7 +125,47 @@ type TemplateConfig struct {
 
 // ShopConfig 商店信息
 type ShopConfig struct {
-	Address string `mapstructure:"address"`
+	Address            string  `mapstructure:"address"`
+	PackFee            float64 `mapstructure:"pack_fee"`             // 每份商品的打包费
+	DeliveryFee        float64 `mapstructure:"delivery_fee"`         // 配送费
+	TablewareFee       float64 `mapstructure:"tableware_fee"`        // 每套餐具的费用
+	AutoAssign         bool    `mapstructure:"auto_assign"`          // 接单后是否自动指派骑手
+	ScheduleLeadTime   int     `mapstructure:"schedule_lead_time"`   // 预约单在时段开始前多少分钟进入后厨队列
+	ScheduleMinAdvance int     `mapstructure:"schedule_min_advance"` // 时段开始前多少分钟停止预约
+	ReviewDays         int     `mapstructure:"review_days"`          // 订单完成后多少天内可以评价
+	Lat                float64 `mapstructure:"lat"`                  // 店铺坐标，为 0 时通过地址解析得到
+	Lng                float64 `mapstructure:"lng"`
+}
+
+// GeoConfig 地理服务配置
+type GeoConfig struct {
+	Provider string `mapstructure:"provider"` // baidu 或 local
+}
+
+// PointsConfig 会员积分配置
+type PointsConfig struct {
+	EarnRatio      float64      `mapstructure:"earn_ratio"`       // 每消费 1 元获得的积分，为 0 时不发放积分
+	RedeemRate     int          `mapstructure:"redeem_rate"`      // 多少积分抵扣 1 元，为 0 时不能抵扣
+	MaxRedeemRatio float64      `mapstructure:"max_redeem_ratio"` // 积分最多抵扣应付金额的比例
+	TierWindowDays int          `mapstructure:"tier_window_days"` // 按最近多少天的消费金额计算会员等级
+	Tiers          []TierConfig `mapstructure:"tiers"`            // 会员等级，按消费门槛从低到高排列
+}
+
+// TierConfig 会员等级
+type TierConfig struct {
+	Name       string  `mapstructure:"name"`
+	MinSpend   float64 `mapstructure:"min_spend"`  // 消费门槛，元
+	Multiplier float64 `mapstructure:"multiplier"` // 积分倍率
+}
+
+// PrinterConfig 小票打印机配置
+type PrinterConfig struct {
+	Provider string `mapstructure:"provider"` // network 或 file
+	Address  string `mapstructure:"address"`  // 网络打印机地址，未带端口时使用 9100
+	File     string `mapstructure:"file"`     // file 打印机的输出文件，为空时输出到标准输出
+	Timeout  int    `mapstructure:"timeout"`  // 连接和发送超时，单位秒
+	Width    int    `mapstructure:"width"`    // 每行半角字符数，58mm 纸为 32，80mm 纸为 48
+	Title    string `mapstructure:"title"`    // 小票抬头
 }
 
 // BaiduConfig 百度地图配置
@@ -0,0 +1,190 @@
+package payment
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/common/logger"
+	"takeout/common/utils"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// MockSignatureHeader 模拟网关回调的签名请求头
+const MockSignatureHeader = "Mock-Signature"
+
+// 模拟网关回调的请求体
+type mockNotify struct {
+	EventType     string `json:"event_type"` // TRANSACTION.SUCCESS、REFUND.SUCCESS
+	OutTradeNo    string `json:"out_trade_no"`
+	TransactionID string `json:"transaction_id"`
+	OutRefundNo   string `json:"out_refund_no,omitempty"`
+	Status        string `json:"status"`
+	Timestamp     int64  `json:"timestamp"`
+}
+
+// MockProvider 本地模拟支付网关，签发伪造的 prepay_id 并异步回调 /notify/pay、/notify/refund
+// 用于在本地跑通 支付 → 回调 → 接单 的完整流程
+type MockProvider struct {
+	config *global.MockPayConfig
+	client *http.Client
+
+	mutex  sync.Mutex
+	trades map[string]*QueryResult // 商户订单号 -> 支付状态
+}
+
+// NewMockProvider 创建模拟支付渠道
+func NewMockProvider(config *global.MockPayConfig) *MockProvider {
+	return &MockProvider{
+		config: config,
+		client: &http.Client{Timeout: 5 * time.Second},
+		trades: make(map[string]*QueryResult),
+	}
+}
+
+// Name 渠道名称
+func (p *MockProvider) Name() string {
+	return ProviderMock
+}
+
+// 使用 HMAC-SHA256 签名
+func (p *MockProvider) sign(data []byte) string {
+	mac := hmac.New(sha256.New, []byte(p.config.Secret))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Prepay 签发模拟的 prepay_id，开启自动回调时会在延迟后通知支付成功
+func (p *MockProvider) Prepay(req *PrepayRequest) (*PrepayResult, error) {
+	timeStamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonceStr := utils.GenerateRandomNumericString(32)
+	prepayID := "mock_" + p.sign([]byte(req.OrderNumber + timeStamp))[:32]
+	pkg := "prepay_id=" + prepayID
+	// 与微信一致：appId\ntimeStamp\nnonceStr\npackage\n
+	paySign := p.sign([]byte(fmt.Sprintf("%s\n%s\n%s\n%s\n", ProviderMock, timeStamp, nonceStr, pkg)))
+
+	p.mutex.Lock()
+	p.trades[req.OrderNumber] = &QueryResult{OrderNumber: req.OrderNumber, State: "NOTPAY"}
+	p.mutex.Unlock()
+
+	if p.config.AutoNotify {
+		go p.notifyPay(req.OrderNumber)
+	}
+	return &PrepayResult{
+		PrepayID:  prepayID,
+		TimeStamp: timeStamp,
+		NonceStr:  nonceStr,
+		Package:   pkg,
+		SignType:  "HMAC-SHA256",
+		PaySign:   paySign,
+	}, nil
+}
+
+// Query 查询模拟订单的支付状态
+func (p *MockProvider) Query(orderNumber string) (*QueryResult, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	trade, ok := p.trades[orderNumber]
+	if !ok {
+		return &QueryResult{OrderNumber: orderNumber, State: "NOTPAY"}, nil
+	}
+	result := *trade
+	return &result, nil
+}
+
+// Refund 模拟退款，总是异步回调退款成功
+func (p *MockProvider) Refund(req *RefundRequest) error {
+	go p.notifyRefund(req.OrderNumber, req.RefundNumber)
+	return nil
+}
+
+// ParseNotify 校验签名并解析模拟网关的回调
+func (p *MockProvider) ParseNotify(r *http.Request) (*NotifyResult, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeBadRequest, "回调内容异常")
+	}
+	if !hmac.Equal([]byte(p.sign(body)), []byte(r.Header.Get(MockSignatureHeader))) {
+		return nil, errs.New(constant.CodeBadRequest, "内容验证失败")
+	}
+	var n mockNotify
+	if err = json.Unmarshal(body, &n); err != nil {
+		return nil, errs.Wrap(err, constant.CodeBadRequest, constant.MsgUnmarshalFail)
+	}
+	result := &NotifyResult{
+		OrderNumber:   n.OutTradeNo,
+		TransactionID: n.TransactionID,
+		RefundNumber:  n.OutRefundNo,
+		Success:       n.Status == "SUCCESS",
+		Status:        n.Status,
+	}
+	if n.OutRefundNo != "" {
+		result.Type = NotifyRefund
+	} else {
+		result.Type = NotifyPay
+	}
+	return result, nil
+}
+
+// 模拟用户完成支付，并回调支付结果
+func (p *MockProvider) notifyPay(orderNumber string) {
+	time.Sleep(time.Duration(p.config.NotifyDelay) * time.Second)
+	transactionID := "mock" + time.Now().Format("20060102150405") + utils.GenerateRandomNumericString(8)
+	p.mutex.Lock()
+	p.trades[orderNumber] = &QueryResult{OrderNumber: orderNumber, TransactionID: transactionID, Paid: true, State: "SUCCESS"}
+	p.mutex.Unlock()
+
+	p.post(p.config.NotifyUrl, &mockNotify{
+		EventType:     "TRANSACTION.SUCCESS",
+		OutTradeNo:    orderNumber,
+		TransactionID: transactionID,
+		Status:        "SUCCESS",
+		Timestamp:     time.Now().Unix(),
+	})
+}
+
+// 回调退款结果
+func (p *MockProvider) notifyRefund(orderNumber, refundNumber string) {
+	time.Sleep(time.Duration(p.config.NotifyDelay) * time.Second)
+	p.post(p.config.RefundNotifyUrl, &mockNotify{
+		EventType:   "REFUND.SUCCESS",
+		OutTradeNo:  orderNumber,
+		OutRefundNo: refundNumber,
+		Status:      "SUCCESS",
+		Timestamp:   time.Now().Unix(),
+	})
+}
+
+// 发送带签名的回调请求
+func (p *MockProvider) post(url string, n *mockNotify) {
+	body, err := json.Marshal(n)
+	if err != nil {
+		logger.Error(constant.MsgMarshalFail, zap.Error(err))
+		return
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		logger.Error("模拟支付回调失败", zap.String("url", url), zap.Error(err))
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(MockSignatureHeader, p.sign(body))
+	resp, err := p.client.Do(req)
+	if err != nil {
+		logger.Error("模拟支付回调失败", zap.String("url", url), zap.Error(err))
+		return
+	}
+	_ = resp.Body.Close()
+	logger.Info("模拟支付回调", zap.String("url", url), zap.String("event", n.EventType), zap.Int("status", resp.StatusCode))
+}
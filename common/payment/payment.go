@@ -0,0 +1,97 @@
+package payment
+
+import (
+	"net/http"
+	"sync"
+	"takeout/common/global"
+
+	"github.com/shopspring/decimal"
+)
+
+// 支付渠道名称
+const (
+	ProviderWechat = "wechat"
+	ProviderMock   = "mock"
+)
+
+// 回调通知类型
+const (
+	NotifyPay    = 1 // 支付结果通知
+	NotifyRefund = 2 // 退款结果通知
+)
+
+// PrepayRequest 预下单参数
+type PrepayRequest struct {
+	OrderNumber string          // 商户订单号
+	Description string          // 商品描述
+	Amount      decimal.Decimal // 支付金额，单位元
+	OpenID      string          // 付款用户，微信支付需要
+}
+
+// PrepayResult 预下单结果，即小程序调起支付所需的参数
+type PrepayResult struct {
+	PrepayID  string
+	TimeStamp string
+	NonceStr  string
+	Package   string
+	SignType  string
+	PaySign   string
+}
+
+// QueryResult 订单支付状态查询结果
+type QueryResult struct {
+	OrderNumber   string
+	TransactionID string // 支付平台交易号
+	Paid          bool
+	State         string // 支付平台原始状态
+}
+
+// RefundRequest 退款参数
+type RefundRequest struct {
+	OrderNumber  string
+	RefundNumber string
+	Refund       decimal.Decimal // 退款金额
+	Total        decimal.Decimal // 原订单金额
+}
+
+// NotifyResult 解析并验签后的回调通知
+type NotifyResult struct {
+	Type          int // NotifyPay 或 NotifyRefund
+	OrderNumber   string
+	TransactionID string
+	RefundNumber  string
+	Success       bool
+	Status        string // 支付平台原始状态
+}
+
+// PaymentProvider 支付渠道接口，不同的支付平台实现该接口即可接入
+type PaymentProvider interface {
+	// Name 渠道名称
+	Name() string
+	// Prepay 预下单，返回客户端调起支付的参数
+	Prepay(req *PrepayRequest) (*PrepayResult, error)
+	// Query 根据商户订单号查询支付状态
+	Query(orderNumber string) (*QueryResult, error)
+	// Refund 申请退款，退款结果通过回调通知
+	Refund(req *RefundRequest) error
+	// ParseNotify 解析并验证支付平台的回调请求
+	ParseNotify(r *http.Request) (*NotifyResult, error)
+}
+
+var (
+	provider PaymentProvider
+	once     sync.Once
+)
+
+// GetProvider 根据配置获取当前使用的支付渠道，默认为微信支付
+func GetProvider() PaymentProvider {
+	once.Do(func() {
+		switch global.Config.Payment.Provider {
+		case ProviderMock:
+			provider = NewMockProvider(&global.Config.Payment.Mock)
+		default:
+			provider = NewWechatProvider(&global.Config.Wechat)
+		}
+	})
+	return provider
+}
@@ -0,0 +1,249 @@
+package payment
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/common/logger"
+	"takeout/common/utils"
+	"time"
+
+	"github.com/go-pay/gopay/wechat/v3"
+	"github.com/shopspring/decimal"
+	"github.com/wechatpay-apiv3/wechatpay-go/core"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/option"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/payments/jsapi"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/refunddomestic"
+	wxutils "github.com/wechatpay-apiv3/wechatpay-go/utils"
+	"go.uber.org/zap"
+)
+
+// WechatProvider 微信支付 v3 渠道
+type WechatProvider struct {
+	config *global.WechatConfig
+
+	clientV3 *wechat.ClientV3 // 用于回调验签
+	wcMu     sync.Mutex
+}
+
+// NewWechatProvider 创建微信支付渠道
+func NewWechatProvider(config *global.WechatConfig) *WechatProvider {
+	return &WechatProvider{config: config}
+}
+
+// Name 渠道名称
+func (p *WechatProvider) Name() string {
+	return ProviderWechat
+}
+
+// 创建微信支付Http客户端
+func (p *WechatProvider) getClient() (*core.Client, error) {
+	mchPrivateKey, err := wxutils.LoadPrivateKeyWithPath(p.config.PrivateKeyFilePath)
+	if err != nil {
+		return nil, err
+	}
+	wechatPayCertificate, err := wxutils.LoadCertificateWithPath(p.config.WeChatPayCertFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return core.NewClient(
+		context.Background(),
+		// 一次性设置 签名/验签/敏感字段加解密，并注册 平台证书下载器，自动定时获取最新的平台证书
+		option.WithMerchantCredential(p.config.MchID, p.config.MchSerialNumber, mchPrivateKey),
+		option.WithWechatPayCertificate([]*x509.Certificate{wechatPayCertificate}),
+	)
+}
+
+// getClientV3 gopay 的 wechatClientV3，用于回调验签
+// 初始化失败时不缓存，下次回调再重新初始化，修正配置后无需重启
+func (p *WechatProvider) getClientV3() (*wechat.ClientV3, error) {
+	p.wcMu.Lock()
+	defer p.wcMu.Unlock()
+	if p.clientV3 != nil {
+		return p.clientV3, nil
+	}
+	privateKey, err := p.readPem()
+	if err != nil {
+		logger.Error("读取微信支付商户私钥失败", zap.String("path", p.config.PrivateKeyFilePath), zap.Error(err))
+		return nil, err
+	}
+	client, err := wechat.NewClientV3(p.config.MchID, p.config.MchSerialNumber, p.config.ApiV3Key, privateKey)
+	if err != nil {
+		logger.Error("初始化微信支付客户端失败", zap.Error(err))
+		return nil, err
+	}
+	// 启用自动同步返回验签，并定时更新微信平台API证书（开启自动验签时，无需单独设置微信平台API证书和序列号）
+	if err = client.AutoVerifySign(); err != nil {
+		logger.Error("初始化微信支付客户端失败", zap.Error(err))
+		return nil, err
+	}
+	p.clientV3 = client
+	return client, nil
+}
+
+func (p *WechatProvider) readPem() (string, error) {
+	privateKey, err := os.ReadFile(p.config.PrivateKeyFilePath)
+	if err != nil {
+		return "", err
+	}
+	return string(privateKey), nil
+}
+
+// 元转换为分
+func toFen(amount decimal.Decimal) int64 {
+	return amount.Mul(decimal.NewFromInt(100)).Round(2).IntPart()
+}
+
+// Prepay 发送jsapi请求，并构造返回给小程序的支付信息
+func (p *WechatProvider) Prepay(req *PrepayRequest) (*PrepayResult, error) {
+	client, err := p.getClient()
+	if err != nil {
+		return nil, err
+	}
+	svc := jsapi.JsapiApiService{Client: client}
+	resp, _, err := svc.Prepay(context.Background(),
+		jsapi.PrepayRequest{
+			Appid:       core.String(p.config.AppID),
+			Mchid:       core.String(p.config.MchID),
+			Description: core.String(req.Description),
+			OutTradeNo:  core.String(req.OrderNumber),
+			NotifyUrl:   core.String(p.config.NotifyUrl),
+			Amount: &jsapi.Amount{
+				Total:    core.Int64(toFen(req.Amount)),
+				Currency: core.String("CNY"),
+			},
+			Payer: &jsapi.Payer{
+				Openid: core.String(req.OpenID),
+			},
+		})
+	if err != nil {
+		return nil, err
+	}
+	if resp.PrepayId == nil {
+		return nil, errs.New(constant.CodeInternalError, constant.MsgNotFound)
+	}
+
+	timeStamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonceStr := utils.GenerateRandomNumericString(32)
+	pkg := "prepay_id=" + *resp.PrepayId
+	list := []any{p.config.AppID, timeStamp, nonceStr, pkg}
+	var stringBuilder strings.Builder
+	for _, item := range list {
+		stringBuilder.WriteString(fmt.Sprintf("%v\n", item))
+	}
+	mchPrivateKey, err := wxutils.LoadPrivateKeyWithPath(p.config.PrivateKeyFilePath)
+	if err != nil {
+		return nil, err
+	}
+	paySign, err := wxutils.SignSHA256WithRSA(stringBuilder.String(), mchPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &PrepayResult{
+		PrepayID:  *resp.PrepayId,
+		TimeStamp: timeStamp,
+		NonceStr:  nonceStr,
+		Package:   pkg,
+		SignType:  "RSA",
+		PaySign:   paySign,
+	}, nil
+}
+
+// Query 根据商户订单号查询微信支付订单
+func (p *WechatProvider) Query(orderNumber string) (*QueryResult, error) {
+	client, err := p.getClient()
+	if err != nil {
+		return nil, err
+	}
+	svc := jsapi.JsapiApiService{Client: client}
+	resp, _, err := svc.QueryOrderByOutTradeNo(context.Background(),
+		jsapi.QueryOrderByOutTradeNoRequest{
+			OutTradeNo: core.String(orderNumber),
+			Mchid:      core.String(p.config.MchID),
+		})
+	if err != nil {
+		return nil, err
+	}
+	result := &QueryResult{OrderNumber: orderNumber}
+	if resp.TransactionId != nil {
+		result.TransactionID = *resp.TransactionId
+	}
+	if resp.TradeState != nil {
+		result.State = *resp.TradeState
+		result.Paid = *resp.TradeState == "SUCCESS"
+	}
+	return result, nil
+}
+
+// Refund 微信支付退款
+func (p *WechatProvider) Refund(req *RefundRequest) error {
+	client, err := p.getClient()
+	if err != nil {
+		return err
+	}
+	svc := refunddomestic.RefundsApiService{Client: client}
+	_, _, err = svc.Create(context.Background(),
+		refunddomestic.CreateRequest{
+			OutTradeNo:  core.String(req.OrderNumber),
+			OutRefundNo: core.String(req.RefundNumber),
+			NotifyUrl:   core.String(p.config.RefundNotifyUrl),
+			Amount: &refunddomestic.AmountReq{
+				Currency: core.String("CNY"),
+				Refund:   core.Int64(toFen(req.Refund)),
+				Total:    core.Int64(toFen(req.Total)),
+			},
+		},
+	)
+	return err
+}
+
+// ParseNotify 解析微信回调请求，验证签名并解密
+func (p *WechatProvider) ParseNotify(r *http.Request) (*NotifyResult, error) {
+	notifyReq, err := wechat.V3ParseNotify(r)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeBadRequest, "回调内容异常")
+	}
+	clientV3, err := p.getClientV3()
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeInternalError, "微信支付客户端初始化失败")
+	}
+	// 验证异步通知的签名
+	if err = notifyReq.VerifySignByPK(clientV3.WxPublicKey()); err != nil {
+		return nil, errs.Wrap(err, constant.CodeBadRequest, "内容验证失败")
+	}
+	// 退款通知的事件类型为 REFUND.SUCCESS、REFUND.ABNORMAL、REFUND.CLOSED
+	if strings.HasPrefix(notifyReq.EventType, "REFUND.") {
+		result, e := notifyReq.DecryptRefundCipherText(p.config.ApiV3Key)
+		if e != nil {
+			return nil, errs.Wrap(e, constant.CodeBadRequest, "内容解密失败")
+		}
+		return &NotifyResult{
+			Type:          NotifyRefund,
+			OrderNumber:   result.OutTradeNo,
+			TransactionID: result.TransactionId,
+			RefundNumber:  result.OutRefundNo,
+			Success:       result.RefundStatus == "SUCCESS",
+			Status:        result.RefundStatus,
+		}, nil
+	}
+	// 普通支付通知解密
+	result, err := notifyReq.DecryptPayCipherText(p.config.ApiV3Key)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeBadRequest, "内容解密失败")
+	}
+	return &NotifyResult{
+		Type:          NotifyPay,
+		OrderNumber:   result.OutTradeNo,
+		TransactionID: result.TransactionId,
+		Success:       result.TradeState == "SUCCESS",
+		Status:        result.TradeState,
+	}, nil
+}
@@ -0,0 +1,155 @@
+package printer
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 对齐方式
+const (
+	AlignLeft   = 0
+	AlignCenter = 1
+	AlignRight  = 2
+)
+
+const (
+	esc = 0x1B
+	fs  = 0x1C
+	gs  = 0x1D
+)
+
+// Builder 拼装 ESC/POS 指令，中文按 GBK 编码
+// width 为纸张每行可打印的半角字符数，58mm 纸一般为 32，80mm 纸一般为 48
+type Builder struct {
+	buf   bytes.Buffer
+	width int
+	size  int // 当前字号倍数，用于计算每行能容纳的字符数
+}
+
+// NewBuilder 初始化打印机并开启汉字模式
+func NewBuilder(width int) *Builder {
+	if width <= 0 {
+		width = 32
+	}
+	b := &Builder{width: width, size: 1}
+	b.buf.Write([]byte{esc, '@', fs, '&'})
+	return b
+}
+
+// Align 设置对齐方式，对之后的整行生效
+func (b *Builder) Align(align int) *Builder {
+	b.buf.Write([]byte{esc, 'a', byte(align)})
+	return b
+}
+
+// Bold 设置加粗
+func (b *Builder) Bold(on bool) *Builder {
+	n := byte(0)
+	if on {
+		n = 1
+	}
+	b.buf.Write([]byte{esc, 'E', n})
+	return b
+}
+
+// Size 设置字号倍数，宽高同时放大，取值 1 到 8
+func (b *Builder) Size(size int) *Builder {
+	size = min(max(size, 1), 8)
+	b.size = size
+	n := byte(size-1)<<4 | byte(size-1)
+	b.buf.Write([]byte{gs, '!', n})
+	return b
+}
+
+// Text 输出文字，不换行
+func (b *Builder) Text(s string) *Builder {
+	b.buf.Write(encode(s))
+	return b
+}
+
+// Line 输出一行文字
+func (b *Builder) Line(s string) *Builder {
+	return b.Text(s).Text("\n")
+}
+
+// Divider 用指定字符画满一行分隔线
+func (b *Builder) Divider(ch string) *Builder {
+	return b.Line(strings.Repeat(ch, b.columns()))
+}
+
+// Columns 左右两端对齐输出一行，放不下时左侧文字单独占一行
+func (b *Builder) Columns(left, right string) *Builder {
+	l, r := encode(left), encode(right)
+	gap := b.columns() - len(l) - len(r)
+	if gap < 1 {
+		b.buf.Write(l)
+		b.buf.WriteByte('\n')
+		l, gap = nil, max(b.columns()-len(r), 0)
+	}
+	b.buf.Write(l)
+	b.buf.Write(bytes.Repeat([]byte{' '}, gap))
+	b.buf.Write(r)
+	b.buf.WriteByte('\n')
+	return b
+}
+
+// Feed 走纸 n 行
+func (b *Builder) Feed(n int) *Builder {
+	b.buf.Write([]byte{esc, 'd', byte(n)})
+	return b
+}
+
+// QRCode 打印二维码，size 为模块大小，取值 1 到 16
+func (b *Builder) QRCode(data string, size int) *Builder {
+	size = min(max(size, 1), 16)
+	// 选择模型 2
+	b.buf.Write([]byte{gs, '(', 'k', 4, 0, '1', 'A', '2', 0})
+	// 模块大小
+	b.buf.Write([]byte{gs, '(', 'k', 3, 0, '1', 'C', byte(size)})
+	// 纠错等级 M
+	b.buf.Write([]byte{gs, '(', 'k', 3, 0, '1', 'E', '1'})
+	// 写入数据
+	n := len(data) + 3
+	b.buf.Write([]byte{gs, '(', 'k', byte(n), byte(n >> 8), '1', 'P', '0'})
+	b.buf.WriteString(data)
+	// 打印
+	b.buf.Write([]byte{gs, '(', 'k', 3, 0, '1', 'Q', '0'})
+	return b
+}
+
+// Cut 走纸后半切
+func (b *Builder) Cut() *Builder {
+	b.buf.Write([]byte{gs, 'V', 66, 0})
+	return b
+}
+
+// Bytes 返回拼装好的指令
+func (b *Builder) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+// columns 当前字号下每行能容纳的半角字符数
+func (b *Builder) columns() int {
+	return b.width / b.size
+}
+
+// encode 转换为 GBK 编码，GBK 中汉字占两个字节，打印宽度也正好是两个半角字符
+// 无法编码的字符（如 emoji）替换为问号
+func encode(s string) []byte {
+	encoder := simplifiedchinese.GBK.NewEncoder()
+	if data, err := encoder.Bytes([]byte(s)); err == nil {
+		return data
+	}
+	var buf bytes.Buffer
+	for _, r := range s {
+		data, err := encoder.Bytes([]byte(string(r)))
+		if err != nil {
+			buf.WriteByte('?')
+			continue
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,32 @@
+package printer
+
+import (
+	"os"
+	"sync"
+)
+
+// FilePrinter 把指令追加写入文件，路径为空时输出到标准输出，用于没有打印机时调试
+type FilePrinter struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFilePrinter(path string) *FilePrinter {
+	return &FilePrinter{path: path}
+}
+
+func (p *FilePrinter) Print(data []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.path == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	file, err := os.OpenFile(p.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(data)
+	return err
+}
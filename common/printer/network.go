@@ -0,0 +1,34 @@
+package printer
+
+import (
+	"net"
+	"time"
+)
+
+// NetworkPrinter 通过 TCP 直连网络打印机的 RAW 端口发送指令
+type NetworkPrinter struct {
+	address string
+	timeout time.Duration
+}
+
+// NewNetworkPrinter 地址未带端口时使用 9100
+func NewNetworkPrinter(address string, timeout time.Duration) *NetworkPrinter {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, DefaultPort)
+	}
+	return &NetworkPrinter{address: address, timeout: timeout}
+}
+
+// Print 每次打印单独建立连接，打印机同一时间通常只接受一个连接
+func (p *NetworkPrinter) Print(data []byte) error {
+	conn, err := net.DialTimeout("tcp", p.address, p.timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err = conn.SetWriteDeadline(time.Now().Add(p.timeout)); err != nil {
+		return err
+	}
+	_, err = conn.Write(data)
+	return err
+}
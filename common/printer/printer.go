@@ -0,0 +1,44 @@
+package printer
+
+import (
+	"sync"
+	"takeout/common/global"
+	"time"
+)
+
+// 打印机类型
+const (
+	ProviderNetwork = "network"
+	ProviderFile    = "file"
+)
+
+// DefaultPort 网络打印机的 RAW 打印端口
+const DefaultPort = "9100"
+
+// Printer 打印机，接收已经编码好的 ESC/POS 指令
+type Printer interface {
+	Print(data []byte) error
+}
+
+var (
+	once    sync.Once
+	printer Printer
+)
+
+// GetPrinter 根据配置返回打印机，默认写入文件或标准输出，方便本地调试
+func GetPrinter() Printer {
+	once.Do(func() {
+		config := global.Config.Printer
+		switch config.Provider {
+		case ProviderNetwork:
+			timeout := time.Duration(config.Timeout) * time.Second
+			if timeout <= 0 {
+				timeout = 3 * time.Second
+			}
+			printer = NewNetworkPrinter(config.Address, timeout)
+		default:
+			printer = NewFilePrinter(config.File)
+		}
+	})
+	return printer
+}
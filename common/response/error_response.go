@@ -15,11 +15,14 @@ var errorCodeToHttpStatus = map[int]int{
 	constant.CodeNotFound:      http.StatusNotFound,            // 404 用户未找到
 	constant.CodeUnauthorized:  http.StatusUnauthorized,        // 401 用户未登录
 	constant.CodeForbidden:     http.StatusForbidden,           // 403 无权限访问
+	constant.CodeConflict:      http.StatusConflict,            // 409 请求冲突
 	constant.CodeServerError:   http.StatusInternalServerError, // 500 服务器内部错误
 	constant.CodeInternalError: http.StatusInternalServerError, // 系统内部错误
 	constant.CodeCacheError:    http.StatusInternalServerError,
 
-	constant.CodePasswordError: http.StatusUnauthorized,
+	constant.CodePasswordError:   http.StatusUnauthorized,
+	constant.CodeCaptchaRequired: http.StatusUnauthorized,
+	constant.CodeTooManyRequests: http.StatusTooManyRequests,
 
 	constant.CodeEmployLoginFail:       http.StatusUnauthorized,
 	constant.CodeEmployeeCreateFail:    http.StatusBadRequest,
@@ -12,6 +12,9 @@ func CleanCache(pattern string) error {
 	if err != nil {
 		return err
 	}
+	if len(keys) == 0 {
+		return nil
+	}
 	_, err = global.Redis.Del(ctx, keys...).Result()
 	if err != nil {
 		return err
@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math/big"
+	"strconv"
+)
+
+const (
+	captchaWidth   = 120
+	captchaHeight  = 40
+	captchaScale   = 3  // 点阵字形的放大倍数
+	captchaAdvance = 16 // 每个字符占用的宽度
+)
+
+// captchaGlyphs 5x7 点阵字形，验证码只会用到数字、运算符和问号
+var captchaGlyphs = map[rune][7]string{
+	'0': {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1': {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2': {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3': {"####.", "....#", "....#", ".###.", "....#", "....#", "####."},
+	'4': {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5': {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6': {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7': {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8': {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9': {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	'+': {".....", "..#..", "..#..", "#####", "..#..", "..#..", "....."},
+	'-': {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+	'×': {".....", "#...#", ".#.#.", "..#..", ".#.#.", "#...#", "....."},
+	'=': {".....", ".....", "#####", ".....", "#####", ".....", "....."},
+	'?': {".###.", "#...#", "....#", "...#.", "..#..", ".....", "..#.."},
+}
+
+// randInt 返回 [0, n) 的随机数
+func randInt(n int) int {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0
+	}
+	return int(v.Int64())
+}
+
+// randColor 随机颜色，limit 限制各通道的上限，用于控制深浅
+func randColor(limit int) color.RGBA {
+	return color.RGBA{R: uint8(randInt(limit)), G: uint8(randInt(limit)), B: uint8(randInt(limit)), A: 0xff}
+}
+
+// NewCaptcha 生成一道算术验证码，返回 PNG 图片的 data URI 和答案
+// 在本地生成，不依赖第三方服务；题目直接画成像素，字符做随机偏移、倾斜，并加干扰线和噪点
+func NewCaptcha() (dataURI string, answer string) {
+	a, b := randInt(10)+1, randInt(10)+1
+	var question string
+	var result int
+	switch randInt(3) {
+	case 0:
+		question, result = fmt.Sprintf("%d+%d=?", a, b), a+b
+	case 1:
+		if a < b {
+			a, b = b, a
+		}
+		question, result = fmt.Sprintf("%d-%d=?", a, b), a-b
+	default:
+		question, result = fmt.Sprintf("%d×%d=?", a, b), a*b
+	}
+
+	img := newCaptchaImage()
+	for i := 0; i < 4; i++ {
+		drawLine(img, randInt(captchaWidth), randInt(captchaHeight), randInt(captchaWidth), randInt(captchaHeight), randColor(0xaa))
+	}
+	x := 4
+	for _, ch := range question {
+		drawGlyph(img, captchaGlyphs[ch], x+randInt(3), 4+randInt(12), randInt(5)-2, randColor(0x77))
+		x += captchaAdvance
+	}
+	for i := 0; i < 120; i++ {
+		img.SetRGBA(randInt(captchaWidth), randInt(captchaHeight), randColor(0xff))
+	}
+
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	dataURI = "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+	return dataURI, strconv.Itoa(result)
+}
+
+func newCaptchaImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, captchaWidth, captchaHeight))
+	background := color.RGBA{R: 0xf5, G: 0xf5, B: 0xf5, A: 0xff}
+	for y := 0; y < captchaHeight; y++ {
+		for x := 0; x < captchaWidth; x++ {
+			img.SetRGBA(x, y, background)
+		}
+	}
+	return img
+}
+
+// drawGlyph 在 (x, y) 处放大绘制字形，shear 为字形顶部相对底部的水平偏移，模拟倾斜
+func drawGlyph(img *image.RGBA, glyph [7]string, x, y, shear int, c color.RGBA) {
+	for row, line := range glyph {
+		offset := shear * (len(glyph) - 1 - row) * captchaScale / len(glyph)
+		for col, dot := range line {
+			if dot != '#' {
+				continue
+			}
+			for dy := 0; dy < captchaScale; dy++ {
+				for dx := 0; dx < captchaScale; dx++ {
+					img.SetRGBA(x+offset+col*captchaScale+dx, y+row*captchaScale+dy, c)
+				}
+			}
+		}
+	}
+}
+
+// drawLine Bresenham 画线
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		img.SetRGBA(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
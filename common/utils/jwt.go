@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"context"
+	"strconv"
 	"takeout/common/constant"
 	"takeout/common/errs"
 	"time"
@@ -10,42 +12,163 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
-// GenerateToken 生成JWT令牌 - 保留原有函数以兼容现有代码
-func GenerateToken(claimName, claimData string) (string, error) {
-	expirationTime := time.Now().Add(time.Duration(global.Config.JWT.AdminTTL) * time.Second).Unix()
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		claimName: claimData,
-		"exp":     expirationTime,
+// Claims 令牌携带的信息，Subject 为账号 ID，Audience 区分管理端、用户端和骑手端
+type Claims struct {
+	SessionID string `json:"sid"` // 登录会话，注销或禁用账号时删除会话使令牌立即失效
+	TokenType string `json:"typ"` // access 或 refresh，防止刷新令牌被当作访问令牌使用
+	jwt.RegisteredClaims
+}
+
+// TokenPair 登录和刷新令牌时返回的令牌
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int // 访问令牌有效期，秒
+}
+
+// audienceKey 每个受众使用独立的签名密钥和访问令牌有效期
+func audienceKey(aud string) (secret string, ttl int, err error) {
+	jwtConfig := global.Config.JWT
+	switch aud {
+	case constant.AudienceAdmin:
+		secret, ttl = jwtConfig.AdminSecretKey, jwtConfig.AdminTTL
+	case constant.AudienceUser:
+		secret, ttl = jwtConfig.UserSecretKey, jwtConfig.UserTTL
+	case constant.AudienceRider:
+		secret, ttl = jwtConfig.RiderSecretKey, jwtConfig.RiderTTL
+	default:
+		return "", 0, errs.New(constant.CodeJWTParseError, constant.MsgJWTUnknownAudience)
+	}
+	if secret == "" {
+		return "", 0, errs.New(constant.CodeConfigError, constant.MsgJWTSecretMissing)
+	}
+	return secret, ttl, nil
+}
+
+// signToken 签发指定类型的令牌
+func signToken(aud, id, sid, tokenType string, ttl time.Duration) (string, string, error) {
+	secret, _, err := audienceKey(aud)
+	if err != nil {
+		return "", "", err
+	}
+	jti, err := randomID()
+	if err != nil {
+		return "", "", err
+	}
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		SessionID: sid,
+		TokenType: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    global.Config.JWT.Issuer,
+			Subject:   id,
+			Audience:  jwt.ClaimStrings{aud},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        jti,
+		},
 	})
 	// 获得签名后的完整token
-	signedToken, err := token.SignedString([]byte(global.Config.JWT.AdminSecretKey))
-	return signedToken, err
+	signedToken, err := token.SignedString([]byte(secret))
+	if err != nil {
+		return "", "", errs.Wrap(err, constant.CodeServerError, constant.MsgJWTSignFail)
+	}
+	return signedToken, jti, nil
+}
+
+// issuePair 为会话签发一对访问令牌和刷新令牌，返回刷新令牌的 jti 用于轮换校验
+func issuePair(aud, id, sid string) (*TokenPair, string, error) {
+	_, ttl, err := audienceKey(aud)
+	if err != nil {
+		return nil, "", err
+	}
+	access, _, err := signToken(aud, id, sid, constant.TokenTypeAccess, time.Duration(ttl)*time.Second)
+	if err != nil {
+		return nil, "", err
+	}
+	refresh, jti, err := signToken(aud, id, sid, constant.TokenTypeRefresh, refreshTTL())
+	if err != nil {
+		return nil, "", err
+	}
+	return &TokenPair{AccessToken: access, RefreshToken: refresh, ExpiresIn: ttl}, jti, nil
 }
 
-// ParseToken 解析JWT令牌
-func ParseToken(tokenStr, claimName string) (string, error) {
-	// 解析 token
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (any, error) {
+// IssueTokens 登录成功后创建会话并签发令牌
+func IssueTokens(ctx context.Context, aud string, id int) (*TokenPair, error) {
+	sid, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	pair, jti, err := issuePair(aud, strconv.Itoa(id), sid)
+	if err != nil {
+		return nil, err
+	}
+	if err = createSession(ctx, aud, strconv.Itoa(id), sid, jti); err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// RefreshTokens 使用刷新令牌换取新的令牌，旧的刷新令牌随之作废
+// 已作废的刷新令牌再次使用说明可能被盗用，直接注销整个会话
+func RefreshTokens(ctx context.Context, aud, refreshToken string) (*TokenPair, error) {
+	claims, err := parseClaims(refreshToken, aud, constant.TokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+	pair, jti, err := issuePair(aud, claims.Subject, claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = rotateSession(ctx, aud, claims.Subject, claims.SessionID, claims.ID, jti); err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// ParseToken 解析访问令牌并确认会话仍然有效
+func ParseToken(ctx context.Context, tokenStr, aud string) (*Claims, error) {
+	claims, err := parseClaims(tokenStr, aud, constant.TokenTypeAccess)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkSession(ctx, aud, claims.SessionID); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// parseClaims 校验签名、有效期、签发者、受众和令牌类型
+func parseClaims(tokenStr, aud, tokenType string) (*Claims, error) {
+	secret, _, err := audienceKey(aud)
+	if err != nil {
+		return nil, err
+	}
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (any, error) {
 		// jwt.SigningMethodHS256 是 jwt.SigningMethodHMAC 的一个具体实现
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errs.New(constant.CodeJWTParseError, constant.MsgJWTUnKnownSigningMethod)
 		}
-		return []byte(global.Config.JWT.AdminSecretKey), nil
+		return []byte(secret), nil
 	})
 	// 错误处理
 	if err != nil {
-		return "", errs.Wrap(err, constant.CodeJWTParseError, constant.MsgJWTParseFail)
+		return nil, errs.Wrap(err, constant.CodeJWTParseError, constant.MsgJWTParseFail)
 	}
-	// 获取携带信息
-	if claims, isMap := token.Claims.(jwt.MapClaims); isMap && token.Valid {
-		// ☆ JWT 数字解析的默认类型是 float64，不能直接用 .(int)
-		// 所以 id 还是转化成 string 再写入 token 比较好
-		claimsData, ok := claims[claimName].(string)
-		// fmt.Println(claims)
-		if !ok {
-			return "", errs.New(constant.CodeJWTParseError, constant.MsgGetAccountInfoFail)
-		}
-		return claimsData, nil
+	if !token.Valid ||
+		!claims.VerifyAudience(aud, true) ||
+		!claims.VerifyIssuer(global.Config.JWT.Issuer, true) ||
+		claims.TokenType != tokenType {
+		return nil, errs.New(constant.CodeJWTParseError, constant.MsgJWTParseFail)
+	}
+	if claims.Subject == "" || claims.SessionID == "" {
+		return nil, errs.New(constant.CodeJWTParseError, constant.MsgGetAccountInfoFail)
 	}
-	return "", errs.New(constant.CodeJWTParseError, constant.MsgJWTParseFail)
+	return claims, nil
+}
+
+// refreshTTL 刷新令牌和会话的有效期
+func refreshTTL() time.Duration {
+	return time.Duration(global.Config.JWT.RefreshTTL) * time.Second
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 )
 
+// Encrypt 旧版的无盐 MD5 密码摘要，仅用于校验和迁移历史密码，新密码使用 HashPassword
 func Encrypt(str string) string {
 	// 创建一个MD5哈希对象
 	h := md5.New()
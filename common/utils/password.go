@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"sync"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHasher 密码哈希算法
+type PasswordHasher interface {
+	// Hash 生成密码哈希，结果中自带盐和参数
+	Hash(password string) (string, error)
+	// Verify 校验密码与哈希是否匹配
+	Verify(hashed, password string) bool
+	// NeedsRehash 哈希参数低于当前配置时需要重新生成
+	NeedsRehash(hashed string) bool
+}
+
+// BcryptHasher 基于 bcrypt 的密码哈希
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	return string(hashed), err
+}
+
+func (h *BcryptHasher) Verify(hashed, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password)) == nil
+}
+
+func (h *BcryptHasher) NeedsRehash(hashed string) bool {
+	cost, err := bcrypt.Cost([]byte(hashed))
+	return err != nil || cost < h.Cost
+}
+
+// Hasher 当前使用的密码哈希算法
+var Hasher PasswordHasher = &BcryptHasher{Cost: bcrypt.DefaultCost}
+
+// HashPassword 使用当前算法生成密码哈希
+func HashPassword(password string) (string, error) {
+	hashed, err := Hasher.Hash(password)
+	if err != nil {
+		return "", errs.Wrap(err, constant.CodeServerError, constant.MsgPasswordHashFail)
+	}
+	return hashed, nil
+}
+
+// VerifyPassword 校验密码，兼容旧版无盐 MD5 摘要
+// rehash 为 true 表示密码正确但哈希已过时，调用方应使用 HashPassword 重新生成并保存
+func VerifyPassword(hashed, password string) (ok, rehash bool) {
+	if isLegacyMD5(hashed) {
+		return subtle.ConstantTimeCompare([]byte(hashed), []byte(Encrypt(password))) == 1, true
+	}
+	if !Hasher.Verify(hashed, password) {
+		return false, false
+	}
+	return true, Hasher.NeedsRehash(hashed)
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// DummyVerify 账号不存在时也做一次同等开销的哈希比对，避免通过响应时间判断用户名是否存在
+func DummyVerify(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = Hasher.Hash(constant.DefaultPassword)
+	})
+	Hasher.Verify(dummyHash, password)
+}
+
+// isLegacyMD5 旧版密码为 32 位十六进制的 MD5 摘要
+func isLegacyMD5(hashed string) bool {
+	if len(hashed) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(hashed)
+	return err == nil
+}
+
+// CheckPasswordStrength 密码强度校验：长度 8-64 位，同时包含字母和数字，且不能是默认密码
+func CheckPasswordStrength(password string) error {
+	if len(password) < constant.PasswordMinLength || len(password) > constant.PasswordMaxLength {
+		return errs.New(constant.CodeBadRequest, constant.MsgPasswordLength)
+	}
+	if password == constant.DefaultPassword {
+		return errs.New(constant.CodeBadRequest, constant.MsgPasswordIsDefault)
+	}
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return errs.New(constant.CodeBadRequest, constant.MsgPasswordTooWeak)
+	}
+	return nil
+}
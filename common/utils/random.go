@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+// GenerateRandomNumericString 生成指定长度的随机数字字符串
+func GenerateRandomNumericString(length int) string {
+	var randomString string
+
+	for i := 0; i < length; i++ {
+		randomDigit := rand.Intn(10) // 生成一个 0 到 9 的随机数字
+		randomString += strconv.Itoa(randomDigit)
+	}
+
+	return randomString
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"takeout/common/constant"
+	"takeout/common/global"
+	"time"
+)
+
+// NextSerialNumber 生成按天递增的流水号：前缀 + 日期 + 8 位序号
+// 序号由 Redis INCR 生成，多实例并发下也不会重复
+func NextSerialNumber(name, prefix string) (string, error) {
+	ctx := context.Background()
+	date := time.Now().Format("20060102")
+	key := constant.RedisKeySerial + name + "::" + date
+	seq, err := global.Redis.Incr(ctx, key).Result()
+	if err != nil {
+		return "", err
+	}
+	if seq == 1 {
+		// 第二天之后就不再使用，保留两天足够
+		global.Redis.Expire(ctx, key, 48*time.Hour)
+	}
+	return fmt.Sprintf("%s%s%08d", prefix, date, seq), nil
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 会话保存在 Redis 中：
+//   session::{aud}::{sid}           当前有效的刷新令牌 jti
+//   session::account::{aud}::{id}   账号下的所有会话，用于禁用账号时全部注销
+
+// rotateScript 只有携带当前 jti 的刷新令牌才能轮换，返回 0 表示会话不存在，-1 表示刷新令牌已被使用过
+var rotateScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current then
+	return 0
+end
+if current ~= ARGV[1] then
+	return -1
+end
+redis.call('SET', KEYS[1], ARGV[2], 'EX', ARGV[3])
+return 1
+`)
+
+func sessionKey(aud, sid string) string {
+	return constant.RedisKeySession + aud + "::" + sid
+}
+
+func accountSessionKey(aud, id string) string {
+	return constant.RedisKeySessionAccount + aud + "::" + id
+}
+
+// randomID 生成会话 ID 和令牌 jti
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errs.Wrap(err, constant.CodeServerError, constant.MsgServerError)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func createSession(ctx context.Context, aud, id, sid, jti string) error {
+	ttl := refreshTTL()
+	_, err := global.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, sessionKey(aud, sid), jti, ttl)
+		pipe.SAdd(ctx, accountSessionKey(aud, id), sid)
+		pipe.Expire(ctx, accountSessionKey(aud, id), ttl)
+		return nil
+	})
+	if err != nil {
+		return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	return nil
+}
+
+func rotateSession(ctx context.Context, aud, id, sid, oldJTI, newJTI string) error {
+	ttl := int(refreshTTL().Seconds())
+	result, err := rotateScript.Run(ctx, global.Redis, []string{sessionKey(aud, sid)}, oldJTI, newJTI, ttl).Int()
+	if err != nil {
+		return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	switch result {
+	case 0:
+		return errs.New(constant.CodeUnauthorized, constant.MsgSessionExpired)
+	case -1:
+		if err = RevokeSession(ctx, aud, id, sid); err != nil {
+			return err
+		}
+		return errs.New(constant.CodeUnauthorized, constant.MsgRefreshTokenReused)
+	}
+	global.Redis.Expire(ctx, accountSessionKey(aud, id), refreshTTL())
+	return nil
+}
+
+func checkSession(ctx context.Context, aud, sid string) error {
+	n, err := global.Redis.Exists(ctx, sessionKey(aud, sid)).Result()
+	if err != nil {
+		return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	if n == 0 {
+		return errs.New(constant.CodeUnauthorized, constant.MsgSessionExpired)
+	}
+	return nil
+}
+
+// RevokeSession 注销单个会话，会话下的访问令牌和刷新令牌立即失效
+func RevokeSession(ctx context.Context, aud, id, sid string) error {
+	_, err := global.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sessionKey(aud, sid))
+		pipe.SRem(ctx, accountSessionKey(aud, id), sid)
+		return nil
+	})
+	if err != nil {
+		return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	return nil
+}
+
+// RevokeAccount 注销账号的所有会话，用于禁用账号
+func RevokeAccount(ctx context.Context, aud string, id int) error {
+	key := accountSessionKey(aud, strconv.Itoa(id))
+	sids, err := global.Redis.SMembers(ctx, key).Result()
+	if err != nil {
+		return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	keys := make([]string, 0, len(sids)+1)
+	for _, sid := range sids {
+		keys = append(keys, sessionKey(aud, sid))
+	}
+	keys = append(keys, key)
+	if err = global.Redis.Del(ctx, keys...).Err(); err != nil {
+		return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+	"takeout/model/dto"
+)
+
+// AuditController 操作日志接口
+type AuditController struct {
+	auditService service.AuditService
+}
+
+func NewAuditController() *AuditController {
+	return &AuditController{}
+}
+
+// PageQuery 操作日志分页查询
+func (c *AuditController) PageQuery(ctx *gin.Context) {
+	var queryDTO dto.AuditPageQueryDTO
+	if err := ctx.ShouldBindQuery(&queryDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+	if queryDTO.Page <= 0 {
+		queryDTO.Page = constant.DefaultPageNum
+	}
+	if queryDTO.PageSize <= 0 {
+		queryDTO.PageSize = constant.DefaultPageSize
+	}
+
+	page, err := c.auditService.PageQuery(&queryDTO)
+	if err != nil {
+		logger.Error(constant.MsgAuditQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, page)
+}
@@ -0,0 +1,146 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+	"takeout/model/dto"
+)
+
+// CouponController 优惠券管理接口
+type CouponController struct {
+	couponService service.CouponService
+}
+
+func NewCouponController() *CouponController {
+	return &CouponController{}
+}
+
+// Create 新增优惠券模板
+func (c *CouponController) Create(ctx *gin.Context) {
+	var createDTO dto.CouponTemplateDTO
+	if err := ctx.ShouldBindJSON(&createDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.couponService.Create(ctx, &createDTO); err != nil {
+		logger.Error(constant.MsgCreateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgCreateSuccess, nil)
+}
+
+// Update 修改优惠券模板
+func (c *CouponController) Update(ctx *gin.Context) {
+	var updateDTO dto.CouponTemplateDTO
+	if err := ctx.ShouldBindJSON(&updateDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.couponService.Update(ctx, &updateDTO); err != nil {
+		logger.Error(constant.MsgUpdateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgUpdateSuccess, nil)
+}
+
+// GetByID 查询优惠券模板详情
+func (c *CouponController) GetByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	templateVO, err := c.couponService.GetByID(id)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, templateVO)
+}
+
+// PageQuery 优惠券模板分页查询
+func (c *CouponController) PageQuery(ctx *gin.Context) {
+	var queryDTO dto.CouponPageQueryDTO
+	if err := ctx.ShouldBindQuery(&queryDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+	if ctx.Query("status") == "" {
+		queryDTO.Status = constant.InvalidStatus
+	}
+
+	page, err := c.couponService.PageQuery(&queryDTO)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, page)
+}
+
+// UpdateStatus 启用或停用优惠券模板
+func (c *CouponController) UpdateStatus(ctx *gin.Context) {
+	status, err := strconv.Atoi(ctx.Param("status"))
+	if err != nil || (status != constant.CouponEnable && status != constant.CouponDisable) {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err = c.couponService.UpdateStatus(id, status); err != nil {
+		logger.Error(constant.MsgCouponStatusFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgCouponStatusSuccess, nil)
+}
+
+// BatchDelete 批量删除优惠券模板
+func (c *CouponController) BatchDelete(ctx *gin.Context) {
+	idsStr := ctx.Query("ids")
+	if idsStr == "" {
+		logger.Error(constant.MsgMissingRequest)
+		response.BadRequest(ctx, constant.MsgMissingRequest)
+		return
+	}
+	idStrs := strings.Split(idsStr, ",")
+	ids := make([]int, 0, len(idStrs))
+	for _, idStr := range idStrs {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.Error(constant.MsgBadRequest)
+			response.BadRequest(ctx, constant.MsgBadRequest)
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	if err := c.couponService.BatchDelete(ids); err != nil {
+		logger.Error(constant.MsgDeleteFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgDeleteSuccess, nil)
+}
@@ -0,0 +1,106 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+	"takeout/model/dto"
+)
+
+// DeliverySlotController 配送时段管理接口
+type DeliverySlotController struct {
+	deliverySlotService service.DeliverySlotService
+}
+
+func NewDeliverySlotController() *DeliverySlotController {
+	return &DeliverySlotController{}
+}
+
+// List 查询全部配送时段
+func (c *DeliverySlotController) List(ctx *gin.Context) {
+	list, err := c.deliverySlotService.List()
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, list)
+}
+
+// Create 新增配送时段
+func (c *DeliverySlotController) Create(ctx *gin.Context) {
+	var slotDTO dto.DeliverySlotDTO
+	if err := ctx.ShouldBindJSON(&slotDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.deliverySlotService.Create(ctx, &slotDTO); err != nil {
+		logger.Error(constant.MsgCreateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgCreateSuccess, nil)
+}
+
+// Update 修改配送时段
+func (c *DeliverySlotController) Update(ctx *gin.Context) {
+	var slotDTO dto.DeliverySlotDTO
+	if err := ctx.ShouldBindJSON(&slotDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.deliverySlotService.Update(ctx, &slotDTO); err != nil {
+		logger.Error(constant.MsgUpdateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgUpdateSuccess, nil)
+}
+
+// UpdateStatus 启用或停用配送时段
+func (c *DeliverySlotController) UpdateStatus(ctx *gin.Context) {
+	status, err := strconv.Atoi(ctx.Param("status"))
+	if err != nil || (status != constant.SlotEnable && status != constant.SlotDisable) {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err = c.deliverySlotService.UpdateStatus(id, status); err != nil {
+		logger.Error(constant.MsgSlotStatusFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgSlotStatusSuccess, nil)
+}
+
+// Delete 删除配送时段
+func (c *DeliverySlotController) Delete(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err = c.deliverySlotService.Delete(id); err != nil {
+		logger.Error(constant.MsgDeleteFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgDeleteSuccess, nil)
+}
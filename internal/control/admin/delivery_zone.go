@@ -0,0 +1,106 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+	"takeout/model/dto"
+)
+
+// DeliveryZoneController 配送区域管理接口
+type DeliveryZoneController struct {
+	deliveryZoneService service.DeliveryZoneService
+}
+
+func NewDeliveryZoneController() *DeliveryZoneController {
+	return &DeliveryZoneController{}
+}
+
+// List 查询全部配送区域
+func (c *DeliveryZoneController) List(ctx *gin.Context) {
+	list, err := c.deliveryZoneService.List()
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, list)
+}
+
+// Create 新增配送区域
+func (c *DeliveryZoneController) Create(ctx *gin.Context) {
+	var zoneDTO dto.DeliveryZoneDTO
+	if err := ctx.ShouldBindJSON(&zoneDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.deliveryZoneService.Create(ctx, &zoneDTO); err != nil {
+		logger.Error(constant.MsgCreateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgCreateSuccess, nil)
+}
+
+// Update 修改配送区域
+func (c *DeliveryZoneController) Update(ctx *gin.Context) {
+	var zoneDTO dto.DeliveryZoneDTO
+	if err := ctx.ShouldBindJSON(&zoneDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.deliveryZoneService.Update(ctx, &zoneDTO); err != nil {
+		logger.Error(constant.MsgUpdateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgUpdateSuccess, nil)
+}
+
+// UpdateStatus 启用或停用配送区域
+func (c *DeliveryZoneController) UpdateStatus(ctx *gin.Context) {
+	status, err := strconv.Atoi(ctx.Param("status"))
+	if err != nil || (status != constant.ZoneEnable && status != constant.ZoneDisable) {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err = c.deliveryZoneService.UpdateStatus(id, status); err != nil {
+		logger.Error(constant.MsgZoneStatusFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgZoneStatusSuccess, nil)
+}
+
+// Delete 删除配送区域
+func (c *DeliveryZoneController) Delete(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err = c.deliveryZoneService.Delete(id); err != nil {
+		logger.Error(constant.MsgDeleteFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgDeleteSuccess, nil)
+}
@@ -7,6 +7,7 @@ import (
 	"takeout/common/constant"
 	"takeout/common/logger"
 	"takeout/common/response"
+	"takeout/common/utils"
 	"takeout/model/dto"
 
 	"github.com/gin-gonic/gin"
@@ -16,6 +17,7 @@ import (
 // EmployeeController 员工控制器
 type EmployeeController struct {
 	employeeService service.EmployeeService
+	tokenService    service.TokenService
 }
 
 // NewEmployeeController 创建员工控制器
@@ -32,11 +34,44 @@ func NewEmployeeController() *EmployeeController {
 // @Success 200 {object} response.Response "登出成功"
 // @Router /admin/employee/logout [post]
 func (c *EmployeeController) Logout(ctx *gin.Context) {
+	// 注销当前会话，令牌立即失效
+	if err := c.tokenService.Logout(ctx, constant.AudienceAdmin); err != nil {
+		logger.Error(constant.MsgLogoutFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
 	// 登出成功
 	logger.Info(constant.MsgEmployeeLogoutSuccess)
 	response.Success(ctx, constant.MsgEmployeeLogoutSuccess, nil)
 }
 
+// Refresh 刷新令牌
+// @Summary 刷新令牌
+// @Description 使用刷新令牌换取新的访问令牌，旧的刷新令牌随之失效
+// @Tags 员工管理
+// @Accept json
+// @Produce json
+// @Param refresh body dto.RefreshTokenDTO true "刷新令牌"
+// @Success 200 {object} response.Response{data=vo.TokenVO} "刷新成功"
+// @Failure 401 {object} response.Response "刷新令牌无效"
+// @Router /admin/employee/refresh [post]
+func (c *EmployeeController) Refresh(ctx *gin.Context) {
+	var refreshDTO dto.RefreshTokenDTO
+	if err := ctx.ShouldBindJSON(&refreshDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	tokenVO, err := c.tokenService.Refresh(ctx, constant.AudienceAdmin, &refreshDTO)
+	if err != nil {
+		logger.Error(constant.MsgJWTParseFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgRefreshTokenSuccess, tokenVO)
+}
+
 // Login 员工登录
 // @Summary 员工登录
 // @Description 员工登录系统
@@ -46,7 +81,8 @@ func (c *EmployeeController) Logout(ctx *gin.Context) {
 // @Param login body dto.EmployeeLoginDTO true "登录信息"
 // @Success 200 {object} response.Response{data=vo.EmployeeLoginVO} "登录成功"
 // @Failure 400 {object} response.Response "请求参数错误"
-// @Failure 401 {object} response.Response "用户名或密码错误"
+// @Failure 401 {object} response.Response "用户名或密码错误，code 为 103 时需要验证码"
+// @Failure 429 {object} response.Response "失败次数过多，账号或IP已临时锁定"
 // @Router /admin/employee/login [post]
 func (c *EmployeeController) Login(ctx *gin.Context) {
 	// 绑定请求参数
@@ -58,7 +94,7 @@ func (c *EmployeeController) Login(ctx *gin.Context) {
 	}
 
 	// 调用服务层处理登录
-	loginVO, err := c.employeeService.Login(&loginDTO)
+	loginVO, err := c.employeeService.Login(ctx, &loginDTO)
 	if err != nil {
 		logger.Error(constant.MsgEmployeeLoginFail, zap.Error(err), zap.String("username", loginDTO.Username))
 		response.ErrorResponse(ctx, err)
@@ -70,6 +106,43 @@ func (c *EmployeeController) Login(ctx *gin.Context) {
 	response.Success(ctx, constant.MsgEmployeeLoginSuccess, loginVO)
 }
 
+// Captcha 获取登录验证码
+// @Summary 获取登录验证码
+// @Description 连续登录失败后需要携带验证码，答案只能使用一次
+// @Tags 员工管理
+// @Produce json
+// @Success 200 {object} response.Response{data=vo.CaptchaVO} "获取成功"
+// @Router /admin/employee/captcha [get]
+func (c *EmployeeController) Captcha(ctx *gin.Context) {
+	captchaVO, err := c.employeeService.Captcha(ctx)
+	if err != nil {
+		logger.Error(constant.MsgCaptchaFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, captchaVO)
+}
+
+// Unlock 解除员工的登录锁定
+func (c *EmployeeController) Unlock(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err), zap.String("id", idStr))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err = c.employeeService.Unlock(ctx, id); err != nil {
+		logger.Error(constant.MsgEmployeeUnlockFail, zap.Error(err), zap.Int("id", id))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+
+	logger.Info(constant.MsgEmployeeUnlockSuccess, zap.Int("id", id))
+	response.Success(ctx, constant.MsgEmployeeUnlockSuccess, nil)
+}
+
 // Create 创建新员工
 func (c *EmployeeController) Create(ctx *gin.Context) {
 	// 绑定请求参数
@@ -156,7 +229,7 @@ func (c *EmployeeController) UpdateStatus(ctx *gin.Context) {
 	}
 
 	// 调用服务层更新员工状态
-	err = c.employeeService.UpdateStatusById(status, id)
+	err = c.employeeService.UpdateStatusById(ctx, status, id)
 	if err != nil {
 		logger.Error(constant.MsgEmployeeStatusUpdateFail, zap.Error(err), zap.Int("status", status), zap.Int("id", id))
 		response.ErrorResponse(ctx, err)
@@ -182,6 +255,17 @@ func (c *EmployeeController) UpdatePassword(ctx *gin.Context) {
 		return
 	}
 
+	// 未指定员工时修改自己的密码
+	if passwordDTO.EmpId == 0 {
+		empID, err := utils.GetId(ctx)
+		if err != nil {
+			logger.Error(constant.MsgGetAccountInfoFail, zap.Error(err))
+			response.ErrorResponse(ctx, err)
+			return
+		}
+		passwordDTO.EmpId = empID
+	}
+
 	// 调用服务层修改密码
 	err := c.employeeService.UpdatePassword(ctx, &passwordDTO)
 	if err != nil {
@@ -0,0 +1,124 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+	"takeout/model/dto"
+)
+
+// KitchenController 后厨档口和工单接口
+type KitchenController struct {
+	kitchenService service.KitchenService
+}
+
+func NewKitchenController() *KitchenController {
+	return &KitchenController{}
+}
+
+// ListStations 查询全部档口
+func (c *KitchenController) ListStations(ctx *gin.Context) {
+	list, err := c.kitchenService.ListStations()
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, list)
+}
+
+// CreateStation 新增档口
+func (c *KitchenController) CreateStation(ctx *gin.Context) {
+	var stationDTO dto.KitchenStationDTO
+	if err := ctx.ShouldBindJSON(&stationDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.kitchenService.CreateStation(ctx, &stationDTO); err != nil {
+		logger.Error(constant.MsgCreateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgCreateSuccess, nil)
+}
+
+// UpdateStation 修改档口
+func (c *KitchenController) UpdateStation(ctx *gin.Context) {
+	var stationDTO dto.KitchenStationDTO
+	if err := ctx.ShouldBindJSON(&stationDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.kitchenService.UpdateStation(ctx, &stationDTO); err != nil {
+		logger.Error(constant.MsgUpdateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgUpdateSuccess, nil)
+}
+
+// DeleteStation 删除档口
+func (c *KitchenController) DeleteStation(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err = c.kitchenService.DeleteStation(id); err != nil {
+		logger.Error(constant.MsgDeleteFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgDeleteSuccess, nil)
+}
+
+// Tickets 按档口查询工单
+func (c *KitchenController) Tickets(ctx *gin.Context) {
+	var queryDTO dto.KitchenTicketQueryDTO
+	if err := ctx.ShouldBindQuery(&queryDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	list, err := c.kitchenService.Tickets(&queryDTO)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, list)
+}
+
+// UpdateTicketStatus 开始制作或出餐
+func (c *KitchenController) UpdateTicketStatus(ctx *gin.Context) {
+	status, err := strconv.Atoi(ctx.Param("status"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err = c.kitchenService.UpdateTicketStatus(id, status); err != nil {
+		logger.Error(constant.MsgTicketUpdateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgTicketUpdateSuccess, nil)
+}
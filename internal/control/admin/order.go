@@ -13,6 +13,7 @@ import (
 
 type OrderController struct {
 	orderService service.OrderService
+	printService service.PrintService
 }
 
 func NewOrderController() *OrderController {
@@ -81,7 +82,7 @@ func (c *OrderController) Confirm(ctx *gin.Context) {
 		return
 	}
 
-	err := c.orderService.Confirm(&confirmDTO)
+	err := c.orderService.Confirm(ctx, &confirmDTO)
 	if err != nil {
 		logger.Error(constant.MsgUpdateFail, zap.Error(err))
 		response.ErrorResponse(ctx, err)
@@ -99,7 +100,7 @@ func (c *OrderController) Reject(ctx *gin.Context) {
 		return
 	}
 
-	err := c.orderService.Reject(&rejectDTO)
+	err := c.orderService.Reject(ctx, &rejectDTO)
 	if err != nil {
 		logger.Error(constant.MsgUpdateFail, zap.Error(err))
 		response.ErrorResponse(ctx, err)
@@ -117,7 +118,7 @@ func (c *OrderController) Cancel(ctx *gin.Context) {
 		return
 	}
 
-	err := c.orderService.Cancel(&cancelDTO)
+	err := c.orderService.Cancel(ctx, &cancelDTO)
 	if err != nil {
 		logger.Error(constant.MsgUpdateFail, zap.Error(err))
 		response.ErrorResponse(ctx, err)
@@ -141,7 +142,7 @@ func (c *OrderController) Delivery(ctx *gin.Context) {
 		return
 	}
 
-	err = c.orderService.Delivery(id)
+	err = c.orderService.Delivery(ctx, id)
 	if err != nil {
 		logger.Error(constant.MsgUpdateFail, zap.Error(err))
 		response.ErrorResponse(ctx, err)
@@ -165,7 +166,7 @@ func (c *OrderController) Complete(ctx *gin.Context) {
 		return
 	}
 
-	err = c.orderService.Complete(id)
+	err = c.orderService.Complete(ctx, id)
 	if err != nil {
 		logger.Error(constant.MsgUpdateFail, zap.Error(err))
 		response.ErrorResponse(ctx, err)
@@ -173,3 +174,43 @@ func (c *OrderController) Complete(ctx *gin.Context) {
 	}
 	response.Success(ctx, constant.MsgSuccess, nil)
 }
+
+// AssignRider 指派骑手，不指定骑手时自动指派
+func (c *OrderController) AssignRider(ctx *gin.Context) {
+	var assignDTO dto.OrderAssignDTO
+	if err := ctx.ShouldBindJSON(&assignDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.orderService.AssignRider(&assignDTO); err != nil {
+		logger.Error(constant.MsgRiderAssignFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgRiderAssignSuccess, nil)
+}
+
+// Reprint 补打小票，type 为 1 只打顾客小票，为 2 只打后厨单，不传时都打印
+func (c *OrderController) Reprint(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+	printType, err := strconv.Atoi(ctx.DefaultQuery("type", "0"))
+	if err != nil || printType < 0 || printType > constant.PrintKitchen {
+		logger.Error(constant.MsgBadRequest, zap.String("type", ctx.Query("type")))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err = c.printService.Reprint(id, printType); err != nil {
+		logger.Error(constant.MsgPrintFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgPrintSuccess, nil)
+}
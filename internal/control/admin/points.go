@@ -0,0 +1,55 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+	"takeout/model/dto"
+)
+
+// PointsController 会员积分管理接口
+type PointsController struct {
+	pointsService service.PointsService
+}
+
+func NewPointsController() *PointsController {
+	return &PointsController{}
+}
+
+// Page 分页查询积分流水
+func (c *PointsController) Page(ctx *gin.Context) {
+	var queryDTO dto.PointsPageQueryDTO
+	if err := ctx.ShouldBindQuery(&queryDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	page, err := c.pointsService.PageQuery(&queryDTO)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, page)
+}
+
+// Adjust 人工调整用户积分
+func (c *PointsController) Adjust(ctx *gin.Context) {
+	var adjustDTO dto.PointsAdjustDTO
+	if err := ctx.ShouldBindJSON(&adjustDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.pointsService.Adjust(ctx, &adjustDTO); err != nil {
+		logger.Error(constant.MsgPointsAdjustFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgPointsAdjustSuccess, nil)
+}
@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+	"takeout/model/dto"
+)
+
+// RefundController 退款单管理接口
+type RefundController struct {
+	refundService service.RefundService
+}
+
+func NewRefundController() *RefundController {
+	return &RefundController{}
+}
+
+// Page 分页查询退款单
+func (c *RefundController) Page(ctx *gin.Context) {
+	var queryDTO dto.RefundPageQueryDTO
+	if err := ctx.ShouldBindQuery(&queryDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	page, err := c.refundService.PageQuery(&queryDTO)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, page)
+}
+
+// Resubmit 重新提交退款
+func (c *RefundController) Resubmit(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err = c.refundService.Resubmit(id); err != nil {
+		logger.Error(constant.MsgRefundFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgRefundResubmitted, nil)
+}
@@ -7,11 +7,13 @@ import (
 	"takeout/common/logger"
 	"takeout/common/response"
 	"takeout/internal/service"
+	"takeout/model/dto"
 	"time"
 )
 
 type ReportController struct {
 	reportService service.ReportService
+	reviewService service.ReviewService
 }
 
 func NewReportController() *ReportController {
@@ -100,3 +102,21 @@ func (c *ReportController) SalesTop10Statistics(ctx *gin.Context) {
 func (c *ReportController) Export(ctx *gin.Context) {
 	c.reportService.Export(ctx)
 }
+
+// LowRatedDishes 低分菜品报表
+func (c *ReportController) LowRatedDishes(ctx *gin.Context) {
+	var queryDTO dto.LowRatedQueryDTO
+	if err := ctx.ShouldBindQuery(&queryDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	ret, err := c.reviewService.LowRatedDishes(&queryDTO)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgSuccess, ret)
+}
@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+	"takeout/model/dto"
+)
+
+// ReviewController 评价管理接口
+type ReviewController struct {
+	reviewService service.ReviewService
+}
+
+func NewReviewController() *ReviewController {
+	return &ReviewController{}
+}
+
+// Page 分页查询评价
+func (c *ReviewController) Page(ctx *gin.Context) {
+	var queryDTO dto.ReviewPageQueryDTO
+	if err := ctx.ShouldBindQuery(&queryDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	page, err := c.reviewService.PageQuery(&queryDTO)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, page)
+}
+
+// Reply 回复评价
+func (c *ReviewController) Reply(ctx *gin.Context) {
+	var replyDTO dto.ReviewReplyDTO
+	if err := ctx.ShouldBindJSON(&replyDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.reviewService.Reply(ctx, &replyDTO); err != nil {
+		logger.Error(constant.MsgReviewReplyFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgReviewReplySuccess, nil)
+}
+
+// UpdateStatus 显示或隐藏评价
+func (c *ReviewController) UpdateStatus(ctx *gin.Context) {
+	status, err := strconv.Atoi(ctx.Param("status"))
+	if err != nil || (status != constant.ReviewShow && status != constant.ReviewHidden) {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err = c.reviewService.UpdateStatus(id, status); err != nil {
+		logger.Error(constant.MsgReviewStatusFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgReviewStatusSuccess, nil)
+}
@@ -0,0 +1,110 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+	"takeout/model/dto"
+)
+
+// RiderController 骑手管理接口
+type RiderController struct {
+	riderService service.RiderService
+}
+
+func NewRiderController() *RiderController {
+	return &RiderController{}
+}
+
+// Create 新增骑手
+func (c *RiderController) Create(ctx *gin.Context) {
+	var createDTO dto.RiderDTO
+	if err := ctx.ShouldBindJSON(&createDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.riderService.Create(ctx, &createDTO); err != nil {
+		logger.Error(constant.MsgCreateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgCreateSuccess, nil)
+}
+
+// Update 修改骑手信息
+func (c *RiderController) Update(ctx *gin.Context) {
+	var updateDTO dto.RiderDTO
+	if err := ctx.ShouldBindJSON(&updateDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.riderService.Update(ctx, &updateDTO); err != nil {
+		logger.Error(constant.MsgUpdateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgUpdateSuccess, nil)
+}
+
+// PageQuery 骑手分页查询
+func (c *RiderController) PageQuery(ctx *gin.Context) {
+	var queryDTO dto.RiderPageQueryDTO
+	if err := ctx.ShouldBindQuery(&queryDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+	if ctx.Query("status") == "" {
+		queryDTO.Status = constant.InvalidStatus
+	}
+
+	page, err := c.riderService.PageQuery(&queryDTO)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, page)
+}
+
+// UpdateStatus 启用、禁用骑手
+func (c *RiderController) UpdateStatus(ctx *gin.Context) {
+	status, err := strconv.Atoi(ctx.Param("status"))
+	if err != nil || (status != constant.RiderEnable && status != constant.RiderDisable) {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err = c.riderService.UpdateStatus(id, status); err != nil {
+		logger.Error(constant.MsgUpdateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgUpdateSuccess, nil)
+}
+
+// ListOnline 查询接单中的骑手
+func (c *RiderController) ListOnline(ctx *gin.Context) {
+	list, err := c.riderService.ListOnline()
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, list)
+}
@@ -0,0 +1,106 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+	"takeout/model/dto"
+)
+
+// RoleController 角色管理接口
+type RoleController struct {
+	roleService service.RoleService
+}
+
+func NewRoleController() *RoleController {
+	return &RoleController{}
+}
+
+// Permissions 查询可分配的全部权限
+func (c *RoleController) Permissions(ctx *gin.Context) {
+	response.Success(ctx, constant.MsgSuccess, c.roleService.Permissions())
+}
+
+// List 查询全部角色
+func (c *RoleController) List(ctx *gin.Context) {
+	list, err := c.roleService.List()
+	if err != nil {
+		logger.Error(constant.MsgRoleQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgSuccess, list)
+}
+
+// GetByID 查询角色详情
+func (c *RoleController) GetByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	roleVO, err := c.roleService.GetByID(id)
+	if err != nil {
+		logger.Error(constant.MsgRoleQueryFail, zap.Error(err), zap.Int("id", id))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgSuccess, roleVO)
+}
+
+// Create 新增角色
+func (c *RoleController) Create(ctx *gin.Context) {
+	var roleDTO dto.RoleDTO
+	if err := ctx.ShouldBindJSON(&roleDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.roleService.Create(ctx, &roleDTO); err != nil {
+		logger.Error(constant.MsgRoleCreateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgCreateSuccess, nil)
+}
+
+// Update 修改角色名称和权限
+func (c *RoleController) Update(ctx *gin.Context) {
+	var roleDTO dto.RoleDTO
+	if err := ctx.ShouldBindJSON(&roleDTO); err != nil || roleDTO.ID == 0 {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.roleService.Update(ctx, &roleDTO); err != nil {
+		logger.Error(constant.MsgRoleUpdateFail, zap.Error(err), zap.Int("id", roleDTO.ID))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgUpdateSuccess, nil)
+}
+
+// Delete 删除角色
+func (c *RoleController) Delete(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err = c.roleService.Delete(id); err != nil {
+		logger.Error(constant.MsgRoleDeleteFail, zap.Error(err), zap.Int("id", id))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgDeleteSuccess, nil)
+}
@@ -8,6 +8,7 @@ import (
 	"takeout/common/logger"
 	"takeout/common/response"
 	"takeout/internal/service"
+	"takeout/model/dto"
 )
 
 // ShopController 店铺控制器
@@ -54,3 +55,114 @@ func (c *ShopController) GetStatus(ctx *gin.Context) {
 	}
 	response.Success(ctx, constant.MsgQuerySuccess, status)
 }
+
+// GetEffectiveStatus 获取综合营业时间、暂停后的实际营业状态
+func (c *ShopController) GetEffectiveStatus(ctx *gin.Context) {
+	statusVO, err := c.shopService.Status()
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, statusVO)
+}
+
+// ListHours 查询每周营业时间
+func (c *ShopController) ListHours(ctx *gin.Context) {
+	list, err := c.shopService.ListHours()
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, list)
+}
+
+// SaveHours 整体保存每周营业时间
+func (c *ShopController) SaveHours(ctx *gin.Context) {
+	var hoursDTOs []dto.BusinessHoursDTO
+	if err := ctx.ShouldBindJSON(&hoursDTOs); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.shopService.SaveHours(hoursDTOs); err != nil {
+		logger.Error(constant.MsgUpdateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgBusinessHoursSaved, nil)
+}
+
+// ListSpecialDays 查询今天及以后的特殊营业日
+func (c *ShopController) ListSpecialDays(ctx *gin.Context) {
+	list, err := c.shopService.ListSpecialDays()
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, list)
+}
+
+// SaveSpecialDay 设置节假日、特殊日期的营业安排
+func (c *ShopController) SaveSpecialDay(ctx *gin.Context) {
+	var dayDTO dto.SpecialDayDTO
+	if err := ctx.ShouldBindJSON(&dayDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.shopService.SaveSpecialDay(&dayDTO); err != nil {
+		logger.Error(constant.MsgUpdateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgUpdateSuccess, nil)
+}
+
+// DeleteSpecialDay 删除某天的特殊营业安排
+func (c *ShopController) DeleteSpecialDay(ctx *gin.Context) {
+	date := ctx.Query("date")
+	if date == "" {
+		logger.Error(constant.MsgMissingRequest)
+		response.BadRequest(ctx, constant.MsgMissingRequest)
+		return
+	}
+
+	if err := c.shopService.DeleteSpecialDay(date); err != nil {
+		logger.Error(constant.MsgDeleteFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgDeleteSuccess, nil)
+}
+
+// Pause 忙碌时暂停接单，到时间后自动恢复
+func (c *ShopController) Pause(ctx *gin.Context) {
+	var pauseDTO dto.ShopPauseDTO
+	if err := ctx.ShouldBindJSON(&pauseDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.shopService.Pause(pauseDTO.Minutes); err != nil {
+		logger.Error(constant.MsgUpdateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgShopPauseSuccess, nil)
+}
+
+// Resume 提前恢复接单
+func (c *ShopController) Resume(ctx *gin.Context) {
+	if err := c.shopService.Resume(); err != nil {
+		logger.Error(constant.MsgUpdateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgShopResumeSuccess, nil)
+}
@@ -0,0 +1,55 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+	"takeout/model/dto"
+)
+
+// WalletController 用户余额管理接口
+type WalletController struct {
+	walletService service.WalletService
+}
+
+func NewWalletController() *WalletController {
+	return &WalletController{}
+}
+
+// Page 分页查询余额流水
+func (c *WalletController) Page(ctx *gin.Context) {
+	var queryDTO dto.WalletPageQueryDTO
+	if err := ctx.ShouldBindQuery(&queryDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	page, err := c.walletService.PageQuery(&queryDTO)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, page)
+}
+
+// Adjust 人工调整用户余额
+func (c *WalletController) Adjust(ctx *gin.Context) {
+	var adjustDTO dto.WalletAdjustDTO
+	if err := ctx.ShouldBindJSON(&adjustDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.walletService.Adjust(ctx, &adjustDTO); err != nil {
+		logger.Error(constant.MsgWalletAdjustFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgWalletAdjustSuccess, nil)
+}
@@ -6,83 +6,69 @@ import (
 	"github.com/go-pay/gopay/wechat/v3"
 	"go.uber.org/zap"
 	"net/http"
+	"strings"
 	"takeout/common/constant"
-	"takeout/common/global"
+	"takeout/common/errs"
 	"takeout/common/logger"
-	"takeout/common/utils"
+	"takeout/common/payment"
 	"takeout/internal/service"
 )
 
 type NotifyController struct {
-	orderService service.OrderService
+	orderService  service.OrderService
+	refundService service.RefundService
+	walletService service.WalletService
 }
 
 func NewNotifyController() *NotifyController {
 	return &NotifyController{}
 }
 
-// PaySuccess 解析微信回调请求的参数到 V3NotifyReq 结构体
+// PaySuccess 支付结果回调，由当前支付渠道解析并验签
 func (c *NotifyController) PaySuccess(ctx *gin.Context) {
-	notifyReq, err := wechat.V3ParseNotify(ctx.Request)
+	result, err := payment.GetProvider().ParseNotify(ctx.Request)
 	if err != nil {
-		logger.Error("V3ParseNotify ERR", zap.Error(err))
-		ctx.JSON(http.StatusOK, &wechat.V3NotifyRsp{Code: gopay.FAIL, Message: "回调内容异常"})
+		logger.Error("ParseNotify ERR", zap.Error(err))
+		ctx.JSON(http.StatusOK, &wechat.V3NotifyRsp{Code: gopay.FAIL, Message: errs.GetMessage(err)})
 		return
 	}
-	// 获取微信平台证书
-	wxClient := utils.GetWechatClientV3()
-	// 验证异步通知的签名
-	err = notifyReq.VerifySignByPK(wxClient.WxPublicKey())
-	if err != nil {
-		logger.Error("VerifySignByPKMap ERR", zap.Error(err))
-		ctx.JSON(http.StatusOK, &wechat.V3NotifyRsp{Code: gopay.FAIL, Message: "内容验证失败"})
-		return
-	}
-	// 普通支付通知解密
-	result, rErr := notifyReq.DecryptPayCipherText(global.Config.Wechat.ApiV3Key)
-	if rErr != nil {
-		logger.Error("DecryptPayCipherText Error", zap.Error(err))
-		ctx.JSON(http.StatusOK, &wechat.V3NotifyRsp{Code: gopay.FAIL, Message: "内容解密失败"})
-		return
-	}
-	if result != nil && result.TradeState == "SUCCESS" {
-		logger.Info("商户平台订单号", zap.String("out_trade_no", result.OutTradeNo))
-		logger.Info("微信支付交易号", zap.String("transaction_id", result.TransactionId))
+	if result.Type == payment.NotifyPay && result.Success {
+		logger.Info("商户平台订单号", zap.String("out_trade_no", result.OrderNumber))
+		logger.Info("支付平台交易号", zap.String("transaction_id", result.TransactionID))
 
-		err = c.orderService.PaySuccess(result.OutTradeNo)
-		logger.Error(constant.Update, zap.Error(err))
+		// 充值单和订单共用支付回调，按单号前缀区分
+		if strings.HasPrefix(result.OrderNumber, constant.RechargePrefix) {
+			err = c.walletService.RechargeSuccess(result.OrderNumber)
+		} else {
+			err = c.orderService.PaySuccess(result.OrderNumber)
+		}
+		if err != nil {
+			logger.Error(constant.MsgUpdateFail, zap.Error(err))
+			ctx.JSON(http.StatusOK, &wechat.V3NotifyRsp{Code: gopay.FAIL, Message: "订单处理失败"})
+			return
+		}
 	}
 
 	// 此写法是 gin 框架返回微信的写法
 	ctx.JSON(http.StatusOK, &wechat.V3NotifyRsp{Code: gopay.SUCCESS, Message: gopay.SUCCESS})
 }
 
-// RefundSuccess 微信支付退款回调
+// RefundSuccess 退款结果回调
 func (c *NotifyController) RefundSuccess(ctx *gin.Context) {
-	notifyReq, err := wechat.V3ParseNotify(ctx.Request)
+	result, err := payment.GetProvider().ParseNotify(ctx.Request)
 	if err != nil {
-		logger.Error("V3ParseNotify ERR", zap.Error(err))
-		ctx.JSON(http.StatusOK, &wechat.V3NotifyRsp{Code: gopay.FAIL, Message: "回调内容异常"})
-		return
-	}
-	// 获取微信平台证书
-	wxClient := utils.GetWechatClientV3()
-	// 验证异步通知的签名
-	err = notifyReq.VerifySignByPK(wxClient.WxPublicKey())
-	if err != nil {
-		logger.Error("VerifySignByPKMap ERR", zap.Error(err))
-		ctx.JSON(http.StatusOK, &wechat.V3NotifyRsp{Code: gopay.FAIL, Message: "内容验证失败"})
-		return
-	}
-	// 普通支付通知解密
-	result, rErr := notifyReq.DecryptRefundCipherText(global.Config.Wechat.ApiV3Key)
-	if rErr != nil {
-		logger.Error("DecryptPayCipherText Error", zap.Error(err))
-		ctx.JSON(http.StatusOK, &wechat.V3NotifyRsp{Code: gopay.FAIL, Message: "内容解密失败"})
+		logger.Error("ParseNotify ERR", zap.Error(err))
+		ctx.JSON(http.StatusOK, &wechat.V3NotifyRsp{Code: gopay.FAIL, Message: errs.GetMessage(err)})
 		return
 	}
-	if result != nil && result.RefundStatus == "SUCCESS" {
-		logger.Info("退款成功", zap.String("退单号", result.OutRefundNo))
+	if result.Type == payment.NotifyRefund {
+		logger.Info("退款回调", zap.String("退单号", result.RefundNumber), zap.String("refund_status", result.Status))
+		// SUCCESS 退款成功，CLOSED 退款关闭，ABNORMAL 退款异常
+		if err = c.refundService.Callback(result.RefundNumber, result.Success, result.Status); err != nil {
+			logger.Error(constant.MsgRefundFail, zap.Error(err))
+			ctx.JSON(http.StatusOK, &wechat.V3NotifyRsp{Code: gopay.FAIL, Message: "退款处理失败"})
+			return
+		}
 	}
 
 	ctx.JSON(http.StatusOK, &wechat.V3NotifyRsp{Code: gopay.SUCCESS, Message: gopay.SUCCESS})
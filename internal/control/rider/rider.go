@@ -0,0 +1,160 @@
+package rider
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+	"takeout/model/dto"
+)
+
+// RiderController 骑手端接口
+type RiderController struct {
+	riderService service.RiderService
+	tokenService service.TokenService
+}
+
+func NewRiderController() *RiderController {
+	return &RiderController{}
+}
+
+// Login 骑手登录
+func (c *RiderController) Login(ctx *gin.Context) {
+	var loginDTO dto.RiderLoginDTO
+	if err := ctx.ShouldBindJSON(&loginDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	loginVO, err := c.riderService.Login(&loginDTO)
+	if err != nil {
+		logger.Error(constant.MsgRiderLoginFail, zap.Error(err), zap.String("username", loginDTO.Username))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgRiderLoginSuccess, loginVO)
+}
+
+// Refresh 刷新令牌
+func (c *RiderController) Refresh(ctx *gin.Context) {
+	var refreshDTO dto.RefreshTokenDTO
+	if err := ctx.ShouldBindJSON(&refreshDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	tokenVO, err := c.tokenService.Refresh(ctx, constant.AudienceRider, &refreshDTO)
+	if err != nil {
+		logger.Error(constant.MsgJWTParseFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgRefreshTokenSuccess, tokenVO)
+}
+
+// Logout 骑手退出登录
+func (c *RiderController) Logout(ctx *gin.Context) {
+	if err := c.tokenService.Logout(ctx, constant.AudienceRider); err != nil {
+		logger.Error(constant.MsgLogoutFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgRiderLogoutSuccess, nil)
+}
+
+// UpdateWorkStatus 切换接单、休息状态
+func (c *RiderController) UpdateWorkStatus(ctx *gin.Context) {
+	status, err := strconv.Atoi(ctx.Param("status"))
+	if err != nil || (status != constant.RiderOnline && status != constant.RiderOffline) {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err = c.riderService.UpdateWorkStatus(ctx, status); err != nil {
+		logger.Error(constant.MsgUpdateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgUpdateSuccess, nil)
+}
+
+// ReportLocation 上报当前位置
+func (c *RiderController) ReportLocation(ctx *gin.Context) {
+	var locationDTO dto.RiderLocationDTO
+	if err := ctx.ShouldBindJSON(&locationDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.riderService.ReportLocation(ctx, &locationDTO); err != nil {
+		logger.Error(constant.MsgRiderLocationFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgSuccess, nil)
+}
+
+// Orders 查询指派给自己的订单，riderStatus 不传时查询进行中的订单
+func (c *RiderController) Orders(ctx *gin.Context) {
+	riderStatus := 0
+	if statusStr := ctx.Query("riderStatus"); statusStr != "" {
+		var err error
+		if riderStatus, err = strconv.Atoi(statusStr); err != nil {
+			logger.Error(constant.MsgBadRequest, zap.Error(err))
+			response.BadRequest(ctx, constant.MsgBadRequest)
+			return
+		}
+	}
+
+	orders, err := c.riderService.Orders(ctx, riderStatus)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, orders)
+}
+
+// Accept 接受指派的订单
+func (c *RiderController) Accept(ctx *gin.Context) {
+	c.handleOrder(ctx, c.riderService.Accept)
+}
+
+// Reject 拒绝指派的订单
+func (c *RiderController) Reject(ctx *gin.Context) {
+	c.handleOrder(ctx, c.riderService.Reject)
+}
+
+// Pickup 到店取餐
+func (c *RiderController) Pickup(ctx *gin.Context) {
+	c.handleOrder(ctx, c.riderService.Pickup)
+}
+
+// Deliver 确认送达
+func (c *RiderController) Deliver(ctx *gin.Context) {
+	c.handleOrder(ctx, c.riderService.Deliver)
+}
+
+// 解析路径中的订单ID并执行配送操作
+func (c *RiderController) handleOrder(ctx *gin.Context, handle func(*gin.Context, int) error) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err = handle(ctx, id); err != nil {
+		logger.Error(constant.MsgRiderOrderStatusError, zap.Int("orderId", id), zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgSuccess, nil)
+}
@@ -0,0 +1,68 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+)
+
+type CouponController struct {
+	couponService service.CouponService
+}
+
+func NewCouponController() *CouponController {
+	return &CouponController{}
+}
+
+// Claimable 查询可以领取的优惠券
+func (c *CouponController) Claimable(ctx *gin.Context) {
+	list, err := c.couponService.Claimable()
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, list)
+}
+
+// Claim 领取优惠券
+func (c *CouponController) Claim(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err = c.couponService.Claim(ctx, id); err != nil {
+		logger.Error(constant.MsgCouponClaimFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgCouponClaimSuccess, nil)
+}
+
+// List 查询我的优惠券，status 1 未使用 2 已使用 3 已过期，不传查询全部
+func (c *CouponController) List(ctx *gin.Context) {
+	status := 0
+	if statusStr := ctx.Query("status"); statusStr != "" {
+		var err error
+		if status, err = strconv.Atoi(statusStr); err != nil {
+			logger.Error(constant.MsgBadRequest, zap.Error(err))
+			response.BadRequest(ctx, constant.MsgBadRequest)
+			return
+		}
+	}
+
+	list, err := c.couponService.List(ctx, status)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, list)
+}
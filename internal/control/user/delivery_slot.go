@@ -0,0 +1,31 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+)
+
+// DeliverySlotController 配送时段控制器
+type DeliverySlotController struct {
+	deliverySlotService service.DeliverySlotService
+}
+
+// NewDeliverySlotController 创建配送时段控制器
+func NewDeliverySlotController() *DeliverySlotController {
+	return &DeliverySlotController{}
+}
+
+// Available 查询今天和明天的配送时段，下单时选择 available 为 true 的时段
+func (c *DeliverySlotController) Available(ctx *gin.Context) {
+	list, err := c.deliverySlotService.Available()
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, list)
+}
@@ -4,13 +4,17 @@ import (
 	"errors"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"io"
 	"strconv"
 	"takeout/common/constant"
 	"takeout/common/errs"
 	"takeout/common/logger"
 	"takeout/common/response"
+	"takeout/common/utils"
 	"takeout/internal/service"
+	"takeout/internal/websocket"
 	"takeout/model/dto"
+	"time"
 )
 
 type OrderController struct {
@@ -53,30 +57,7 @@ func (c *OrderController) Payment(ctx *gin.Context) {
 		return
 	}
 
-	payVO, err := c.orderService.Payment(&payDTO)
-	if err != nil {
-		var e *errs.Error
-		if errors.As(err, &e); e.Code == constant.CodeBusinessError {
-			logger.Info(constant.MsgBusinessError, zap.Error(err))
-		} else {
-			logger.Error(constant.MsgPayFail, zap.Error(err))
-		}
-		response.ErrorResponse(ctx, err)
-		return
-	}
-	response.Success(ctx, constant.MsgSuccess, payVO)
-}
-
-// RealPayment 真实的微信支付
-func (c *OrderController) RealPayment(ctx *gin.Context) {
-	var payDTO dto.OrderPaymentDTO
-	if err := ctx.ShouldBindJSON(&payDTO); err != nil {
-		logger.Error(constant.MsgBadRequest, zap.Error(err))
-		response.BadRequest(ctx, constant.MsgBadRequest)
-		return
-	}
-
-	payVO, err := c.orderService.RealPayment(ctx, &payDTO)
+	payVO, err := c.orderService.Payment(ctx, &payDTO)
 	if err != nil {
 		var e *errs.Error
 		if errors.As(err, &e); e.Code == constant.CodeBusinessError {
@@ -123,7 +104,7 @@ func (c *OrderController) Detail(ctx *gin.Context) {
 		return
 	}
 
-	dishVO, err := c.orderService.Detail(id)
+	dishVO, err := c.orderService.UserDetail(ctx, id)
 	if err != nil {
 		logger.Error(constant.MsgQueryFail, zap.Error(err))
 		response.ErrorResponse(ctx, err)
@@ -147,7 +128,7 @@ func (c *OrderController) Cancel(ctx *gin.Context) {
 		return
 	}
 
-	err = c.orderService.CancelByUser(id)
+	err = c.orderService.CancelByUser(ctx, id)
 	if err != nil {
 		logger.Error(constant.MsgOrderCancelFail, zap.Error(err))
 		response.ErrorResponse(ctx, err)
@@ -195,7 +176,7 @@ func (c *OrderController) Reminder(ctx *gin.Context) {
 		return
 	}
 
-	err = c.orderService.Reminder(id)
+	err = c.orderService.Reminder(ctx, id)
 	if err != nil {
 		logger.Error(constant.MsgServerError, zap.Error(err))
 		response.ErrorResponse(ctx, err)
@@ -203,3 +184,35 @@ func (c *OrderController) Reminder(ctx *gin.Context) {
 	}
 	response.Success(ctx, constant.MsgSuccess, nil)
 }
+
+// Events 通过 SSE 推送当前用户的订单状态变更，消息内容与 websocket 用户频道一致
+func (c *OrderController) Events(ctx *gin.Context) {
+	userID, err := utils.GetId(ctx)
+	if err != nil {
+		logger.Error(constant.MsgGetAccountInfoFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	messages, cancel := websocket.SubscribeUser("sse-"+ctx.ClientIP(), userID)
+	defer cancel()
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	ticker := time.NewTicker(websocket.PingPeriod)
+	defer ticker.Stop()
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case msg, ok := <-messages:
+			if !ok {
+				return false
+			}
+			ctx.SSEvent("order", string(msg))
+			return true
+		case <-ticker.C:
+			ctx.SSEvent("ping", "")
+			return true
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+	"takeout/model/dto"
+)
+
+// PointsController 用户积分接口
+type PointsController struct {
+	pointsService service.PointsService
+}
+
+func NewPointsController() *PointsController {
+	return &PointsController{}
+}
+
+// Info 查询积分余额和会员等级进度
+func (c *PointsController) Info(ctx *gin.Context) {
+	info, err := c.pointsService.Info(ctx)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, info)
+}
+
+// Page 分页查询积分流水
+func (c *PointsController) Page(ctx *gin.Context) {
+	var queryDTO dto.PointsPageQueryDTO
+	if err := ctx.ShouldBindQuery(&queryDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	page, err := c.pointsService.Page(ctx, &queryDTO)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, page)
+}
@@ -0,0 +1,56 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+	"takeout/model/dto"
+)
+
+// ReviewController 用户评价接口
+type ReviewController struct {
+	reviewService service.ReviewService
+}
+
+func NewReviewController() *ReviewController {
+	return &ReviewController{}
+}
+
+// Submit 评价已完成的订单
+func (c *ReviewController) Submit(ctx *gin.Context) {
+	var reviewDTO dto.ReviewDTO
+	if err := ctx.ShouldBindJSON(&reviewDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.reviewService.Submit(ctx, &reviewDTO); err != nil {
+		logger.Error(constant.MsgReviewSubmitFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgReviewSubmitSuccess, nil)
+}
+
+// GetByOrder 查看订单的评价
+func (c *ReviewController) GetByOrder(ctx *gin.Context) {
+	orderID, err := strconv.Atoi(ctx.Param("orderId"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	review, err := c.reviewService.GetByOrder(ctx, orderID)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, review)
+}
@@ -19,9 +19,9 @@ func NewShopController() *ShopController {
 	return &ShopController{}
 }
 
-// GetStatus 获取店铺状态
+// GetStatus 获取店铺实际营业状态，打烊时返回下次营业时间
 func (c *ShopController) GetStatus(ctx *gin.Context) {
-	status, err := c.shopService.GetStatus()
+	status, err := c.shopService.Status()
 	if err != nil {
 		logger.Error(constant.MsgQueryFail, zap.Error(err))
 		response.ErrorResponse(ctx, err)
@@ -1,6 +1,8 @@
 package user
 
 import (
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"takeout/common/constant"
@@ -12,6 +14,7 @@ import (
 
 type ShoppingCartController struct {
 	shoppingCartService service.ShoppingCartService
+	couponService       service.CouponService
 }
 
 func NewShoppingCartController() *ShoppingCartController {
@@ -75,3 +78,70 @@ func (c *ShoppingCartController) Sub(ctx *gin.Context) {
 	}
 	response.Success(ctx, constant.MsgDeleteSuccess, nil)
 }
+
+// BestCoupon 预览购物车可用的最优优惠券
+func (c *ShoppingCartController) BestCoupon(ctx *gin.Context) {
+	preview, err := c.couponService.Best(ctx)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, preview)
+}
+
+// Preview 购物车结算预览，按收货地址计算配送费和起送金额
+func (c *ShoppingCartController) Preview(ctx *gin.Context) {
+	addressBookID, err := strconv.Atoi(ctx.DefaultQuery("addressBookId", "0"))
+	if err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+	preview, err := c.shoppingCartService.Preview(ctx, addressBookID)
+	if err != nil {
+		logger.Error(constant.MsgCartPreviewFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, preview)
+}
+
+// UpdateNumber 修改购物车中一行的数量
+func (c *ShoppingCartController) UpdateNumber(ctx *gin.Context) {
+	var numberDTO dto.CartNumberDTO
+	if err := ctx.ShouldBindJSON(&numberDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	if err := c.shoppingCartService.UpdateNumber(ctx, &numberDTO); err != nil {
+		logger.Error(constant.MsgCartUpdateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgCartUpdateSuccess, nil)
+}
+
+// Summary 购物车汇总，按商品当前价格计算
+func (c *ShoppingCartController) Summary(ctx *gin.Context) {
+	summary, err := c.shoppingCartService.Summary(ctx)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, summary)
+}
+
+// Validate 下单前校验购物车
+func (c *ShoppingCartController) Validate(ctx *gin.Context) {
+	summary, err := c.shoppingCartService.Validate(ctx)
+	if err != nil {
+		logger.Error(constant.MsgCartValidateFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, summary)
+}
@@ -12,7 +12,8 @@ import (
 
 // WeChatUserController 微信用户接口
 type WeChatUserController struct {
-	userService service.UserService
+	userService  service.UserService
+	tokenService service.TokenService
 }
 
 func NewWeChatUserController() *WeChatUserController {
@@ -39,5 +40,28 @@ func (c *WeChatUserController) Login(ctx *gin.Context) {
 
 // Logout 实现用户退出
 func (c *WeChatUserController) Logout(ctx *gin.Context) {
+	if err := c.tokenService.Logout(ctx, constant.AudienceUser); err != nil {
+		logger.Error(constant.MsgLogoutFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
 	response.Success(ctx, constant.MsgUserLogoutSuccess, nil)
 }
+
+// Refresh 刷新令牌
+func (c *WeChatUserController) Refresh(ctx *gin.Context) {
+	var refreshDTO dto.RefreshTokenDTO
+	if err := ctx.ShouldBindJSON(&refreshDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	tokenVO, err := c.tokenService.Refresh(ctx, constant.AudienceUser, &refreshDTO)
+	if err != nil {
+		logger.Error(constant.MsgJWTParseFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgRefreshTokenSuccess, tokenVO)
+}
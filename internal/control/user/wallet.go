@@ -0,0 +1,67 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/internal/service"
+	"takeout/model/dto"
+)
+
+// WalletController 用户余额接口
+type WalletController struct {
+	walletService service.WalletService
+}
+
+func NewWalletController() *WalletController {
+	return &WalletController{}
+}
+
+// Info 查询余额
+func (c *WalletController) Info(ctx *gin.Context) {
+	wallet, err := c.walletService.Info(ctx)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, wallet)
+}
+
+// Page 分页查询余额流水
+func (c *WalletController) Page(ctx *gin.Context) {
+	var queryDTO dto.WalletPageQueryDTO
+	if err := ctx.ShouldBindQuery(&queryDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	page, err := c.walletService.Page(ctx, &queryDTO)
+	if err != nil {
+		logger.Error(constant.MsgQueryFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgQuerySuccess, page)
+}
+
+// Recharge 余额充值
+func (c *WalletController) Recharge(ctx *gin.Context) {
+	var rechargeDTO dto.WalletRechargeDTO
+	if err := ctx.ShouldBindJSON(&rechargeDTO); err != nil {
+		logger.Error(constant.MsgBadRequest, zap.Error(err))
+		response.BadRequest(ctx, constant.MsgBadRequest)
+		return
+	}
+
+	recharge, err := c.walletService.Recharge(ctx, &rechargeDTO)
+	if err != nil {
+		logger.Error(constant.MsgRechargeFail, zap.Error(err))
+		response.ErrorResponse(ctx, err)
+		return
+	}
+	response.Success(ctx, constant.MsgSuccess, recharge)
+}
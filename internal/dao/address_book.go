@@ -43,6 +43,12 @@ func (d *AddressBookDAO) Update(db *gorm.DB, address *entity.AddressBook) error
 	return db.Table("address_book").Where("id = ?", address.ID).Updates(address).Error
 }
 
+// UpdateLocation 更新地址坐标，坐标为 0 表示需要重新解析
+func (d *AddressBookDAO) UpdateLocation(db *gorm.DB, id int, lat, lng float64) error {
+	return db.Model(&entity.AddressBook{}).Where("id = ?", id).
+		UpdateColumns(map[string]any{"lat": lat, "lng": lng}).Error
+}
+
 // SetNonDefault 将指定用户的所有地址设置为非默认
 func (d *AddressBookDAO) SetNonDefault(db *gorm.DB, userID int) error {
 	return db.Model(&entity.AddressBook{}).Where("user_id = ?", userID).Update("is_default", constant.NonDefaultAddress).Error
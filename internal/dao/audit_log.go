@@ -0,0 +1,53 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+	"takeout/model/dto"
+	"takeout/model/entity"
+	"takeout/model/vo"
+)
+
+// AuditLogDAO 审计日志数据访问对象，只提供追加和查询
+type AuditLogDAO struct{}
+
+// Create 批量写入审计日志
+func (dao *AuditLogDAO) Create(db *gorm.DB, logs []*entity.AuditLog) error {
+	return db.Create(logs).Error
+}
+
+// PageQuery 分页查询审计日志，按时间倒序
+func (dao *AuditLogDAO) PageQuery(db *gorm.DB, queryDTO *dto.AuditPageQueryDTO) (int64, []*vo.AuditLogVO, error) {
+	var (
+		list  []*vo.AuditLogVO
+		total int64
+	)
+	query := db.Table("audit_log a").Joins("left join employee e on e.id = a.operator_id")
+	if queryDTO.OperatorID != 0 {
+		query = query.Where("a.operator_id = ?", queryDTO.OperatorID)
+	}
+	if queryDTO.EntityType != "" {
+		query = query.Where("a.entity_type = ?", queryDTO.EntityType)
+	}
+	if queryDTO.EntityID != 0 {
+		query = query.Where("a.entity_id = ?", queryDTO.EntityID)
+	}
+	if queryDTO.Action != "" {
+		query = query.Where("a.action = ?", queryDTO.Action)
+	}
+	if queryDTO.BeginTime != "" {
+		query = query.Where("a.create_time >= ?", queryDTO.BeginTime)
+	}
+	if queryDTO.EndTime != "" {
+		query = query.Where("a.create_time <= ?", queryDTO.EndTime)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+	offset := (queryDTO.Page - 1) * queryDTO.PageSize
+	err := query.Select("a.*, e.name as operator_name").
+		Order("a.create_time desc, a.id desc").Offset(offset).Limit(queryDTO.PageSize).Scan(&list).Error
+	if err != nil {
+		return 0, nil, err
+	}
+	return total, list, nil
+}
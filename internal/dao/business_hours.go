@@ -0,0 +1,59 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+	"takeout/model/entity"
+)
+
+// BusinessHoursDAO 每周营业时间数据访问对象
+type BusinessHoursDAO struct{}
+
+// List 查询每周营业时间
+func (dao *BusinessHoursDAO) List(db *gorm.DB) ([]*entity.BusinessHours, error) {
+	var list []*entity.BusinessHours
+	result := db.Model(&entity.BusinessHours{}).Order("weekday, open_time").Find(&list)
+	return list, result.Error
+}
+
+// Replace 整体替换每周营业时间，需要在事务中调用
+func (dao *BusinessHoursDAO) Replace(db *gorm.DB, list []*entity.BusinessHours) error {
+	if err := db.Where("1 = 1").Delete(&entity.BusinessHours{}).Error; err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return db.Create(&list).Error
+}
+
+// SpecialDayDAO 特殊营业日数据访问对象
+type SpecialDayDAO struct{}
+
+// ListBetween 查询日期范围内的特殊营业日，日期格式 2006-01-02，包含两端
+func (dao *SpecialDayDAO) ListBetween(db *gorm.DB, from, to string) ([]*entity.SpecialDay, error) {
+	var list []*entity.SpecialDay
+	result := db.Model(&entity.SpecialDay{}).Where("date >= ? and date <= ?", from, to).
+		Order("date, open_time").Find(&list)
+	return list, result.Error
+}
+
+// ListFrom 查询某天及以后的特殊营业日
+func (dao *SpecialDayDAO) ListFrom(db *gorm.DB, from string) ([]*entity.SpecialDay, error) {
+	var list []*entity.SpecialDay
+	result := db.Model(&entity.SpecialDay{}).Where("date >= ?", from).Order("date, open_time").Find(&list)
+	return list, result.Error
+}
+
+// ReplaceDate 替换某天的营业安排，需要在事务中调用
+func (dao *SpecialDayDAO) ReplaceDate(db *gorm.DB, date string, list []*entity.SpecialDay) error {
+	if _, err := dao.DeleteDate(db, date); err != nil {
+		return err
+	}
+	return db.Create(&list).Error
+}
+
+// DeleteDate 删除某天的营业安排，恢复按每周营业时间营业
+func (dao *SpecialDayDAO) DeleteDate(db *gorm.DB, date string) (int64, error) {
+	result := db.Where("date = ?", date).Delete(&entity.SpecialDay{})
+	return result.RowsAffected, result.Error
+}
@@ -51,6 +51,13 @@ func (dao *CategoryDAO) UpdateStatus(id, status int) error {
 	return result.Error
 }
 
+// GetByID 根据ID查询分类
+func (dao *CategoryDAO) GetByID(id int) (*entity.Category, error) {
+	var category entity.Category
+	result := global.DB.Where("id = ?", id).First(&category)
+	return &category, result.Error
+}
+
 func (dao *CategoryDAO) PageQuery(name string, typeId, page, pageSize int) ([]entity.Category, int64, error) {
 	var categories []entity.Category
 	var total int64
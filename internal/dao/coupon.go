@@ -0,0 +1,205 @@
+package dao
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/utils"
+	"takeout/model/entity"
+	"takeout/model/vo"
+	"time"
+)
+
+// CouponTemplateDAO 优惠券模板数据访问对象
+type CouponTemplateDAO struct{}
+
+// Create 新增优惠券模板
+func (dao *CouponTemplateDAO) Create(ctx *gin.Context, db *gorm.DB, template *entity.CouponTemplate) error {
+	return utils.AutoFill(dao.create)(ctx, db, template, constant.Create)
+}
+
+func (dao *CouponTemplateDAO) create(_ *gin.Context, db *gorm.DB, template any, _ string) error {
+	t, ok := template.(*entity.CouponTemplate)
+	if !ok {
+		return errs.New(constant.CodeInternalError, constant.MsgTypeConversionFail)
+	}
+	return db.Model(&entity.CouponTemplate{}).Create(t).Error
+}
+
+// Update 更新优惠券模板，零值字段也会更新，领取数量和状态不在这里修改
+func (dao *CouponTemplateDAO) Update(ctx *gin.Context, db *gorm.DB, template *entity.CouponTemplate) error {
+	return utils.AutoFill(dao.update)(ctx, db, template, constant.Update)
+}
+
+func (dao *CouponTemplateDAO) update(_ *gin.Context, db *gorm.DB, template any, _ string) error {
+	t, ok := template.(*entity.CouponTemplate)
+	if !ok {
+		return errs.New(constant.CodeInternalError, constant.MsgTypeConversionFail)
+	}
+	return db.Model(&entity.CouponTemplate{}).Where("id = ?", t.ID).
+		Select("*").Omit("id", "claimed_count", "status", "create_time", "create_user").
+		Updates(t).Error
+}
+
+// GetByID 根据ID查询优惠券模板
+func (dao *CouponTemplateDAO) GetByID(db *gorm.DB, id int) (*entity.CouponTemplate, error) {
+	var template entity.CouponTemplate
+	result := db.Model(&entity.CouponTemplate{}).Where("id = ?", id).First(&template)
+	return &template, result.Error
+}
+
+// ListByIDs 根据ID列表查询优惠券模板
+func (dao *CouponTemplateDAO) ListByIDs(db *gorm.DB, ids []int) ([]*entity.CouponTemplate, error) {
+	var list []*entity.CouponTemplate
+	result := db.Model(&entity.CouponTemplate{}).Where("id in ?", ids).Find(&list)
+	return list, result.Error
+}
+
+// PageQuery 分页查询优惠券模板
+func (dao *CouponTemplateDAO) PageQuery(db *gorm.DB, name string, status int, page, pageSize int) (int64, []*entity.CouponTemplate, error) {
+	var (
+		list  []*entity.CouponTemplate
+		total int64
+	)
+	query := db.Model(&entity.CouponTemplate{})
+	if name != "" {
+		query = query.Where("name like ?", "%"+name+"%")
+	}
+	if status == constant.CouponEnable || status == constant.CouponDisable {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+	offset := (page - 1) * pageSize
+	if err := query.Order("create_time desc").Offset(offset).Limit(pageSize).Find(&list).Error; err != nil {
+		return 0, nil, err
+	}
+	return total, list, nil
+}
+
+// UpdateStatus 启用或停用优惠券模板
+func (dao *CouponTemplateDAO) UpdateStatus(db *gorm.DB, id, status int) (int64, error) {
+	result := db.Model(&entity.CouponTemplate{}).Where("id = ?", id).UpdateColumn("status", status)
+	return result.RowsAffected, result.Error
+}
+
+// CountUndeletable 统计所给ID中启用中或已被领取的模板数量
+func (dao *CouponTemplateDAO) CountUndeletable(db *gorm.DB, ids []int) (int64, error) {
+	var count int64
+	result := db.Model(&entity.CouponTemplate{}).
+		Where("id in ? and (status = ? or claimed_count > 0)", ids, constant.CouponEnable).
+		Count(&count)
+	return count, result.Error
+}
+
+// BatchDelete 批量删除优惠券模板
+func (dao *CouponTemplateDAO) BatchDelete(db *gorm.DB, ids []int) error {
+	return db.Where("id in ?", ids).Delete(&entity.CouponTemplate{}).Error
+}
+
+// ListClaimable 查询当前可以领取的优惠券模板
+func (dao *CouponTemplateDAO) ListClaimable(db *gorm.DB, now time.Time) ([]*entity.CouponTemplate, error) {
+	var list []*entity.CouponTemplate
+	result := db.Model(&entity.CouponTemplate{}).
+		Where("status = ? and valid_from <= ? and valid_to > ?", constant.CouponEnable, now, now).
+		Where("total_count = 0 or claimed_count < total_count").
+		Order("create_time desc").
+		Find(&list)
+	return list, result.Error
+}
+
+// IncrClaimed 领取时增加已领取数量，模板停用、不在活动时间内或已领完时影响行数为 0
+func (dao *CouponTemplateDAO) IncrClaimed(db *gorm.DB, id int, now time.Time) (int64, error) {
+	result := db.Model(&entity.CouponTemplate{}).
+		Where("id = ? and status = ? and valid_from <= ? and valid_to > ?", id, constant.CouponEnable, now, now).
+		Where("total_count = 0 or claimed_count < total_count").
+		UpdateColumn("claimed_count", gorm.Expr("claimed_count + 1"))
+	return result.RowsAffected, result.Error
+}
+
+// CouponScopeDAO 优惠券适用范围数据访问对象
+type CouponScopeDAO struct{}
+
+// BatchInsert 批量插入
+func (dao *CouponScopeDAO) BatchInsert(db *gorm.DB, scopes []*entity.CouponScope) error {
+	return db.Model(&entity.CouponScope{}).Create(&scopes).Error
+}
+
+// DeleteByTemplateIDs 删除模板的适用范围
+func (dao *CouponScopeDAO) DeleteByTemplateIDs(db *gorm.DB, templateIDs []int) error {
+	return db.Where("template_id in ?", templateIDs).Delete(&entity.CouponScope{}).Error
+}
+
+// ListByTemplateIDs 查询模板的适用范围
+func (dao *CouponScopeDAO) ListByTemplateIDs(db *gorm.DB, templateIDs []int) ([]*entity.CouponScope, error) {
+	var list []*entity.CouponScope
+	result := db.Model(&entity.CouponScope{}).Where("template_id in ?", templateIDs).Find(&list)
+	return list, result.Error
+}
+
+// UserCouponDAO 用户优惠券数据访问对象
+type UserCouponDAO struct{}
+
+// Insert 插入用户优惠券
+func (dao *UserCouponDAO) Insert(db *gorm.DB, coupon *entity.UserCoupon) error {
+	return db.Model(&entity.UserCoupon{}).Create(coupon).Error
+}
+
+// CountByUserTemplate 统计用户领取某模板的数量
+func (dao *UserCouponDAO) CountByUserTemplate(db *gorm.DB, userID, templateID int) (int64, error) {
+	var count int64
+	result := db.Model(&entity.UserCoupon{}).Where("user_id = ? and template_id = ?", userID, templateID).Count(&count)
+	return count, result.Error
+}
+
+// ListByUser 按状态查询用户的优惠券，status 为 0 时查询全部
+func (dao *UserCouponDAO) ListByUser(db *gorm.DB, userID, status int, now time.Time) ([]*vo.UserCouponVO, error) {
+	var list []*vo.UserCouponVO
+	query := db.Table("user_coupon uc").
+		Joins("left join coupon_template ct on uc.template_id = ct.id").
+		Select("uc.*, ct.name, ct.type, ct.amount, ct.discount, ct.max_discount, ct.threshold, ct.scope_type").
+		Where("uc.user_id = ?", userID)
+	switch status {
+	case constant.UserCouponUnused:
+		query = query.Where("uc.status = ? and uc.valid_to > ?", constant.UserCouponUnused, now)
+	case constant.UserCouponUsed:
+		query = query.Where("uc.status = ?", constant.UserCouponUsed)
+	case constant.UserCouponExpired:
+		query = query.Where("uc.status = ? and uc.valid_to <= ?", constant.UserCouponUnused, now)
+	}
+	result := query.Order("uc.valid_to asc").Find(&list)
+	return list, result.Error
+}
+
+// ListUsable 查询用户当前可以使用的优惠券
+func (dao *UserCouponDAO) ListUsable(db *gorm.DB, userID int, now time.Time) ([]*entity.UserCoupon, error) {
+	var list []*entity.UserCoupon
+	result := db.Model(&entity.UserCoupon{}).
+		Where("user_id = ? and status = ? and valid_from <= ? and valid_to > ?", userID, constant.UserCouponUnused, now, now).
+		Find(&list)
+	return list, result.Error
+}
+
+// GetByID 根据ID查询用户优惠券
+func (dao *UserCouponDAO) GetByID(db *gorm.DB, id int) (*entity.UserCoupon, error) {
+	var coupon entity.UserCoupon
+	result := db.Model(&entity.UserCoupon{}).Where("id = ?", id).First(&coupon)
+	return &coupon, result.Error
+}
+
+// Use 核销优惠券，优惠券已被使用或已过期时影响行数为 0
+func (dao *UserCouponDAO) Use(db *gorm.DB, id, userID, orderID int, now time.Time) (int64, error) {
+	result := db.Model(&entity.UserCoupon{}).
+		Where("id = ? and user_id = ? and status = ? and valid_from <= ? and valid_to > ?", id, userID, constant.UserCouponUnused, now, now).
+		UpdateColumns(map[string]any{"status": constant.UserCouponUsed, "order_id": orderID, "use_time": now})
+	return result.RowsAffected, result.Error
+}
+
+// ReturnByOrderID 退还订单使用的优惠券
+func (dao *UserCouponDAO) ReturnByOrderID(db *gorm.DB, orderID int) error {
+	return db.Model(&entity.UserCoupon{}).
+		Where("order_id = ? and status = ?", orderID, constant.UserCouponUsed).
+		UpdateColumns(map[string]any{"status": constant.UserCouponUnused, "order_id": 0, "use_time": nil}).Error
+}
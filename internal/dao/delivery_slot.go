@@ -0,0 +1,77 @@
+package dao
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/utils"
+	"takeout/model/entity"
+)
+
+// DeliverySlotDAO 配送时段数据访问对象
+type DeliverySlotDAO struct{}
+
+// Create 新增配送时段
+func (dao *DeliverySlotDAO) Create(ctx *gin.Context, db *gorm.DB, slot *entity.DeliverySlot) error {
+	return utils.AutoFill(dao.create)(ctx, db, slot, constant.Create)
+}
+
+func (dao *DeliverySlotDAO) create(_ *gin.Context, db *gorm.DB, slot any, _ string) error {
+	s, ok := slot.(*entity.DeliverySlot)
+	if !ok {
+		return errs.New(constant.CodeInternalError, constant.MsgTypeConversionFail)
+	}
+	return db.Model(&entity.DeliverySlot{}).Create(s).Error
+}
+
+// Update 修改配送时段的时间和容量
+func (dao *DeliverySlotDAO) Update(ctx *gin.Context, db *gorm.DB, slot *entity.DeliverySlot) error {
+	return utils.AutoFill(dao.update)(ctx, db, slot, constant.Update)
+}
+
+func (dao *DeliverySlotDAO) update(_ *gin.Context, db *gorm.DB, slot any, _ string) error {
+	s, ok := slot.(*entity.DeliverySlot)
+	if !ok {
+		return errs.New(constant.CodeInternalError, constant.MsgTypeConversionFail)
+	}
+	result := db.Model(&entity.DeliverySlot{}).Where("id = ?", s.ID).
+		Select("start_time", "end_time", "capacity", "update_time", "update_user").
+		Updates(s)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// UpdateStatus 启用、停用配送时段
+func (dao *DeliverySlotDAO) UpdateStatus(db *gorm.DB, id, status int) (int64, error) {
+	result := db.Model(&entity.DeliverySlot{}).Where("id = ?", id).UpdateColumn("status", status)
+	return result.RowsAffected, result.Error
+}
+
+// GetByIDForUpdate 查询并锁定配送时段，同一时段的预约串行执行，保证不超过容量
+func (dao *DeliverySlotDAO) GetByIDForUpdate(db *gorm.DB, id int) (*entity.DeliverySlot, error) {
+	var slot entity.DeliverySlot
+	result := db.Model(&entity.DeliverySlot{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).First(&slot)
+	return &slot, result.Error
+}
+
+// List 按开始时间查询配送时段，status 为 InvalidStatus 时查询全部
+func (dao *DeliverySlotDAO) List(db *gorm.DB, status int) ([]*entity.DeliverySlot, error) {
+	var list []*entity.DeliverySlot
+	query := db.Model(&entity.DeliverySlot{})
+	if status != constant.InvalidStatus {
+		query = query.Where("status = ?", status)
+	}
+	result := query.Order("start_time").Find(&list)
+	return list, result.Error
+}
+
+// Delete 删除配送时段，已预约的订单保留送达时间不受影响
+func (dao *DeliverySlotDAO) Delete(db *gorm.DB, id int) (int64, error) {
+	result := db.Where("id = ?", id).Delete(&entity.DeliverySlot{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,68 @@
+package dao
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/utils"
+	"takeout/model/entity"
+)
+
+// DeliveryZoneDAO 配送区域数据访问对象
+type DeliveryZoneDAO struct{}
+
+// Create 新增配送区域
+func (dao *DeliveryZoneDAO) Create(ctx *gin.Context, db *gorm.DB, zone *entity.DeliveryZone) error {
+	return utils.AutoFill(dao.create)(ctx, db, zone, constant.Create)
+}
+
+func (dao *DeliveryZoneDAO) create(_ *gin.Context, db *gorm.DB, zone any, _ string) error {
+	z, ok := zone.(*entity.DeliveryZone)
+	if !ok {
+		return errs.New(constant.CodeInternalError, constant.MsgTypeConversionFail)
+	}
+	return db.Model(&entity.DeliveryZone{}).Create(z).Error
+}
+
+// Update 修改配送区域，零值字段也会更新，状态不在这里修改
+func (dao *DeliveryZoneDAO) Update(ctx *gin.Context, db *gorm.DB, zone *entity.DeliveryZone) error {
+	return utils.AutoFill(dao.update)(ctx, db, zone, constant.Update)
+}
+
+func (dao *DeliveryZoneDAO) update(_ *gin.Context, db *gorm.DB, zone any, _ string) error {
+	z, ok := zone.(*entity.DeliveryZone)
+	if !ok {
+		return errs.New(constant.CodeInternalError, constant.MsgTypeConversionFail)
+	}
+	result := db.Model(&entity.DeliveryZone{}).Where("id = ?", z.ID).
+		Select("*").Omit("id", "status", "create_time", "create_user").
+		Updates(z)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// UpdateStatus 启用、停用配送区域
+func (dao *DeliveryZoneDAO) UpdateStatus(db *gorm.DB, id, status int) (int64, error) {
+	result := db.Model(&entity.DeliveryZone{}).Where("id = ?", id).UpdateColumn("status", status)
+	return result.RowsAffected, result.Error
+}
+
+// List 按匹配顺序查询配送区域，status 为 InvalidStatus 时查询全部
+func (dao *DeliveryZoneDAO) List(db *gorm.DB, status int) ([]*entity.DeliveryZone, error) {
+	var list []*entity.DeliveryZone
+	query := db.Model(&entity.DeliveryZone{})
+	if status != constant.InvalidStatus {
+		query = query.Where("status = ?", status)
+	}
+	result := query.Order("sort, id").Find(&list)
+	return list, result.Error
+}
+
+// Delete 删除配送区域
+func (dao *DeliveryZoneDAO) Delete(db *gorm.DB, id int) (int64, error) {
+	result := db.Where("id = ?", id).Delete(&entity.DeliveryZone{})
+	return result.RowsAffected, result.Error
+}
@@ -74,6 +74,13 @@ func (dao *DishDAO) GetById(id int) (*entity.Dish, error) {
 	return &dish, result.Error
 }
 
+// GetByID 根据 id 查找菜品信息，在调用方的事务中查询
+func (dao *DishDAO) GetByID(db *gorm.DB, id int) (*entity.Dish, error) {
+	var dish entity.Dish
+	result := db.Model(&entity.Dish{}).Where("id = ?", id).First(&dish)
+	return &dish, result.Error
+}
+
 // DeleteByIDsTx 按 ids 批量删除菜品，事务版
 func (dao *DishDAO) DeleteByIDsTx(ids []int, tx *gorm.DB) error {
 	result := tx.Delete(&entity.Dish{}, ids) // 只有 id 是主键的时候才能这样用
@@ -90,13 +97,17 @@ func (dao *DishDAO) updateTx(_ *gin.Context, tx *gorm.DB, dish any, _ string) er
 	if !ok {
 		return errs.New(constant.CodeInternalError, constant.MsgTypeConversionFail)
 	}
-	result := tx.Model(&entity.Dish{}).Where("id = ?", d.ID).Updates(d)
-	return result.Error
+	if err := tx.Model(&entity.Dish{}).Where("id = ?", d.ID).Updates(d).Error; err != nil {
+		return err
+	}
+	// Updates 会跳过空指针，库存需要单独更新，传空时恢复为不限量
+	return tx.Model(&entity.Dish{}).Where("id = ?", d.ID).
+		UpdateColumns(map[string]any{"stock": d.Stock, "daily_stock": d.DailyStock}).Error
 }
 
-// UpdateStatus 更新菜品状态
+// UpdateStatus 更新菜品状态，手动起售停售后不再视为售罄自动停售
 func (dao *DishDAO) UpdateStatus(id int, status int) error {
-	return global.DB.Table("dish").Where("id = ?", id).UpdateColumn("status", status).Error
+	return global.DB.Table("dish").Where("id = ?", id).UpdateColumns(map[string]any{"status": status, "sold_out": false}).Error
 }
 
 // GetByCategoryID 根据分类ID获取菜品列表
@@ -119,3 +130,49 @@ func (dao *DishDAO) GetCount(db *gorm.DB, status int) (int64, error) {
 	err := db.Model(&entity.Dish{}).Where("status = ?", status).Count(&cnt).Error
 	return cnt, err
 }
+
+// ListByIDs 根据ID列表查询菜品
+func (dao *DishDAO) ListByIDs(db *gorm.DB, ids []int) ([]*entity.Dish, error) {
+	var list []*entity.Dish
+	result := db.Model(&entity.Dish{}).Where("id in ?", ids).Find(&list)
+	return list, result.Error
+}
+
+// DeductStock 扣减限量菜品的库存，库存不足时影响行数为 0
+func (dao *DishDAO) DeductStock(db *gorm.DB, id int, number int) (int64, error) {
+	result := db.Model(&entity.Dish{}).
+		Where("id = ? and stock is not null and stock >= ?", id, number).
+		UpdateColumn("stock", gorm.Expr("stock - ?", number))
+	return result.RowsAffected, result.Error
+}
+
+// RestoreStock 归还限量菜品的库存
+func (dao *DishDAO) RestoreStock(db *gorm.DB, id int, number int) error {
+	return db.Model(&entity.Dish{}).
+		Where("id = ? and stock is not null", id).
+		UpdateColumn("stock", gorm.Expr("stock + ?", number)).Error
+}
+
+// DisableSoldOut 将所给ID中已售罄的起售菜品自动停售
+func (dao *DishDAO) DisableSoldOut(db *gorm.DB, ids []int) (int64, error) {
+	result := db.Model(&entity.Dish{}).
+		Where("id in ? and stock = 0 and status = ?", ids, constant.DishEnable).
+		UpdateColumns(map[string]any{"status": constant.DishDisable, "sold_out": true})
+	return result.RowsAffected, result.Error
+}
+
+// EnableRestocked 将因售罄自动停售、现在又有库存的菜品重新起售
+func (dao *DishDAO) EnableRestocked(db *gorm.DB) (int64, error) {
+	result := db.Model(&entity.Dish{}).
+		Where("sold_out = ? and stock > 0", true).
+		UpdateColumns(map[string]any{"status": constant.DishEnable, "sold_out": false})
+	return result.RowsAffected, result.Error
+}
+
+// ResetDailyStock 将设置了每日库存的菜品库存重置
+func (dao *DishDAO) ResetDailyStock(db *gorm.DB) (int64, error) {
+	result := db.Model(&entity.Dish{}).
+		Where("daily_stock is not null").
+		UpdateColumn("stock", gorm.Expr("daily_stock"))
+	return result.RowsAffected, result.Error
+}
@@ -2,7 +2,6 @@ package dao
 
 import (
 	"gorm.io/gorm"
-	"takeout/common/global"
 	"takeout/model/entity"
 )
 
@@ -15,20 +14,38 @@ func (dao *DishFlavorDAO) BatchCreateWithTx(flavors []*entity.DishFlavor, tx *go
 	return result.Error
 }
 
-// DeleteByDishIDsTx 根据 dishIDs 删除关联的口味数据
+// BatchCreateOptionsTx 使用事务批量创建口味选项
+func (dao *DishFlavorDAO) BatchCreateOptionsTx(options []*entity.DishFlavorOption, tx *gorm.DB) error {
+	return tx.Create(&options).Error
+}
+
+// DeleteByDishIDsTx 根据 dishIDs 删除关联的口味及选项数据
 func (dao *DishFlavorDAO) DeleteByDishIDsTx(dishIds []int, tx *gorm.DB) error {
+	if err := tx.Where("dish_id in ?", dishIds).Delete(&entity.DishFlavorOption{}).Error; err != nil {
+		return err
+	}
 	result := tx.Where("dish_id in ?", dishIds).Delete(&entity.DishFlavor{})
 	return result.Error
 }
 
-// GetByDishID 根据菜品ID查询口味数据
-func (dao *DishFlavorDAO) GetByDishID(dishId int) ([]*entity.DishFlavor, error) {
+// GetByDishID 根据菜品ID查询口味数据，不含选项
+func (dao *DishFlavorDAO) GetByDishID(db *gorm.DB, dishId int) ([]*entity.DishFlavor, error) {
 	var flavors []*entity.DishFlavor
-	result := global.DB.Model(&entity.DishFlavor{}).Where("dish_id = ?", dishId).Find(&flavors)
+	result := db.Model(&entity.DishFlavor{}).Where("dish_id = ?", dishId).Order("sort, id").Find(&flavors)
 	return flavors, result.Error
 }
 
-// DeleteByDishIDTx 根据dishID删除口味数据
+// ListOptionsByDishID 根据菜品ID查询全部口味选项
+func (dao *DishFlavorDAO) ListOptionsByDishID(db *gorm.DB, dishId int) ([]*entity.DishFlavorOption, error) {
+	var options []*entity.DishFlavorOption
+	result := db.Model(&entity.DishFlavorOption{}).Where("dish_id = ?", dishId).Order("sort, id").Find(&options)
+	return options, result.Error
+}
+
+// DeleteByDishIDTx 根据dishID删除口味及选项数据
 func (dao *DishFlavorDAO) DeleteByDishIDTx(dishId int, tx *gorm.DB) error {
+	if err := tx.Where("dish_id = ?", dishId).Delete(&entity.DishFlavorOption{}).Error; err != nil {
+		return err
+	}
 	return tx.Where("dish_id = ?", dishId).Delete(&entity.DishFlavor{}).Error
 }
@@ -49,6 +49,14 @@ func (dao *EmployeeDAO) create(_ *gin.Context, _ *gorm.DB, employee any, _ strin
 	return result.Error
 }
 
+// UpdatePassword 更新密码哈希和强制改密标记，mustChange 为 false 也需要写入，不能用结构体更新
+func (dao *EmployeeDAO) UpdatePassword(id int, password string, mustChange bool) error {
+	return global.DB.Model(&entity.Employee{}).Where("id = ?", id).Updates(map[string]any{
+		"password":             password,
+		"must_change_password": mustChange,
+	}).Error
+}
+
 // CheckUsernameExists 检查用户名是否已存在
 func (dao *EmployeeDAO) CheckUsernameExists(username string) (bool, error) {
 	var count int64
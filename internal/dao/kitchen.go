@@ -0,0 +1,137 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/utils"
+	"takeout/model/entity"
+	"takeout/model/wrap"
+)
+
+// KitchenDAO 后厨档口和工单数据访问对象
+type KitchenDAO struct{}
+
+// CreateStation 新增档口
+func (dao *KitchenDAO) CreateStation(ctx *gin.Context, db *gorm.DB, station *entity.KitchenStation) error {
+	return utils.AutoFill(dao.createStation)(ctx, db, station, constant.Create)
+}
+
+func (dao *KitchenDAO) createStation(_ *gin.Context, db *gorm.DB, station any, _ string) error {
+	s, ok := station.(*entity.KitchenStation)
+	if !ok {
+		return errs.New(constant.CodeInternalError, constant.MsgTypeConversionFail)
+	}
+	return db.Model(&entity.KitchenStation{}).Create(s).Error
+}
+
+// UpdateStation 修改档口
+func (dao *KitchenDAO) UpdateStation(ctx *gin.Context, db *gorm.DB, station *entity.KitchenStation) error {
+	return utils.AutoFill(dao.updateStation)(ctx, db, station, constant.Update)
+}
+
+func (dao *KitchenDAO) updateStation(_ *gin.Context, db *gorm.DB, station any, _ string) error {
+	s, ok := station.(*entity.KitchenStation)
+	if !ok {
+		return errs.New(constant.CodeInternalError, constant.MsgTypeConversionFail)
+	}
+	result := db.Model(&entity.KitchenStation{}).Where("id = ?", s.ID).
+		Select("*").Omit("id", "create_time", "create_user").
+		Updates(s)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// DeleteStation 删除档口及其分类
+func (dao *KitchenDAO) DeleteStation(db *gorm.DB, id int) (int64, error) {
+	if err := db.Where("station_id = ?", id).Delete(&entity.KitchenStationCategory{}).Error; err != nil {
+		return 0, err
+	}
+	result := db.Where("id = ?", id).Delete(&entity.KitchenStation{})
+	return result.RowsAffected, result.Error
+}
+
+// ListStations 按排序查询全部档口
+func (dao *KitchenDAO) ListStations(db *gorm.DB) ([]*entity.KitchenStation, error) {
+	var list []*entity.KitchenStation
+	result := db.Model(&entity.KitchenStation{}).Order("sort, id").Find(&list)
+	return list, result.Error
+}
+
+// ReplaceCategories 重新设置档口负责的分类
+func (dao *KitchenDAO) ReplaceCategories(db *gorm.DB, stationID int, categoryIDs []int) error {
+	if err := db.Where("station_id = ?", stationID).Delete(&entity.KitchenStationCategory{}).Error; err != nil {
+		return err
+	}
+	if len(categoryIDs) == 0 {
+		return nil
+	}
+	list := make([]*entity.KitchenStationCategory, 0, len(categoryIDs))
+	for _, categoryID := range categoryIDs {
+		list = append(list, &entity.KitchenStationCategory{StationID: stationID, CategoryID: categoryID})
+	}
+	return db.Create(list).Error
+}
+
+// ListStationCategories 查询全部档口的分类
+func (dao *KitchenDAO) ListStationCategories(db *gorm.DB) ([]*entity.KitchenStationCategory, error) {
+	var list []*entity.KitchenStationCategory
+	result := db.Model(&entity.KitchenStationCategory{}).Find(&list)
+	return list, result.Error
+}
+
+// BatchCreateTickets 批量新增工单
+func (dao *KitchenDAO) BatchCreateTickets(db *gorm.DB, tickets []*entity.KitchenTicket) error {
+	return db.Create(tickets).Error
+}
+
+// DeleteTicketsByOrderID 删除订单的全部工单
+func (dao *KitchenDAO) DeleteTicketsByOrderID(db *gorm.DB, orderID int) (int64, error) {
+	result := db.Where("order_id = ?", orderID).Delete(&entity.KitchenTicket{})
+	return result.RowsAffected, result.Error
+}
+
+// GetTicket 根据ID查询工单
+func (dao *KitchenDAO) GetTicket(db *gorm.DB, id int) (*entity.KitchenTicket, error) {
+	var ticket entity.KitchenTicket
+	err := db.Where("id = ?", id).First(&ticket).Error
+	return &ticket, err
+}
+
+// UpdateTicketStatus 工单从 from 状态流转到 to 状态，返回影响行数，并发修改时只有一方能成功
+func (dao *KitchenDAO) UpdateTicketStatus(db *gorm.DB, id, from, to int) (int64, error) {
+	updates := map[string]any{"status": to}
+	now := wrap.LocalTime(time.Now())
+	switch to {
+	case constant.TicketCooking:
+		updates["start_time"] = now
+	case constant.TicketReady:
+		updates["ready_time"] = now
+	}
+	result := db.Model(&entity.KitchenTicket{}).Where("id = ? and status = ?", id, from).Updates(updates)
+	return result.RowsAffected, result.Error
+}
+
+// ListTickets 按创建顺序查询工单，stationID 为 InvalidStatus 时查询全部档口
+func (dao *KitchenDAO) ListTickets(db *gorm.DB, stationID int, status []int) ([]*entity.KitchenTicket, error) {
+	var list []*entity.KitchenTicket
+	query := db.Model(&entity.KitchenTicket{}).Where("status in ?", status)
+	if stationID != constant.InvalidStatus {
+		query = query.Where("station_id = ?", stationID)
+	}
+	result := query.Order("id").Find(&list)
+	return list, result.Error
+}
+
+// CountUnready 统计订单未出餐的工单数量
+func (dao *KitchenDAO) CountUnready(db *gorm.DB, orderID int) (int64, error) {
+	var cnt int64
+	result := db.Model(&entity.KitchenTicket{}).
+		Where("order_id = ? and status <> ?", orderID, constant.TicketReady).Count(&cnt)
+	return cnt, result.Error
+}
@@ -29,6 +29,18 @@ func (d *OrderDAO) Update(db *gorm.DB, order *entity.Order) error {
 	return db.Model(&entity.Order{}).Where("id = ?", order.ID).Updates(order).Error
 }
 
+// UpdateStatus 条件更新订单状态，只有当前状态仍为 from 时才会更新，返回影响行数
+func (d *OrderDAO) UpdateStatus(db *gorm.DB, from int, order *entity.Order) (int64, error) {
+	result := db.Model(&entity.Order{}).Where("id = ? and status = ?", order.ID, from).Updates(order)
+	return result.RowsAffected, result.Error
+}
+
+// MarkPaid 把未支付的订单标记为已支付，不修改订单状态，返回影响行数，为 0 表示已处理过
+func (d *OrderDAO) MarkPaid(db *gorm.DB, order *entity.Order) (int64, error) {
+	result := db.Model(&entity.Order{}).Where("id = ? and pay_status = ?", order.ID, constant.UnPaid).Updates(order)
+	return result.RowsAffected, result.Error
+}
+
 // Page 分页查询
 func (d *OrderDAO) Page(db *gorm.DB, queryDTO *dto.OrderPageQueryDTO) (int64, []*entity.Order, error) {
 	var (
@@ -55,6 +67,12 @@ func (d *OrderDAO) Page(db *gorm.DB, queryDTO *dto.OrderPageQueryDTO) (int64, []
 	if queryDTO.EndTime != "" {
 		query = query.Where("order_time <= ?", queryDTO.EndTime)
 	}
+	switch queryDTO.Held {
+	case constant.OrderReleased:
+		query = query.Where("not (held = ? and status = ?)", constant.OrderHeld, constant.ToBeConfirmed)
+	case constant.OrderHeld:
+		query = query.Where("held = ? and status = ?", constant.OrderHeld, constant.ToBeConfirmed)
+	}
 
 	if err = query.Order("order_time desc").Error; err != nil {
 		return 0, nil, err
@@ -77,10 +95,11 @@ func (d *OrderDAO) GetByID(db *gorm.DB, id int) (*entity.Order, error) {
 	return &order, result.Error
 }
 
-// CountStatus 统计某种状态的订单数量
+// CountStatus 统计某种状态的订单数量，不包含未进入后厨队列的预约单
 func (d *OrderDAO) CountStatus(db *gorm.DB, status int) (int64, error) {
 	var cnt int64
-	res := db.Model(&entity.Order{}).Where("status = ?", status).Count(&cnt)
+	res := db.Model(&entity.Order{}).Where("status = ?", status).
+		Where("not (held = ? and status = ?)", constant.OrderHeld, constant.ToBeConfirmed).Count(&cnt)
 	return cnt, res.Error
 }
 
@@ -98,6 +117,14 @@ func (d *OrderDAO) GetAmount(db *gorm.DB, begin *time.Time, end *time.Time) (dec
 	return amount, res.Error
 }
 
+// GetUserSpend 统计用户在某个时间之后完成的订单金额，用于计算会员等级
+func (d *OrderDAO) GetUserSpend(db *gorm.DB, userID int, since time.Time) (decimal.Decimal, error) {
+	var amount decimal.Decimal
+	res := db.Table("orders").Select("ifnull(sum(amount), 0)").
+		Where("user_id = ? and status = ? and delivery_time >= ?", userID, constant.Completed, since).Scan(&amount)
+	return amount, res.Error
+}
+
 // GetCount 统计订单数量
 func (d *OrderDAO) GetCount(db *gorm.DB, begin *time.Time, end *time.Time, status int) (int64, error) {
 	var cnt int64
@@ -125,3 +152,72 @@ func (d *OrderDAO) GetSalesTop10(db *gorm.DB, begin *time.Time, end *time.Time)
 		Group("od.name").Order("number desc").Offset(0).Limit(10).Find(&list).Error
 	return list, err
 }
+
+// AssignRider 为已接单且骑手未取餐的订单指派骑手
+func (d *OrderDAO) AssignRider(db *gorm.DB, id, riderID int) (int64, error) {
+	result := db.Model(&entity.Order{}).
+		Where("id = ? and status = ? and rider_status in ?", id, constant.Confirmed,
+			[]int{constant.RiderUnassigned, constant.RiderAssigned, constant.RiderAccepted}).
+		UpdateColumns(map[string]any{"rider_id": riderID, "rider_status": constant.RiderAssigned})
+	return result.RowsAffected, result.Error
+}
+
+// UpdateRiderStatus 骑手推进配送进度，只能操作指派给自己且处于 from 状态的订单
+func (d *OrderDAO) UpdateRiderStatus(db *gorm.DB, id, riderID, from, to int) (int64, error) {
+	result := db.Model(&entity.Order{}).
+		Where("id = ? and rider_id = ? and rider_status = ?", id, riderID, from).
+		UpdateColumn("rider_status", to)
+	return result.RowsAffected, result.Error
+}
+
+// ReleaseRider 骑手拒绝指派的订单，订单回到未指派状态
+func (d *OrderDAO) ReleaseRider(db *gorm.DB, id, riderID int) (int64, error) {
+	result := db.Model(&entity.Order{}).
+		Where("id = ? and rider_id = ? and rider_status = ?", id, riderID, constant.RiderAssigned).
+		UpdateColumns(map[string]any{"rider_id": 0, "rider_status": constant.RiderUnassigned})
+	return result.RowsAffected, result.Error
+}
+
+// ListByRider 查询指派给骑手的订单，riderStatus 为空时查询全部
+func (d *OrderDAO) ListByRider(db *gorm.DB, riderID int, riderStatus []int) ([]*entity.Order, error) {
+	var orders []*entity.Order
+	query := db.Model(&entity.Order{}).Where("rider_id = ?", riderID)
+	if len(riderStatus) > 0 {
+		query = query.Where("rider_status in ?", riderStatus)
+	}
+	result := query.Order("order_time desc").Find(&orders)
+	return orders, result.Error
+}
+
+// CountBySlot 统计某天某个配送时段已预约的订单数量，已取消的订单不占用容量
+func (d *OrderDAO) CountBySlot(db *gorm.DB, slotID int, begin, end time.Time) (int64, error) {
+	var cnt int64
+	res := db.Model(&entity.Order{}).
+		Where("delivery_slot_id = ? and estimated_delivery_time >= ? and estimated_delivery_time < ? and status <> ?",
+			slotID, begin, end, constant.Cancelled).
+		Count(&cnt)
+	return cnt, res.Error
+}
+
+// ListDueHeld 查询已到出餐提前期、仍对后厨隐藏的待接单预约单
+func (d *OrderDAO) ListDueHeld(db *gorm.DB, now time.Time) ([]*entity.Order, error) {
+	var list []*entity.Order
+	result := db.Model(&entity.Order{}).
+		Where("held = ? and status = ? and release_time <= ?", constant.OrderHeld, constant.ToBeConfirmed, now).
+		Find(&list)
+	return list, result.Error
+}
+
+// Release 预约单进入后厨队列，返回影响行数，为 0 表示已被处理过
+func (d *OrderDAO) Release(db *gorm.DB, id int) (int64, error) {
+	result := db.Model(&entity.Order{}).Where("id = ? and held = ?", id, constant.OrderHeld).
+		UpdateColumn("held", constant.OrderReleased)
+	return result.RowsAffected, result.Error
+}
+
+// MarkMealReady 标记订单已全部出餐，返回影响行数，为 0 表示已标记过
+func (d *OrderDAO) MarkMealReady(db *gorm.DB, id int) (int64, error) {
+	result := db.Model(&entity.Order{}).Where("id = ? and meal_ready = ?", id, constant.MealPreparing).
+		UpdateColumns(map[string]any{"meal_ready": constant.MealReady, "ready_time": time.Now()})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,20 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+	"takeout/model/entity"
+)
+
+type OrderStatusHistoryDAO struct{}
+
+// Insert 新增一条状态流转记录
+func (d *OrderStatusHistoryDAO) Insert(db *gorm.DB, history *entity.OrderStatusHistory) error {
+	return db.Model(&entity.OrderStatusHistory{}).Create(history).Error
+}
+
+// ListByOrderID 按时间顺序查询订单的状态流转记录
+func (d *OrderStatusHistoryDAO) ListByOrderID(db *gorm.DB, orderID int) ([]*entity.OrderStatusHistory, error) {
+	var list []*entity.OrderStatusHistory
+	result := db.Model(&entity.OrderStatusHistory{}).Where("order_id = ?", orderID).Order("id asc").Find(&list)
+	return list, result.Error
+}
@@ -0,0 +1,52 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"takeout/model/entity"
+)
+
+// PointsDAO 积分数据访问对象
+type PointsDAO struct{}
+
+// GetBalanceForUpdate 锁定用户并查询积分余额，需要在事务中调用
+func (dao *PointsDAO) GetBalanceForUpdate(db *gorm.DB, userID int) (int, error) {
+	var user entity.User
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "points").Where("id = ?", userID).First(&user).Error
+	return user.Points, err
+}
+
+// UpdateBalance 更新积分余额
+func (dao *PointsDAO) UpdateBalance(db *gorm.DB, userID, balance int) error {
+	return db.Model(&entity.User{}).Where("id = ?", userID).UpdateColumn("points", balance).Error
+}
+
+// CreateLog 写入积分流水
+func (dao *PointsDAO) CreateLog(db *gorm.DB, log *entity.PointsLog) error {
+	return db.Create(log).Error
+}
+
+// SumByOrder 统计订单某种类型的积分变动合计
+func (dao *PointsDAO) SumByOrder(db *gorm.DB, orderID, typ int) (int, error) {
+	var sum int
+	err := db.Model(&entity.PointsLog{}).Select("ifnull(sum(`change`), 0)").
+		Where("order_id = ? and type = ?", orderID, typ).Scan(&sum).Error
+	return sum, err
+}
+
+// PageByUser 分页查询用户的积分流水，userID 为 0 时查询全部
+func (dao *PointsDAO) PageByUser(db *gorm.DB, userID, page, pageSize int) (int64, []*entity.PointsLog, error) {
+	var (
+		list  []*entity.PointsLog
+		total int64
+	)
+	query := db.Model(&entity.PointsLog{})
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+	err := query.Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error
+	return total, list, err
+}
@@ -0,0 +1,29 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+	"takeout/model/entity"
+	"time"
+)
+
+type PrintJobDAO struct{}
+
+// BatchInsert 批量新增打印任务
+func (d *PrintJobDAO) BatchInsert(db *gorm.DB, jobs []*entity.PrintJob) error {
+	return db.Model(&entity.PrintJob{}).Create(jobs).Error
+}
+
+// UpdateStatus 条件更新打印任务，只有当前状态在 from 中才会更新，返回影响行数
+func (d *PrintJobDAO) UpdateStatus(db *gorm.DB, from []int, job *entity.PrintJob) (int64, error) {
+	result := db.Model(&entity.PrintJob{}).Where("id = ? and status in ?", job.ID, from).Updates(job)
+	return result.RowsAffected, result.Error
+}
+
+// ListRetry 查询在某个时间之前最后更新、处于给定状态且重试次数未超限的打印任务
+func (d *PrintJobDAO) ListRetry(db *gorm.DB, status []int, t time.Time, maxRetry int) ([]*entity.PrintJob, error) {
+	var list []*entity.PrintJob
+	result := db.Model(&entity.PrintJob{}).
+		Where("status in ? and update_time < ? and retry_count < ?", status, t, maxRetry).
+		Order("id").Find(&list)
+	return list, result.Error
+}
@@ -0,0 +1,65 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+	"takeout/model/dto"
+	"takeout/model/entity"
+	"time"
+)
+
+type RefundDAO struct{}
+
+// Insert 新增退款单
+func (d *RefundDAO) Insert(db *gorm.DB, refund *entity.Refund) error {
+	return db.Model(&entity.Refund{}).Create(refund).Error
+}
+
+// GetByNumber 根据退款单号查询
+func (d *RefundDAO) GetByNumber(db *gorm.DB, number string) (*entity.Refund, error) {
+	var refund entity.Refund
+	result := db.Model(&entity.Refund{}).Where("number = ?", number).First(&refund)
+	return &refund, result.Error
+}
+
+// GetByID 根据ID查询退款单
+func (d *RefundDAO) GetByID(db *gorm.DB, id int) (*entity.Refund, error) {
+	var refund entity.Refund
+	result := db.Model(&entity.Refund{}).Where("id = ?", id).First(&refund)
+	return &refund, result.Error
+}
+
+// Page 分页查询退款单，最近更新的排在前面
+func (d *RefundDAO) Page(db *gorm.DB, queryDTO *dto.RefundPageQueryDTO) (int64, []*entity.Refund, error) {
+	var (
+		total int64
+		list  []*entity.Refund
+	)
+	query := db.Model(&entity.Refund{})
+	if queryDTO.OrderNumber != "" {
+		query = query.Where("order_number like ?", "%"+queryDTO.OrderNumber+"%")
+	}
+	if queryDTO.Status != 0 {
+		query = query.Where("status = ?", queryDTO.Status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+	err := query.Order("update_time desc").Limit(queryDTO.PageSize).
+		Offset((queryDTO.Page - 1) * queryDTO.PageSize).Find(&list).Error
+	return total, list, err
+}
+
+// UpdateStatus 条件更新退款单，只有当前状态在 from 中才会更新，返回影响行数
+func (d *RefundDAO) UpdateStatus(db *gorm.DB, from []int, refund *entity.Refund) (int64, error) {
+	result := db.Model(&entity.Refund{}).Where("id = ? and status in ?", refund.ID, from).Updates(refund)
+	return result.RowsAffected, result.Error
+}
+
+// ListStuck 查询在某个时间之前最后更新、处于给定状态且重试次数未超限的退款单
+func (d *RefundDAO) ListStuck(db *gorm.DB, status []int, t time.Time, maxRetry int) ([]*entity.Refund, error) {
+	var list []*entity.Refund
+	result := db.Model(&entity.Refund{}).
+		Where("status in ? and update_time < ? and retry_count < ?", status, t, maxRetry).
+		Find(&list)
+	return list, result.Error
+}
@@ -0,0 +1,134 @@
+package dao
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/model/dto"
+	"takeout/model/entity"
+	"takeout/model/vo"
+)
+
+// ReviewDAO 订单评价数据访问对象
+type ReviewDAO struct{}
+
+// Create 新增评价及商品评分
+func (dao *ReviewDAO) Create(db *gorm.DB, review *entity.Review, items []*entity.ReviewItem) error {
+	if err := db.Create(review).Error; err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	for _, item := range items {
+		item.ReviewID = review.ID
+	}
+	return db.Create(&items).Error
+}
+
+// GetByID 根据ID查询评价
+func (dao *ReviewDAO) GetByID(db *gorm.DB, id int) (*entity.Review, error) {
+	var review entity.Review
+	err := db.Where("id = ?", id).First(&review).Error
+	return &review, err
+}
+
+// GetByOrderID 查询订单的评价
+func (dao *ReviewDAO) GetByOrderID(db *gorm.DB, orderID int) (*entity.Review, error) {
+	var review entity.Review
+	err := db.Where("order_id = ?", orderID).First(&review).Error
+	return &review, err
+}
+
+// ListItems 查询评价的商品评分
+func (dao *ReviewDAO) ListItems(db *gorm.DB, reviewIDs []int) ([]*entity.ReviewItem, error) {
+	var items []*entity.ReviewItem
+	if len(reviewIDs) == 0 {
+		return items, nil
+	}
+	err := db.Where("review_id in ?", reviewIDs).Order("id").Find(&items).Error
+	return items, err
+}
+
+// PageQuery 分页查询评价，按评价时间倒序
+func (dao *ReviewDAO) PageQuery(db *gorm.DB, queryDTO *dto.ReviewPageQueryDTO) (int64, []*entity.Review, error) {
+	var (
+		list  []*entity.Review
+		total int64
+	)
+	query := db.Model(&entity.Review{})
+	if queryDTO.Rating != 0 {
+		query = query.Where("rating = ?", queryDTO.Rating)
+	}
+	if queryDTO.DishID != 0 {
+		query = query.Where("id in (?)", db.Model(&entity.ReviewItem{}).Select("review_id").Where("dish_id = ?", queryDTO.DishID))
+	}
+	if queryDTO.Status != nil {
+		query = query.Where("status = ?", *queryDTO.Status)
+	}
+	if queryDTO.Replied != nil {
+		if *queryDTO.Replied == 1 {
+			query = query.Where("reply_time is not null")
+		} else {
+			query = query.Where("reply_time is null")
+		}
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+	offset := (queryDTO.Page - 1) * queryDTO.PageSize
+	err := query.Order("create_time desc, id desc").Offset(offset).Limit(queryDTO.PageSize).Find(&list).Error
+	return total, list, err
+}
+
+// Reply 商家回复评价，重复回复会覆盖之前的内容
+func (dao *ReviewDAO) Reply(db *gorm.DB, id int, reply string, empID int) (int64, error) {
+	result := db.Model(&entity.Review{}).Where("id = ?", id).Updates(map[string]any{
+		"reply":      reply,
+		"reply_time": time.Now(),
+		"reply_user": empID,
+	})
+	return result.RowsAffected, result.Error
+}
+
+// UpdateStatus 显示或隐藏评价，商品评分的状态同步修改
+func (dao *ReviewDAO) UpdateStatus(db *gorm.DB, id, status int) (int64, error) {
+	result := db.Model(&entity.Review{}).Where("id = ?", id).Update("status", status)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.RowsAffected, result.Error
+	}
+	err := db.Model(&entity.ReviewItem{}).Where("review_id = ?", id).Update("status", status).Error
+	return result.RowsAffected, err
+}
+
+// DishRatings 统计菜品的平均评分和评分数，只统计显示中的评价
+func (dao *ReviewDAO) DishRatings(db *gorm.DB, dishIDs []int) ([]*vo.DishRatingVO, error) {
+	var list []*vo.DishRatingVO
+	if len(dishIDs) == 0 {
+		return list, nil
+	}
+	err := db.Model(&entity.ReviewItem{}).
+		Select("dish_id, round(avg(rating), 1) as rating, count(*) as review_count").
+		Where("dish_id in ? and status = ?", dishIDs, constant.ReviewShow).
+		Group("dish_id").Scan(&list).Error
+	return list, err
+}
+
+// LowRatedDishes 查询平均分低于阈值的菜品，begin、end 为零值时不限制时间
+func (dao *ReviewDAO) LowRatedDishes(db *gorm.DB, threshold float64, minCount int, begin, end time.Time) ([]*vo.DishRatingVO, error) {
+	var list []*vo.DishRatingVO
+	query := db.Table("review_item ri").Joins("left join dish d on d.id = ri.dish_id").
+		Where("ri.dish_id <> 0 and ri.status = ?", constant.ReviewShow)
+	if !begin.IsZero() {
+		query = query.Where("ri.create_time >= ?", begin)
+	}
+	if !end.IsZero() {
+		query = query.Where("ri.create_time < ?", end)
+	}
+	err := query.Select("ri.dish_id, coalesce(d.name, max(ri.name)) as name, round(avg(ri.rating), 1) as rating, count(*) as review_count").
+		Group("ri.dish_id, d.name").
+		Having("count(*) >= ? and avg(ri.rating) < ?", minCount, threshold).
+		Order("rating, review_count desc").Scan(&list).Error
+	return list, err
+}
@@ -0,0 +1,120 @@
+package dao
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/utils"
+	"takeout/model/entity"
+	"takeout/model/vo"
+	"time"
+)
+
+// RiderDAO 骑手数据访问对象
+type RiderDAO struct{}
+
+// activeRiderStatus 骑手仍在处理中的配送进度
+var activeRiderStatus = []int{constant.RiderAssigned, constant.RiderAccepted, constant.RiderPickedUp}
+
+// Create 新增骑手
+func (dao *RiderDAO) Create(ctx *gin.Context, db *gorm.DB, rider *entity.Rider) error {
+	return utils.AutoFill(dao.create)(ctx, db, rider, constant.Create)
+}
+
+func (dao *RiderDAO) create(_ *gin.Context, db *gorm.DB, rider any, _ string) error {
+	r, ok := rider.(*entity.Rider)
+	if !ok {
+		return errs.New(constant.CodeInternalError, constant.MsgTypeConversionFail)
+	}
+	return db.Model(&entity.Rider{}).Create(r).Error
+}
+
+// Update 更新骑手基本信息
+func (dao *RiderDAO) Update(ctx *gin.Context, db *gorm.DB, rider *entity.Rider) error {
+	return utils.AutoFill(dao.update)(ctx, db, rider, constant.Update)
+}
+
+func (dao *RiderDAO) update(_ *gin.Context, db *gorm.DB, rider any, _ string) error {
+	r, ok := rider.(*entity.Rider)
+	if !ok {
+		return errs.New(constant.CodeInternalError, constant.MsgTypeConversionFail)
+	}
+	return db.Model(&entity.Rider{}).Where("id = ?", r.ID).Updates(r).Error
+}
+
+// GetByID 根据ID查询骑手
+func (dao *RiderDAO) GetByID(db *gorm.DB, id int) (*entity.Rider, error) {
+	var rider entity.Rider
+	result := db.Model(&entity.Rider{}).Where("id = ?", id).First(&rider)
+	return &rider, result.Error
+}
+
+// GetByUsername 根据用户名查询骑手
+func (dao *RiderDAO) GetByUsername(db *gorm.DB, username string) (*entity.Rider, error) {
+	var rider entity.Rider
+	result := db.Model(&entity.Rider{}).Where("username = ?", username).First(&rider)
+	return &rider, result.Error
+}
+
+// PageQuery 分页查询骑手
+func (dao *RiderDAO) PageQuery(db *gorm.DB, name string, status, page, pageSize int) (int64, []*entity.Rider, error) {
+	var (
+		list  []*entity.Rider
+		total int64
+	)
+	query := db.Model(&entity.Rider{})
+	if name != "" {
+		query = query.Where("name like ?", "%"+name+"%")
+	}
+	if status == constant.RiderEnable || status == constant.RiderDisable {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+	offset := (page - 1) * pageSize
+	if err := query.Order("create_time desc").Offset(offset).Limit(pageSize).Find(&list).Error; err != nil {
+		return 0, nil, err
+	}
+	return total, list, nil
+}
+
+// UpdateStatus 启用或禁用骑手，禁用的同时设为休息
+func (dao *RiderDAO) UpdateStatus(db *gorm.DB, id, status int) (int64, error) {
+	updates := map[string]any{"status": status}
+	if status == constant.RiderDisable {
+		updates["work_status"] = constant.RiderOffline
+	}
+	result := db.Model(&entity.Rider{}).Where("id = ?", id).UpdateColumns(updates)
+	return result.RowsAffected, result.Error
+}
+
+// UpdatePassword 更新骑手密码哈希
+func (dao *RiderDAO) UpdatePassword(db *gorm.DB, id int, password string) error {
+	return db.Model(&entity.Rider{}).Where("id = ?", id).UpdateColumn("password", password).Error
+}
+
+// UpdateWorkStatus 骑手切换接单、休息状态
+func (dao *RiderDAO) UpdateWorkStatus(db *gorm.DB, id, workStatus int) error {
+	return db.Model(&entity.Rider{}).Where("id = ?", id).UpdateColumn("work_status", workStatus).Error
+}
+
+// UpdateLocation 更新骑手最近一次上报的位置
+func (dao *RiderDAO) UpdateLocation(db *gorm.DB, id int, latitude, longitude float64, t time.Time) error {
+	return db.Model(&entity.Rider{}).Where("id = ?", id).
+		UpdateColumns(map[string]any{"latitude": latitude, "longitude": longitude, "location_time": t}).Error
+}
+
+// ListOnline 查询接单中的骑手及其进行中的订单数量，按订单数量升序
+func (dao *RiderDAO) ListOnline(db *gorm.DB) ([]*vo.RiderVO, error) {
+	var list []*vo.RiderVO
+	result := db.Table("rider r").
+		Joins("left join orders o on o.rider_id = r.id and o.rider_status in ?", activeRiderStatus).
+		Where("r.status = ? and r.work_status = ?", constant.RiderEnable, constant.RiderOnline).
+		Group("r.id").
+		Select("r.*, count(o.id) as active_orders").
+		Order("active_orders asc, r.id asc").
+		Find(&list)
+	return list, result.Error
+}
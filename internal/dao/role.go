@@ -0,0 +1,99 @@
+package dao
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/utils"
+	"takeout/model/entity"
+)
+
+// RoleDAO 角色数据访问对象
+type RoleDAO struct{}
+
+// Create 新增角色
+func (dao *RoleDAO) Create(ctx *gin.Context, db *gorm.DB, role *entity.Role) error {
+	return utils.AutoFill(dao.create)(ctx, db, role, constant.Create)
+}
+
+func (dao *RoleDAO) create(_ *gin.Context, db *gorm.DB, role any, _ string) error {
+	r, ok := role.(*entity.Role)
+	if !ok {
+		return errs.New(constant.CodeInternalError, constant.MsgTypeConversionFail)
+	}
+	return db.Create(r).Error
+}
+
+// Update 修改角色名称，编码和内置标记不可修改
+func (dao *RoleDAO) Update(ctx *gin.Context, db *gorm.DB, role *entity.Role) error {
+	return utils.AutoFill(dao.update)(ctx, db, role, constant.Update)
+}
+
+func (dao *RoleDAO) update(_ *gin.Context, db *gorm.DB, role any, _ string) error {
+	r, ok := role.(*entity.Role)
+	if !ok {
+		return errs.New(constant.CodeInternalError, constant.MsgTypeConversionFail)
+	}
+	return db.Model(&entity.Role{}).Where("id = ?", r.ID).
+		Select("name", "update_time", "update_user").Updates(r).Error
+}
+
+// GetByID 根据ID查询角色
+func (dao *RoleDAO) GetByID(db *gorm.DB, id int) (*entity.Role, error) {
+	var role entity.Role
+	result := db.Where("id = ?", id).First(&role)
+	return &role, result.Error
+}
+
+// GetByCode 根据编码查询角色
+func (dao *RoleDAO) GetByCode(db *gorm.DB, code string) (*entity.Role, error) {
+	var role entity.Role
+	result := db.Where("code = ?", code).First(&role)
+	return &role, result.Error
+}
+
+// List 查询全部角色
+func (dao *RoleDAO) List(db *gorm.DB) ([]*entity.Role, error) {
+	var list []*entity.Role
+	result := db.Order("id").Find(&list)
+	return list, result.Error
+}
+
+// Delete 删除角色及其权限
+func (dao *RoleDAO) Delete(db *gorm.DB, id int) error {
+	if err := db.Where("role_id = ?", id).Delete(&entity.RolePermission{}).Error; err != nil {
+		return err
+	}
+	return db.Where("id = ?", id).Delete(&entity.Role{}).Error
+}
+
+// ListPermissions 查询角色的权限码
+func (dao *RoleDAO) ListPermissions(db *gorm.DB, roleID int) ([]string, error) {
+	var list []string
+	result := db.Model(&entity.RolePermission{}).Where("role_id = ?", roleID).
+		Order("permission").Pluck("permission", &list)
+	return list, result.Error
+}
+
+// ReplacePermissions 覆盖角色的权限码
+func (dao *RoleDAO) ReplacePermissions(db *gorm.DB, roleID int, permissions []string) error {
+	if err := db.Where("role_id = ?", roleID).Delete(&entity.RolePermission{}).Error; err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+	list := make([]entity.RolePermission, 0, len(permissions))
+	for _, p := range permissions {
+		list = append(list, entity.RolePermission{RoleID: roleID, Permission: p})
+	}
+	return db.Create(&list).Error
+}
+
+// CountEmployees 统计角色下的员工数量
+func (dao *RoleDAO) CountEmployees(db *gorm.DB, roleID int) (int64, error) {
+	var count int64
+	result := db.Model(&entity.Employee{}).Where("role_id = ?", roleID).Count(&count)
+	return count, result.Error
+}
@@ -105,10 +105,17 @@ func (dao *SetmealDAO) UpdateStatus(db *gorm.DB, id int, status int) error {
 	return result.Error
 }
 
-// ListByCategoryID 根据分类ID查询起售的套餐列表
+// ListByCategoryID 根据分类ID查询起售的套餐列表，关联菜品停售（包括售罄）的套餐视为不可售
 func (dao *SetmealDAO) ListByCategoryID(db *gorm.DB, categoryID int) ([]*entity.Setmeal, error) {
 	var setmeals []*entity.Setmeal
-	result := db.Model(&entity.Setmeal{}).Where("category_id = ? and status = ?", categoryID, constant.SetmealEnable).Find(&setmeals)
+	haltDish := db.Table("setmeal_dish sd").
+		Joins("join dish d on sd.dish_id = d.id").
+		Where("sd.setmeal_id = setmeal.id and d.status = ?", constant.DishDisable).
+		Select("1")
+	result := db.Model(&entity.Setmeal{}).
+		Where("category_id = ? and status = ?", categoryID, constant.SetmealEnable).
+		Where("not exists (?)", haltDish).
+		Find(&setmeals)
 	return setmeals, result.Error
 }
 
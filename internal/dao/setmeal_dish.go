@@ -46,3 +46,10 @@ func (dao *SetmealDishDAO) GetDishIdsBySetmealId(db *gorm.DB, setmealId int) ([]
 	result := db.Model(&entity.SetmealDish{}).Where("setmeal_id = ?", setmealId).Pluck("dish_id", &dishIds)
 	return dishIds, result.Error
 }
+
+// ListBySetmealIDs 根据套餐ID列表查找
+func (dao *SetmealDishDAO) ListBySetmealIDs(db *gorm.DB, setmealIDs []int) ([]*entity.SetmealDish, error) {
+	var setmealDishes []*entity.SetmealDish
+	result := db.Model(&entity.SetmealDish{}).Where("setmeal_id in ?", setmealIDs).Find(&setmealDishes)
+	return setmealDishes, result.Error
+}
@@ -2,9 +2,13 @@ package dao
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"takeout/common/constant"
 	"takeout/common/global"
+	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 // ShopDAO 店铺数据访问对象
@@ -25,3 +29,32 @@ func (dao *ShopDAO) GetStatus() (int, error) {
 	}
 	return strconv.Atoi(statusStr)
 }
+
+// SetPause 忙碌暂停接单，到期后键自动过期即恢复营业
+func (dao *ShopDAO) SetPause(until time.Time) error {
+	ctx := context.Background()
+	return global.Redis.Set(ctx, constant.RedisKeyShopPause, strconv.FormatInt(until.Unix(), 10), time.Until(until)).Err()
+}
+
+// GetPause 获取暂停接单的恢复时间，未暂停时返回零值
+func (dao *ShopDAO) GetPause() (time.Time, error) {
+	ctx := context.Background()
+	value, err := global.Redis.Get(ctx, constant.RedisKeyShopPause).Result()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	ts, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(ts, 0), nil
+}
+
+// ClearPause 提前恢复接单
+func (dao *ShopDAO) ClearPause() error {
+	ctx := context.Background()
+	return global.Redis.Del(ctx, constant.RedisKeyShopPause).Err()
+}
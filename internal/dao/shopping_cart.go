@@ -52,3 +52,15 @@ func (d *ShoppingCartDAO) DeleteByID(db *gorm.DB, cart *entity.ShoppingCart) err
 func (d *ShoppingCartDAO) BatchInsert(db *gorm.DB, list []*entity.ShoppingCart) error {
 	return db.Model(&entity.ShoppingCart{}).Create(&list).Error
 }
+
+// GetByID 根据ID查询购物车中的一行
+func (d *ShoppingCartDAO) GetByID(db *gorm.DB, id int) (*entity.ShoppingCart, error) {
+	var cart entity.ShoppingCart
+	result := db.Model(&entity.ShoppingCart{}).Where("id = ?", id).First(&cart)
+	return &cart, result.Error
+}
+
+// UpdateAmountByID 更新购物车中一行的单价
+func (d *ShoppingCartDAO) UpdateAmountByID(db *gorm.DB, c *entity.ShoppingCart) error {
+	return db.Model(&entity.ShoppingCart{}).Where("id = ?", c.ID).Update("amount", c.Amount).Error
+}
@@ -0,0 +1,43 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/model/entity"
+)
+
+// StockDAO 库存流水数据访问对象
+type StockDAO struct{}
+
+// BatchInsert 批量写入库存流水
+func (dao *StockDAO) BatchInsert(db *gorm.DB, logs []*entity.StockLog) error {
+	return db.Create(logs).Error
+}
+
+// ListDeducted 查询订单尚未归还的库存流水，按菜品ID排序
+func (dao *StockDAO) ListDeducted(db *gorm.DB, orderID int) ([]*entity.StockLog, error) {
+	var list []*entity.StockLog
+	err := db.Where("order_id = ? and status = ?", orderID, constant.StockDeducted).Order("dish_id").Find(&list).Error
+	return list, err
+}
+
+// MarkRestored 将库存流水标记为已归还，返回影响行数，为 0 表示已归还或库存已被重置
+func (dao *StockDAO) MarkRestored(db *gorm.DB, id int) (int64, error) {
+	result := db.Model(&entity.StockLog{}).Where("id = ? and status = ?", id, constant.StockDeducted).
+		UpdateColumn("status", constant.StockRestored)
+	return result.RowsAffected, result.Error
+}
+
+// ExpireByDish 库存被重置后，之前扣减的流水不再归还
+func (dao *StockDAO) ExpireByDish(db *gorm.DB, dishID int) error {
+	return db.Model(&entity.StockLog{}).Where("dish_id = ? and status = ?", dishID, constant.StockDeducted).
+		UpdateColumn("status", constant.StockExpired).Error
+}
+
+// ExpireDaily 每日重置库存后，设置了每日库存的菜品之前扣减的流水不再归还
+func (dao *StockDAO) ExpireDaily(db *gorm.DB) error {
+	return db.Model(&entity.StockLog{}).
+		Where("status = ? and dish_id in (?)", constant.StockDeducted,
+			db.Session(&gorm.Session{NewDB: true}).Model(&entity.Dish{}).Select("id").Where("daily_stock is not null")).
+		UpdateColumn("status", constant.StockExpired).Error
+}
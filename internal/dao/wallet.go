@@ -0,0 +1,98 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"takeout/common/constant"
+	"takeout/model/entity"
+	"takeout/model/wrap"
+)
+
+// WalletDAO 储值钱包数据访问对象
+type WalletDAO struct{}
+
+// GetForUpdate 锁定用户的钱包，钱包不存在时先创建，需要在事务中调用
+func (dao *WalletDAO) GetForUpdate(db *gorm.DB, userID int) (*entity.Wallet, error) {
+	// 并发创建时由唯一索引去重
+	err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.Wallet{UserID: userID}).Error
+	if err != nil {
+		return nil, err
+	}
+	var wallet entity.Wallet
+	err = db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&wallet).Error
+	return &wallet, err
+}
+
+// GetBalance 查询用户的余额，没有钱包时为 0
+func (dao *WalletDAO) GetBalance(db *gorm.DB, userID int) (decimal.Decimal, error) {
+	var balance decimal.Decimal
+	err := db.Model(&entity.Wallet{}).Select("balance").Where("user_id = ?", userID).Limit(1).Scan(&balance).Error
+	return balance, err
+}
+
+// UpdateBalance 更新余额
+func (dao *WalletDAO) UpdateBalance(db *gorm.DB, id int, balance decimal.Decimal) error {
+	return db.Model(&entity.Wallet{}).Where("id = ?", id).Update("balance", balance).Error
+}
+
+// CreateLog 写入余额流水
+func (dao *WalletDAO) CreateLog(db *gorm.DB, log *entity.WalletLog) error {
+	return db.Create(log).Error
+}
+
+// SumByOrder 统计订单某种类型的余额变动合计
+func (dao *WalletDAO) SumByOrder(db *gorm.DB, orderID, typ int) (decimal.Decimal, error) {
+	var sum decimal.Decimal
+	err := db.Model(&entity.WalletLog{}).Select("ifnull(sum(amount), 0)").
+		Where("order_id = ? and type = ?", orderID, typ).Scan(&sum).Error
+	return sum, err
+}
+
+// PageByUser 分页查询用户的余额流水，userID 为 0 时查询全部
+func (dao *WalletDAO) PageByUser(db *gorm.DB, userID, page, pageSize int) (int64, []*entity.WalletLog, error) {
+	var (
+		list  []*entity.WalletLog
+		total int64
+	)
+	query := db.Model(&entity.WalletLog{})
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+	err := query.Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error
+	return total, list, err
+}
+
+// CreateRecharge 新增充值单
+func (dao *WalletDAO) CreateRecharge(db *gorm.DB, recharge *entity.WalletRecharge) error {
+	return db.Create(recharge).Error
+}
+
+// GetRechargeByNumber 根据充值单号查询
+func (dao *WalletDAO) GetRechargeByNumber(db *gorm.DB, number string) (*entity.WalletRecharge, error) {
+	var recharge entity.WalletRecharge
+	err := db.Where("number = ?", number).First(&recharge).Error
+	return &recharge, err
+}
+
+// UpdateRechargeStatus 将充值单从 from 中的状态修改为 status，返回受影响的行数，并发回调时只有一方能成功
+func (dao *WalletDAO) UpdateRechargeStatus(db *gorm.DB, id int, from []int, status int) (int64, error) {
+	updates := map[string]any{"status": status}
+	if status == constant.RechargePaid {
+		updates["pay_time"] = wrap.LocalTime(time.Now())
+	}
+	result := db.Model(&entity.WalletRecharge{}).Where("id = ? and status in ?", id, from).Updates(updates)
+	return result.RowsAffected, result.Error
+}
+
+// ListUnpaidRecharge 查询某个时间之前创建的待支付充值单
+func (dao *WalletDAO) ListUnpaidRecharge(db *gorm.DB, t time.Time) ([]*entity.WalletRecharge, error) {
+	var list []*entity.WalletRecharge
+	err := db.Where("status = ? and create_time < ?", constant.RechargeUnpaid, t).Find(&list).Error
+	return list, err
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"takeout/common/constant"
+	"takeout/common/response"
+	"takeout/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Audit 记录后台操作的审计日志，需放在 JwtAdmin 之后
+// 对象ID依次从路径参数 id、查询参数 id/ids、请求体 id/empId 中获取，没有ID视为新增
+// 只有业务处理成功的请求才会记录，幂等重放的请求不重复记录
+func Audit(entityType, action string) gin.HandlerFunc {
+	var auditService service.AuditService
+	return func(ctx *gin.Context) {
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			response.BadRequest(ctx, constant.MsgBadRequest)
+			ctx.Abort()
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		entry := auditService.Begin(ctx, entityType, action, auditIDs(ctx, body))
+		writer := &bodyWriter{ResponseWriter: ctx.Writer, body: &bytes.Buffer{}}
+		ctx.Writer = writer
+		ctx.Next()
+
+		if writer.Header().Get(constant.IdempotentReplayed) != "" || !succeeded(writer) {
+			return
+		}
+		auditService.Commit(ctx, entry, body)
+	}
+}
+
+// auditIDs 解析请求中的对象ID
+func auditIDs(ctx *gin.Context, body []byte) []int {
+	if id, err := strconv.Atoi(ctx.Param("id")); err == nil {
+		return []int{id}
+	}
+	if id, err := strconv.Atoi(ctx.Query("id")); err == nil {
+		return []int{id}
+	}
+	if idsStr := ctx.Query("ids"); idsStr != "" {
+		ids := make([]int, 0)
+		for _, s := range strings.Split(idsStr, ",") {
+			if id, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+	var payload struct {
+		ID    int `json:"id"`
+		EmpID int `json:"empId"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		if payload.ID != 0 {
+			return []int{payload.ID}
+		}
+		if payload.EmpID != 0 {
+			return []int{payload.EmpID}
+		}
+	}
+	return nil
+}
+
+// succeeded 业务错误也可能返回 200，需要检查响应体中的业务状态码
+func succeeded(writer *bodyWriter) bool {
+	if writer.Status() >= http.StatusBadRequest {
+		return false
+	}
+	var resp response.Response
+	if err := json.Unmarshal(writer.body.Bytes(), &resp); err != nil {
+		return false
+	}
+	return resp.Code == constant.CodeSuccess
+}
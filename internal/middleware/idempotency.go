@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	idempotencyProcessing = "processing"
+	idempotencyDone       = "done"
+	idempotencyTTL        = 24 * time.Hour
+	idempotencyLockTTL    = time.Minute // 处理中状态的最长保留时间，防止进程崩溃后键永久卡住
+)
+
+// idempotencyRecord 幂等键在 Redis 中保存的内容
+type idempotencyRecord struct {
+	State  string `json:"state"`
+	Hash   string `json:"hash"`             // 请求指纹，同一个键只能用于同一个请求
+	Status int    `json:"status,omitempty"` // HTTP 状态码
+	Body   string `json:"body,omitempty"`   // 首次处理的响应体
+}
+
+// bodyWriter 记录响应体，便于保存后重放
+type bodyWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Idempotent 基于 Idempotency-Key 请求头的幂等控制，需放在 JWT 认证之后
+// 同一个用户携带相同的键重复请求时，直接返回首次处理的响应；未携带该请求头时不做处理
+func Idempotent() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		idemKey := ctx.GetHeader(constant.IdempotencyKeyHeader)
+		if idemKey == "" {
+			ctx.Next()
+			return
+		}
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			response.BadRequest(ctx, constant.MsgBadRequest)
+			ctx.Abort()
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key := idempotencyKey(ctx, idemKey)
+		sum := sha256.Sum256(append([]byte(ctx.Request.Method+ctx.Request.URL.RequestURI()), body...))
+		hash := hex.EncodeToString(sum[:])
+
+		c := context.Background()
+		lock, _ := json.Marshal(idempotencyRecord{State: idempotencyProcessing, Hash: hash})
+		ok, err := global.Redis.SetNX(c, key, lock, idempotencyLockTTL).Result()
+		if err != nil {
+			logger.Error(constant.MsgCacheError, zap.Error(err))
+			response.ErrorResponse(ctx, errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError))
+			ctx.Abort()
+			return
+		}
+		if !ok {
+			replay(ctx, key, hash)
+			ctx.Abort()
+			return
+		}
+
+		writer := &bodyWriter{ResponseWriter: ctx.Writer, body: &bytes.Buffer{}}
+		ctx.Writer = writer
+		ctx.Next()
+
+		// 服务端错误允许客户端使用相同的键重试
+		if writer.Status() >= http.StatusInternalServerError {
+			global.Redis.Del(c, key)
+			return
+		}
+		record, _ := json.Marshal(idempotencyRecord{
+			State:  idempotencyDone,
+			Hash:   hash,
+			Status: writer.Status(),
+			Body:   writer.body.String(),
+		})
+		if err := global.Redis.Set(c, key, record, idempotencyTTL).Err(); err != nil {
+			logger.Error(constant.MsgCacheError, zap.Error(err))
+		}
+	}
+}
+
+// idempotencyKey 键按调用方与接口隔离，不同用户使用相同的键互不影响
+// JWT 中间件保存的 ID 是字符串；管理端、用户端和骑手端的接口路径不同，ID 相同也不会冲突
+func idempotencyKey(ctx *gin.Context, idemKey string) string {
+	return fmt.Sprintf("%s%s::%s::%s", constant.RedisKeyIdempotency, ctx.GetString(constant.ID), ctx.FullPath(), idemKey)
+}
+
+// replay 处理重复请求：返回已保存的响应，或提示请求仍在处理中
+func replay(ctx *gin.Context, key, hash string) {
+	val, err := global.Redis.Get(context.Background(), key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			// 首次请求恰好处理失败并释放了键
+			response.ErrorResponse(ctx, errs.New(constant.CodeConflict, constant.MsgIdempotencyProcessing))
+			return
+		}
+		logger.Error(constant.MsgCacheError, zap.Error(err))
+		response.ErrorResponse(ctx, errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError))
+		return
+	}
+	var record idempotencyRecord
+	if err := json.Unmarshal(val, &record); err != nil {
+		logger.Error(constant.MsgUnmarshalFail, zap.Error(err))
+		response.ErrorResponse(ctx, errs.Wrap(err, constant.CodeInternalError, constant.MsgUnmarshalFail))
+		return
+	}
+	if record.Hash != hash {
+		response.ErrorResponse(ctx, errs.New(constant.CodeConflict, constant.MsgIdempotencyKeyReused))
+		return
+	}
+	if record.State != idempotencyDone {
+		response.ErrorResponse(ctx, errs.New(constant.CodeConflict, constant.MsgIdempotencyProcessing))
+		return
+	}
+	ctx.Header(constant.IdempotentReplayed, "true")
+	ctx.Data(record.Status, "application/json; charset=utf-8", []byte(record.Body))
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"takeout/common/constant"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestIdempotencyKeyPerUser 不同用户携带相同的 Idempotency-Key 时必须使用不同的记录
+func TestIdempotencyKeyPerUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keyFor := func(userID string) string {
+		var key string
+		engine := gin.New()
+		engine.POST("/user/order/submit", func(ctx *gin.Context) {
+			ctx.Set(constant.ID, userID)
+			key = idempotencyKey(ctx, ctx.GetHeader(constant.IdempotencyKeyHeader))
+		})
+		req := httptest.NewRequest(http.MethodPost, "/user/order/submit", nil)
+		req.Header.Set(constant.IdempotencyKeyHeader, "same-key")
+		engine.ServeHTTP(httptest.NewRecorder(), req)
+		return key
+	}
+
+	a, b := keyFor("1"), keyFor("2")
+	if a == b {
+		t.Fatalf("两个用户得到了相同的幂等键: %s", a)
+	}
+	if a != keyFor("1") {
+		t.Fatalf("同一用户的幂等键不稳定")
+	}
+	want := constant.RedisKeyIdempotency + "1::/user/order/submit::same-key"
+	if a != want {
+		t.Fatalf("幂等键为 %s，期望 %s", a, want)
+	}
+}
@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"go.uber.org/zap"
+	"strings"
 	"takeout/common/constant"
+	"takeout/common/errs"
 	"takeout/common/global"
 	"takeout/common/logger"
 	"takeout/common/response"
@@ -24,7 +26,7 @@ func JwtAdmin() gin.HandlerFunc {
 			return
 		}
 		// jwt 校验
-		id, err := utils.ParseToken(token, constant.EmpID)
+		claims, err := utils.ParseToken(ctx, token, constant.AudienceAdmin)
 		if err != nil {
 			// token 解析失败
 			logger.Error(constant.MsgJWTParseFail, zap.Error(err))
@@ -32,12 +34,34 @@ func JwtAdmin() gin.HandlerFunc {
 			ctx.Abort()
 			return
 		}
-		// 存储当前员工 ID
-		ctx.Set(constant.ID, id)
+		// 使用初始密码登录的员工只能修改密码或退出登录
+		if !passwordChangeAllowed(ctx.FullPath()) {
+			pending, err := global.Redis.Exists(ctx, constant.RedisKeyPasswordReset+claims.Subject).Result()
+			// 无法确认时拒绝访问，避免未修改初始密码的员工绕过限制
+			if err != nil {
+				logger.Error(constant.MsgCacheError, zap.Error(err))
+				response.ErrorResponse(ctx, errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError))
+				ctx.Abort()
+				return
+			}
+			if pending > 0 {
+				response.ErrorResponse(ctx, errs.New(constant.CodeForbidden, constant.MsgPasswordChangeRequired))
+				ctx.Abort()
+				return
+			}
+		}
+		// 存储当前员工 ID 和会话
+		ctx.Set(constant.ID, claims.Subject)
+		ctx.Set(constant.SessionID, claims.SessionID)
 		ctx.Next()
 	}
 }
 
+// passwordChangeAllowed 需要修改初始密码时仍然允许访问的接口
+func passwordChangeAllowed(path string) bool {
+	return strings.HasSuffix(path, "/employee/editPassword") || strings.HasSuffix(path, "/employee/logout")
+}
+
 // JwtUser 用户端认证
 func JwtUser() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -48,14 +72,38 @@ func JwtUser() gin.HandlerFunc {
 			ctx.Abort()
 			return
 		}
-		id, err := utils.ParseToken(token, constant.UserID)
+		claims, err := utils.ParseToken(ctx, token, constant.AudienceUser)
+		if err != nil {
+			logger.Error(constant.MsgJWTParseFail, zap.Error(err))
+			response.ErrorResponse(ctx, err)
+			ctx.Abort()
+			return
+		}
+		ctx.Set(constant.ID, claims.Subject)
+		ctx.Set(constant.SessionID, claims.SessionID)
+		ctx.Next()
+	}
+}
+
+// JwtRider 骑手端认证
+func JwtRider() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token := ctx.GetHeader(global.Config.JWT.RiderTokenName)
+		if token == "" {
+			logger.Error(constant.MsgJWTWithoutToken)
+			response.Unauthorized(ctx, constant.MsgJWTWithoutToken)
+			ctx.Abort()
+			return
+		}
+		claims, err := utils.ParseToken(ctx, token, constant.AudienceRider)
 		if err != nil {
 			logger.Error(constant.MsgJWTParseFail, zap.Error(err))
 			response.ErrorResponse(ctx, err)
 			ctx.Abort()
 			return
 		}
-		ctx.Set(constant.ID, id)
+		ctx.Set(constant.ID, claims.Subject)
+		ctx.Set(constant.SessionID, claims.SessionID)
 		ctx.Next()
 	}
 }
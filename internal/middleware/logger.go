@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"takeout/common/constant"
 	"takeout/common/logger"
 
 	"github.com/gin-gonic/gin"
@@ -24,6 +25,7 @@ func LoggerMiddleware() gin.HandlerFunc {
 			zap.String("method", method),
 			zap.Int("status", statusCode),
 			zap.String("client_ip", c.ClientIP()),
+			zap.String("request_id", c.GetString(constant.RequestID)),
 		)
 	}
 }
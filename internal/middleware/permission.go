@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"go.uber.org/zap"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/common/utils"
+	"takeout/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission 管理端权限校验，需放在 JwtAdmin 之后，拥有其中任一权限即可访问
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	var permissionService service.PermissionService
+	return func(ctx *gin.Context) {
+		empID, err := utils.GetId(ctx)
+		if err != nil {
+			logger.Error(constant.MsgGetAccountInfoFail, zap.Error(err))
+			response.ErrorResponse(ctx, err)
+			ctx.Abort()
+			return
+		}
+		allowed, err := permissionService.HasAny(ctx, empID, permissions...)
+		if err != nil {
+			logger.Error(constant.MsgPermissionQueryFail, zap.Error(err))
+			response.ErrorResponse(ctx, err)
+			ctx.Abort()
+			return
+		}
+		if !allowed {
+			logger.Warn(constant.MsgPermissionDenied, zap.Int("empId", empID), zap.Strings("permissions", permissions))
+			response.ErrorResponse(ctx, errs.New(constant.CodeForbidden, constant.MsgPermissionDenied))
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
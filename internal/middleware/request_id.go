@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"takeout/common/constant"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID 为每个请求分配请求ID，优先使用网关传入的 X-Request-ID，并回写到响应头
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(constant.RequestIDHeader)
+		if requestID == "" || len(requestID) > 64 {
+			b := make([]byte, 8)
+			_, _ = rand.Read(b)
+			requestID = hex.EncodeToString(b)
+		}
+		ctx.Set(constant.RequestID, requestID)
+		ctx.Header(constant.RequestIDHeader, requestID)
+		ctx.Next()
+	}
+}
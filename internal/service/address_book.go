@@ -76,6 +76,11 @@ func (s *AddressBookService) Update(addressDTO *dto.AddressBookDTO) error {
 	if err != nil {
 		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
 	}
+	// 修改地址时坐标随之更新，未传坐标则清空，下单时重新解析
+	err = s.addressBookDAO.UpdateLocation(global.DB, address.ID, addressDTO.Lat, addressDTO.Lng)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
 	return nil
 }
 
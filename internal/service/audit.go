@@ -0,0 +1,183 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/common/logger"
+	"takeout/common/utils"
+	"takeout/internal/dao"
+	"takeout/model/dto"
+	"takeout/model/entity"
+	"takeout/model/vo"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// auditIgnoredFields 不记录到审计日志的字段
+var auditIgnoredFields = []string{"password", "updateTime", "updateUser"}
+
+// AuditEntry 一次待记录的后台操作，由审计中间件在处理请求前创建
+type AuditEntry struct {
+	EntityType string
+	Action     string
+	IDs        []int
+	before     map[int]map[string]any
+}
+
+// AuditService 后台操作审计
+type AuditService struct {
+	auditLogDAO    dao.AuditLogDAO
+	employeeDAO    dao.EmployeeDAO
+	categoryDAO    dao.CategoryDAO
+	orderDAO       dao.OrderDAO
+	shopService    ShopService
+	dishService    DishService
+	setmealService SetmealService
+	roleService    RoleService
+}
+
+// Begin 记录操作前的快照，ids 为空表示新增
+// 店铺状态没有ID，修改自己的密码时请求中也没有员工ID，统一在这里补全
+func (s *AuditService) Begin(ctx *gin.Context, entityType, action string, ids []int) *AuditEntry {
+	if len(ids) == 0 {
+		switch {
+		case entityType == constant.AuditShop:
+			ids = []int{0}
+		case entityType == constant.AuditEmployee && action == constant.AuditPassword:
+			if id, err := utils.GetId(ctx); err == nil {
+				ids = []int{id}
+			}
+		}
+	}
+	entry := &AuditEntry{
+		EntityType: entityType,
+		Action:     action,
+		IDs:        ids,
+		before:     make(map[int]map[string]any, len(ids)),
+	}
+	for _, id := range ids {
+		entry.before[id] = s.snapshot(entityType, id)
+	}
+	return entry
+}
+
+// Commit 操作成功后对比前后快照写入审计日志，新增操作没有ID，记录请求内容
+// 审计失败只记录错误日志，不影响已经完成的业务操作
+func (s *AuditService) Commit(ctx *gin.Context, entry *AuditEntry, requestBody []byte) {
+	operatorID, _ := utils.GetId(ctx)
+	newLog := func(entityID int, before, after map[string]any) *entity.AuditLog {
+		beforeJSON, afterJSON := diffSnapshots(before, after)
+		return &entity.AuditLog{
+			OperatorID: operatorID,
+			Action:     entry.Action,
+			EntityType: entry.EntityType,
+			EntityID:   entityID,
+			Before:     beforeJSON,
+			After:      afterJSON,
+			IP:         ctx.ClientIP(),
+			RequestID:  ctx.GetString(constant.RequestID),
+		}
+	}
+
+	logs := make([]*entity.AuditLog, 0, len(entry.IDs)+1)
+	if len(entry.IDs) == 0 {
+		var body map[string]any
+		_ = json.Unmarshal(requestBody, &body)
+		for _, field := range auditIgnoredFields {
+			delete(body, field)
+		}
+		logs = append(logs, newLog(0, nil, body))
+	}
+	for _, id := range entry.IDs {
+		logs = append(logs, newLog(id, entry.before[id], s.snapshot(entry.EntityType, id)))
+	}
+	if err := s.auditLogDAO.Create(global.DB, logs); err != nil {
+		logger.Error(constant.MsgAuditRecordFail, zap.String("entityType", entry.EntityType),
+			zap.String("action", entry.Action), zap.Ints("ids", entry.IDs), zap.Error(err))
+	}
+}
+
+// PageQuery 分页查询审计日志
+func (s *AuditService) PageQuery(queryDTO *dto.AuditPageQueryDTO) (*vo.PageResult, error) {
+	total, list, err := s.auditLogDAO.PageQuery(global.DB, queryDTO)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgAuditQueryFail)
+	}
+	return &vo.PageResult{Total: total, Records: list}, nil
+}
+
+// snapshot 查询对象当前的状态，对象不存在时返回 nil
+func (s *AuditService) snapshot(entityType string, id int) map[string]any {
+	var (
+		data any
+		err  error
+	)
+	switch entityType {
+	case constant.AuditEmployee:
+		data, err = s.employeeDAO.GetById(id)
+	case constant.AuditRole:
+		data, err = s.roleService.GetByID(id)
+	case constant.AuditCategory:
+		data, err = s.categoryDAO.GetByID(id)
+	case constant.AuditDish:
+		data, err = s.dishService.GetByID(id)
+	case constant.AuditSetmeal:
+		data, err = s.setmealService.GetByID(id)
+	case constant.AuditOrder:
+		data, err = s.orderDAO.GetByID(global.DB, id)
+	case constant.AuditShop:
+		data, err = s.shopService.Settings()
+	}
+	if err != nil || data == nil {
+		return nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil
+	}
+	var result map[string]any
+	if err = json.Unmarshal(raw, &result); err != nil {
+		return nil
+	}
+	for _, field := range auditIgnoredFields {
+		delete(result, field)
+	}
+	return result
+}
+
+// diffSnapshots 只保留前后不同的字段；一方为空时保留另一方的全部字段
+func diffSnapshots(before, after map[string]any) (string, string) {
+	if before != nil && after != nil {
+		changedBefore := make(map[string]any)
+		changedAfter := make(map[string]any)
+		for key, value := range after {
+			if old, ok := before[key]; !ok || !reflect.DeepEqual(old, value) {
+				changedBefore[key] = before[key]
+				changedAfter[key] = value
+			}
+		}
+		for key, value := range before {
+			if _, ok := after[key]; !ok {
+				changedBefore[key] = value
+			}
+		}
+		before, after = changedBefore, changedAfter
+	}
+	return marshalSnapshot(before), marshalSnapshot(after)
+}
+
+func marshalSnapshot(data map[string]any) string {
+	if data == nil {
+		return ""
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		logger.Error(constant.MsgMarshalFail, zap.Error(err))
+		return ""
+	}
+	return string(raw)
+}
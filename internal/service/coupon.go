@@ -0,0 +1,435 @@
+package service
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/common/utils"
+	"takeout/internal/dao"
+	"takeout/model/dto"
+	"takeout/model/entity"
+	"takeout/model/vo"
+	"takeout/model/wrap"
+	"time"
+)
+
+// CouponService 优惠券服务
+type CouponService struct {
+	couponTemplateDAO dao.CouponTemplateDAO
+	couponScopeDAO    dao.CouponScopeDAO
+	userCouponDAO     dao.UserCouponDAO
+	shoppingCartDAO   dao.ShoppingCartDAO
+	priceCalculator   PriceCalculator
+}
+
+// Create 新增优惠券模板，新建的模板为停用状态
+func (s *CouponService) Create(ctx *gin.Context, createDTO *dto.CouponTemplateDTO) error {
+	template, err := s.toTemplate(createDTO)
+	if err != nil {
+		return err
+	}
+	template.Status = constant.CouponDisable
+	return global.DB.Transaction(func(db *gorm.DB) error {
+		if e := s.couponTemplateDAO.Create(ctx, db, template); e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		return s.saveScopes(db, template.ID, createDTO.ScopeIDs)
+	})
+}
+
+// Update 修改优惠券模板，只能修改停用的模板
+func (s *CouponService) Update(ctx *gin.Context, updateDTO *dto.CouponTemplateDTO) error {
+	template, err := s.toTemplate(updateDTO)
+	if err != nil {
+		return err
+	}
+	return global.DB.Transaction(func(db *gorm.DB) error {
+		old, e := s.couponTemplateDAO.GetByID(db, updateDTO.ID)
+		if e != nil {
+			if errors.Is(e, gorm.ErrRecordNotFound) {
+				return errs.New(constant.CodeNotFound, constant.MsgCouponNotFound)
+			}
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if old.Status == constant.CouponEnable {
+			return errs.New(constant.CodeBusinessError, constant.MsgCouponEnabled)
+		}
+		if e = s.couponTemplateDAO.Update(ctx, db, template); e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if e = s.couponScopeDAO.DeleteByTemplateIDs(db, []int{template.ID}); e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		return s.saveScopes(db, template.ID, updateDTO.ScopeIDs)
+	})
+}
+
+// GetByID 查询优惠券模板详情
+func (s *CouponService) GetByID(id int) (*vo.CouponTemplateVO, error) {
+	template, err := s.couponTemplateDAO.GetByID(global.DB, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.New(constant.CodeNotFound, constant.MsgCouponNotFound)
+		}
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	scopes, err := s.couponScopeDAO.ListByTemplateIDs(global.DB, []int{id})
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	templateVO := &vo.CouponTemplateVO{CouponTemplate: *template, ScopeIDs: make([]int, 0, len(scopes))}
+	for _, scope := range scopes {
+		templateVO.ScopeIDs = append(templateVO.ScopeIDs, scope.TargetID)
+	}
+	return templateVO, nil
+}
+
+// PageQuery 分页查询优惠券模板
+func (s *CouponService) PageQuery(queryDTO *dto.CouponPageQueryDTO) (*vo.PageResult, error) {
+	total, list, err := s.couponTemplateDAO.PageQuery(global.DB, queryDTO.Name, queryDTO.Status, queryDTO.Page, queryDTO.PageSize)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgQueryFail)
+	}
+	return &vo.PageResult{
+		Total:   total,
+		Records: list,
+	}, nil
+}
+
+// UpdateStatus 启用或停用优惠券模板，停用后不能再领取，已领取的券不受影响
+func (s *CouponService) UpdateStatus(id, status int) error {
+	rows, err := s.couponTemplateDAO.UpdateStatus(global.DB, id, status)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgUpdateFail)
+	}
+	if rows == 0 {
+		return errs.New(constant.CodeNotFound, constant.MsgCouponNotFound)
+	}
+	return nil
+}
+
+// BatchDelete 批量删除优惠券模板，启用中或已被领取的模板不能删除
+func (s *CouponService) BatchDelete(ids []int) error {
+	if len(ids) == 0 {
+		return errs.New(constant.CodeBusinessError, constant.MsgMissingRequest)
+	}
+	return global.DB.Transaction(func(db *gorm.DB) error {
+		count, err := s.couponTemplateDAO.CountUndeletable(db, ids)
+		if err != nil {
+			return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgQueryFail)
+		}
+		if count > 0 {
+			return errs.New(constant.CodeBusinessError, constant.MsgCouponCannotDelete)
+		}
+		if err = s.couponTemplateDAO.BatchDelete(db, ids); err != nil {
+			return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDeleteFail)
+		}
+		if err = s.couponScopeDAO.DeleteByTemplateIDs(db, ids); err != nil {
+			return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDeleteFail)
+		}
+		return nil
+	})
+}
+
+// Claimable 查询当前可以领取的优惠券
+func (s *CouponService) Claimable() ([]*entity.CouponTemplate, error) {
+	list, err := s.couponTemplateDAO.ListClaimable(global.DB, time.Now())
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgQueryFail)
+	}
+	return list, nil
+}
+
+// Claim 用户领取优惠券
+func (s *CouponService) Claim(ctx *gin.Context, templateID int) error {
+	userID, err := utils.GetId(ctx)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeInternalError, constant.MsgGetIDFail)
+	}
+	now := time.Now()
+	return global.DB.Transaction(func(db *gorm.DB) error {
+		// 先占用发放数量，同一模板的领取会在这一行上串行执行，下面的限领判断不会被并发绕过
+		rows, e := s.couponTemplateDAO.IncrClaimed(db, templateID, now)
+		if e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		template, e := s.couponTemplateDAO.GetByID(db, templateID)
+		if e != nil {
+			if errors.Is(e, gorm.ErrRecordNotFound) {
+				return errs.New(constant.CodeNotFound, constant.MsgCouponNotFound)
+			}
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if rows == 0 {
+			if template.Status == constant.CouponEnable && template.ValidFrom.Time().Before(now) && template.ValidTo.Time().After(now) {
+				return errs.New(constant.CodeBusinessError, constant.MsgCouponSoldOut)
+			}
+			return errs.New(constant.CodeBusinessError, constant.MsgCouponUnavailable)
+		}
+
+		count, e := s.userCouponDAO.CountByUserTemplate(db, userID, templateID)
+		if e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if count >= int64(template.PerUserLimit) {
+			return errs.New(constant.CodeBusinessError, constant.MsgCouponClaimLimit)
+		}
+
+		coupon := &entity.UserCoupon{
+			UserID:     userID,
+			TemplateID: templateID,
+			Status:     constant.UserCouponUnused,
+			ValidFrom:  wrap.LocalTime(now),
+			ValidTo:    template.ValidTo,
+		}
+		if template.ValidDays > 0 {
+			coupon.ValidTo = wrap.LocalTime(now.AddDate(0, 0, template.ValidDays))
+		}
+		if e = s.userCouponDAO.Insert(db, coupon); e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		return nil
+	})
+}
+
+// List 按状态查询当前用户的优惠券
+func (s *CouponService) List(ctx *gin.Context, status int) ([]*vo.UserCouponVO, error) {
+	userID, err := utils.GetId(ctx)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgGetIDFail)
+	}
+	list, err := s.userCouponDAO.ListByUser(global.DB, userID, status, time.Now())
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgQueryFail)
+	}
+	return list, nil
+}
+
+// Best 计算当前购物车可用的最优优惠券，没有可用的券时返回 nil
+func (s *CouponService) Best(ctx *gin.Context) (*vo.CouponPreviewVO, error) {
+	userID, err := utils.GetId(ctx)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgGetIDFail)
+	}
+	cartList, err := s.shoppingCartDAO.List(global.DB, &entity.ShoppingCart{UserID: userID})
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if len(cartList) == 0 {
+		return nil, nil
+	}
+	// 优惠与餐具费无关，预览时不计餐具
+	price, err := s.priceCalculator.Calculate(global.DB, cartList, constant.TablewareByNumber, 0)
+	if err != nil {
+		return nil, err
+	}
+	coupons, err := s.userCouponDAO.ListUsable(global.DB, userID, time.Now())
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if len(coupons) == 0 {
+		return nil, nil
+	}
+	templateIDs := make([]int, 0, len(coupons))
+	for _, coupon := range coupons {
+		templateIDs = append(templateIDs, coupon.TemplateID)
+	}
+	templates, scopes, err := s.templatesWithScopes(global.DB, templateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *vo.CouponPreviewVO
+	for _, coupon := range coupons {
+		template, ok := templates[coupon.TemplateID]
+		if !ok {
+			continue
+		}
+		discount, ok := couponDiscount(template, scopes[template.ID], price)
+		if !ok {
+			continue
+		}
+		if best == nil || discount.GreaterThan(best.DiscountAmount) {
+			best = &vo.CouponPreviewVO{UserCouponID: coupon.ID, Name: template.Name, DiscountAmount: discount}
+		}
+	}
+	return best, nil
+}
+
+// Apply 下单时校验优惠券并从订单金额中扣除优惠，核销在订单创建后通过 Use 完成
+func (s *CouponService) Apply(db *gorm.DB, userID, userCouponID int, price *OrderPrice) error {
+	coupon, err := s.userCouponDAO.GetByID(db, userCouponID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.New(constant.CodeBusinessError, constant.MsgCouponNotFound)
+		}
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if coupon.UserID != userID {
+		return errs.New(constant.CodeBusinessError, constant.MsgCouponNotFound)
+	}
+	now := time.Now()
+	if coupon.Status != constant.UserCouponUnused || coupon.ValidFrom.Time().After(now) || !coupon.ValidTo.Time().After(now) {
+		return errs.New(constant.CodeBusinessError, constant.MsgCouponUnavailable)
+	}
+	templates, scopes, err := s.templatesWithScopes(db, []int{coupon.TemplateID})
+	if err != nil {
+		return err
+	}
+	template, ok := templates[coupon.TemplateID]
+	if !ok {
+		return errs.New(constant.CodeBusinessError, constant.MsgCouponUnavailable)
+	}
+	discount, ok := couponDiscount(template, scopes[template.ID], price)
+	if !ok {
+		return errs.New(constant.CodeBusinessError, constant.MsgCouponNotMatch)
+	}
+	price.ApplyDiscount(discount)
+	return nil
+}
+
+// Use 核销优惠券，需要与创建订单放在同一个事务中
+func (s *CouponService) Use(db *gorm.DB, userID, userCouponID, orderID int) error {
+	rows, err := s.userCouponDAO.Use(db, userCouponID, userID, orderID, time.Now())
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if rows == 0 {
+		return errs.New(constant.CodeBusinessError, constant.MsgCouponUnavailable)
+	}
+	return nil
+}
+
+// Return 订单取消时退还使用的优惠券，已过期的券退还后也不能再使用
+func (s *CouponService) Return(db *gorm.DB, orderID int) error {
+	if err := s.userCouponDAO.ReturnByOrderID(db, orderID); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// 校验模板参数并转换为实体
+func (s *CouponService) toTemplate(templateDTO *dto.CouponTemplateDTO) (*entity.CouponTemplate, error) {
+	paramErr := errs.New(constant.CodeBadRequest, constant.MsgCouponParamError)
+	switch templateDTO.Type {
+	case constant.CouponFixed:
+		if !templateDTO.Amount.IsPositive() {
+			return nil, paramErr
+		}
+	case constant.CouponPercent:
+		if !templateDTO.Discount.IsPositive() || !templateDTO.Discount.LessThan(decimal.NewFromInt(1)) || templateDTO.MaxDiscount.IsNegative() {
+			return nil, paramErr
+		}
+	case constant.CouponThreshold:
+		if !templateDTO.Amount.IsPositive() || templateDTO.Threshold.LessThan(templateDTO.Amount) {
+			return nil, paramErr
+		}
+	case constant.CouponFreePack:
+	default:
+		return nil, paramErr
+	}
+	switch templateDTO.ScopeType {
+	case constant.CouponScopeAll:
+	case constant.CouponScopeCategory, constant.CouponScopeDish:
+		if len(templateDTO.ScopeIDs) == 0 {
+			return nil, paramErr
+		}
+	default:
+		return nil, paramErr
+	}
+	if templateDTO.Threshold.IsNegative() || templateDTO.TotalCount < 0 || templateDTO.ValidDays < 0 ||
+		!templateDTO.ValidTo.Time().After(templateDTO.ValidFrom.Time()) {
+		return nil, paramErr
+	}
+
+	template := &entity.CouponTemplate{}
+	if err := utils.CopyProperties(templateDTO, template); err != nil {
+		return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgCopyPropertiesFail)
+	}
+	if template.PerUserLimit <= 0 {
+		template.PerUserLimit = 1
+	}
+	return template, nil
+}
+
+// 保存模板的适用范围，全部商品可用时不需要保存
+func (s *CouponService) saveScopes(db *gorm.DB, templateID int, scopeIDs []int) error {
+	if len(scopeIDs) == 0 {
+		return nil
+	}
+	scopes := make([]*entity.CouponScope, 0, len(scopeIDs))
+	for _, id := range scopeIDs {
+		scopes = append(scopes, &entity.CouponScope{TemplateID: templateID, TargetID: id})
+	}
+	if err := s.couponScopeDAO.BatchInsert(db, scopes); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// 批量查询模板及其适用范围
+func (s *CouponService) templatesWithScopes(db *gorm.DB, templateIDs []int) (map[int]*entity.CouponTemplate, map[int]map[int]bool, error) {
+	list, err := s.couponTemplateDAO.ListByIDs(db, templateIDs)
+	if err != nil {
+		return nil, nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	scopeList, err := s.couponScopeDAO.ListByTemplateIDs(db, templateIDs)
+	if err != nil {
+		return nil, nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	templates := make(map[int]*entity.CouponTemplate, len(list))
+	for _, template := range list {
+		templates[template.ID] = template
+	}
+	scopes := make(map[int]map[int]bool)
+	for _, scope := range scopeList {
+		if scopes[scope.TemplateID] == nil {
+			scopes[scope.TemplateID] = make(map[int]bool)
+		}
+		scopes[scope.TemplateID][scope.TargetID] = true
+	}
+	return templates, scopes, nil
+}
+
+// couponDiscount 计算优惠券对订单的优惠金额，只按适用范围内的商品计算，不满足使用条件时返回 false
+func couponDiscount(template *entity.CouponTemplate, scope map[int]bool, price *OrderPrice) (decimal.Decimal, bool) {
+	var goods, pack decimal.Decimal
+	matched := false
+	for _, line := range price.Lines {
+		switch template.ScopeType {
+		case constant.CouponScopeCategory:
+			if !scope[line.CategoryID] {
+				continue
+			}
+		case constant.CouponScopeDish:
+			if line.Cart.DishID == 0 || !scope[line.Cart.DishID] {
+				continue
+			}
+		}
+		matched = true
+		goods = goods.Add(line.UnitPrice.Mul(decimal.NewFromInt(int64(line.Cart.Number))))
+		pack = pack.Add(line.PackAmount)
+	}
+	if !matched || goods.LessThan(template.Threshold) {
+		return decimal.Zero, false
+	}
+
+	var discount decimal.Decimal
+	switch template.Type {
+	case constant.CouponFixed, constant.CouponThreshold:
+		discount = decimal.Min(template.Amount, goods)
+	case constant.CouponPercent:
+		discount = goods.Mul(decimal.NewFromInt(1).Sub(template.Discount))
+		if template.MaxDiscount.IsPositive() {
+			discount = decimal.Min(discount, template.MaxDiscount)
+		}
+	case constant.CouponFreePack:
+		discount = pack
+	}
+	discount = discount.Round(2)
+	return discount, discount.IsPositive()
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/internal/dao"
+	"takeout/model/dto"
+	"takeout/model/entity"
+	"takeout/model/vo"
+	"takeout/model/wrap"
+)
+
+// slotClockFormat 配送时段的时间格式
+const slotClockFormat = "15:04"
+
+// DeliverySlotService 配送时段服务
+type DeliverySlotService struct {
+	deliverySlotDAO dao.DeliverySlotDAO
+	orderDAO        dao.OrderDAO
+	shopService     ShopService
+}
+
+// SlotReservation 预约结果
+type SlotReservation struct {
+	SlotID       int
+	DeliveryTime time.Time // 时段开始时间，作为订单的预计送达时间
+	ReleaseTime  time.Time // 进入后厨队列的时间
+}
+
+// Create 新增配送时段，新建的时段为启用状态
+func (s *DeliverySlotService) Create(ctx *gin.Context, slotDTO *dto.DeliverySlotDTO) error {
+	if err := checkSlotClock(slotDTO); err != nil {
+		return err
+	}
+	slot := &entity.DeliverySlot{
+		StartTime: slotDTO.StartTime,
+		EndTime:   slotDTO.EndTime,
+		Capacity:  slotDTO.Capacity,
+		Status:    constant.SlotEnable,
+	}
+	if err := s.deliverySlotDAO.Create(ctx, global.DB, slot); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// Update 修改配送时段，已预约的订单不受影响
+func (s *DeliverySlotService) Update(ctx *gin.Context, slotDTO *dto.DeliverySlotDTO) error {
+	if err := checkSlotClock(slotDTO); err != nil {
+		return err
+	}
+	slot := &entity.DeliverySlot{
+		ID:        slotDTO.ID,
+		StartTime: slotDTO.StartTime,
+		EndTime:   slotDTO.EndTime,
+		Capacity:  slotDTO.Capacity,
+	}
+	if err := s.deliverySlotDAO.Update(ctx, global.DB, slot); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.New(constant.CodeNotFound, constant.MsgSlotNotFound)
+		}
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// UpdateStatus 启用、停用配送时段
+func (s *DeliverySlotService) UpdateStatus(id, status int) error {
+	rows, err := s.deliverySlotDAO.UpdateStatus(global.DB, id, status)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if rows == 0 {
+		return errs.New(constant.CodeNotFound, constant.MsgSlotNotFound)
+	}
+	return nil
+}
+
+// Delete 删除配送时段
+func (s *DeliverySlotService) Delete(id int) error {
+	rows, err := s.deliverySlotDAO.Delete(global.DB, id)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if rows == 0 {
+		return errs.New(constant.CodeNotFound, constant.MsgSlotNotFound)
+	}
+	return nil
+}
+
+// List 商家查询全部配送时段
+func (s *DeliverySlotService) List() ([]*entity.DeliverySlot, error) {
+	list, err := s.deliverySlotDAO.List(global.DB, constant.InvalidStatus)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return list, nil
+}
+
+// Available 用户查询今天和明天的配送时段及剩余容量
+func (s *DeliverySlotService) Available() ([]*vo.AvailableSlotVO, error) {
+	slots, err := s.deliverySlotDAO.List(global.DB, constant.SlotEnable)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	now := time.Now()
+	deadline := now.Add(time.Duration(global.Config.Shop.ScheduleMinAdvance) * time.Minute)
+	result := make([]*vo.AvailableSlotVO, 0, len(slots)*constant.ScheduleDays)
+	for day := 0; day < constant.ScheduleDays; day++ {
+		date := now.AddDate(0, 0, day)
+		for _, slot := range slots {
+			start := slotTime(date, slot.StartTime)
+			booked, e := s.countBooked(global.DB, slot.ID, start)
+			if e != nil {
+				return nil, e
+			}
+			remaining := slot.Capacity - int(booked)
+			if remaining < 0 {
+				remaining = 0
+			}
+			open, e := s.shopService.IsOpenAt(start)
+			if e != nil {
+				return nil, e
+			}
+			result = append(result, &vo.AvailableSlotVO{
+				SlotID:       slot.ID,
+				Date:         start.Format(time.DateOnly),
+				StartTime:    slot.StartTime,
+				EndTime:      slot.EndTime,
+				DeliveryTime: wrap.LocalTime(start),
+				Remaining:    remaining,
+				Available:    open && remaining > 0 && start.After(deadline),
+			})
+		}
+	}
+	return result, nil
+}
+
+// Bookable 今天或明天是否还有可以预约的时段
+func (s *DeliverySlotService) Bookable() (bool, error) {
+	slots, err := s.Available()
+	if err != nil {
+		return false, err
+	}
+	for _, slot := range slots {
+		if slot.Available {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Reserve 下单时校验并占用配送时段，需要在下单事务中调用
+// deliveryTime 是用户选择的时段开始时间，只能是今天或明天、未过预约截止时间且未约满的时段
+func (s *DeliverySlotService) Reserve(db *gorm.DB, slotID int, deliveryTime time.Time) (*SlotReservation, error) {
+	if slotID == 0 || deliveryTime.IsZero() {
+		return nil, errs.New(constant.CodeBusinessError, constant.MsgSlotRequired)
+	}
+	// 锁定时段，同一时段的预约串行执行
+	slot, err := s.deliverySlotDAO.GetByIDForUpdate(db, slotID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.New(constant.CodeBusinessError, constant.MsgSlotUnavailable)
+		}
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if slot.Status != constant.SlotEnable {
+		return nil, errs.New(constant.CodeBusinessError, constant.MsgSlotUnavailable)
+	}
+
+	// 按日期和时分匹配，避免客户端与服务端时区表示不同
+	now := time.Now()
+	var start time.Time
+	for day := 0; day < constant.ScheduleDays; day++ {
+		t := slotTime(now.AddDate(0, 0, day), slot.StartTime)
+		if t.Format(wrap.TimeFormat) == deliveryTime.Format(wrap.TimeFormat) {
+			start = t
+			break
+		}
+	}
+	deadline := now.Add(time.Duration(global.Config.Shop.ScheduleMinAdvance) * time.Minute)
+	if start.IsZero() || !start.After(deadline) {
+		return nil, errs.New(constant.CodeBusinessError, constant.MsgSlotUnavailable)
+	}
+
+	open, err := s.shopService.IsOpenAt(start)
+	if err != nil {
+		return nil, err
+	}
+	if !open {
+		return nil, errs.New(constant.CodeBusinessError, constant.MsgSlotOutsideHours)
+	}
+
+	booked, err := s.countBooked(db, slot.ID, start)
+	if err != nil {
+		return nil, err
+	}
+	if int(booked) >= slot.Capacity {
+		return nil, errs.New(constant.CodeBusinessError, constant.MsgSlotFull)
+	}
+	return &SlotReservation{
+		SlotID:       slot.ID,
+		DeliveryTime: start,
+		ReleaseTime:  start.Add(-time.Duration(global.Config.Shop.ScheduleLeadTime) * time.Minute),
+	}, nil
+}
+
+// countBooked 统计时段在 start 当天已预约的订单数
+func (s *DeliverySlotService) countBooked(db *gorm.DB, slotID int, start time.Time) (int64, error) {
+	begin := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	cnt, err := s.orderDAO.CountBySlot(db, slotID, begin, begin.AddDate(0, 0, 1))
+	if err != nil {
+		return 0, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return cnt, nil
+}
+
+// checkSlotClock 校验时段格式，校验后统一为两位小时
+func checkSlotClock(slotDTO *dto.DeliverySlotDTO) error {
+	start, end, ok := parseClockRange(slotDTO.StartTime, slotDTO.EndTime)
+	if !ok {
+		return errs.New(constant.CodeBadRequest, constant.MsgSlotTimeInvalid)
+	}
+	slotDTO.StartTime, slotDTO.EndTime = start.Format(slotClockFormat), end.Format(slotClockFormat)
+	return nil
+}
+
+// parseClockRange 解析 HH:MM 时间段，开始时间必须早于结束时间，不支持跨天
+func parseClockRange(start, end string) (time.Time, time.Time, bool) {
+	startTime, err := time.Parse(slotClockFormat, start)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	endTime, err := time.Parse(slotClockFormat, end)
+	if err != nil || !startTime.Before(endTime) {
+		return time.Time{}, time.Time{}, false
+	}
+	return startTime, endTime, true
+}
+
+// slotTime 计算某天的时段时间，clock 为已校验的 HH:MM
+func slotTime(date time.Time, clock string) time.Time {
+	t, _ := time.Parse(slotClockFormat, clock)
+	return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), 0, 0, date.Location())
+}
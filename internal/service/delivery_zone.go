@@ -0,0 +1,267 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/geo"
+	"takeout/common/global"
+	"takeout/common/logger"
+	"takeout/internal/dao"
+	"takeout/model/dto"
+	"takeout/model/entity"
+	"takeout/model/vo"
+)
+
+// 店铺坐标未配置时按店铺地址解析一次后缓存
+var (
+	shopPointMu sync.Mutex
+	shopPoint   geo.Point
+)
+
+// DeliveryZoneService 配送区域服务
+type DeliveryZoneService struct {
+	deliveryZoneDAO dao.DeliveryZoneDAO
+	addressBookDAO  dao.AddressBookDAO
+}
+
+// DeliveryQuote 收货地址的配送报价
+type DeliveryQuote struct {
+	ZoneName    string
+	Distance    int // 配送距离，米；未计算时为 0
+	MinAmount   decimal.Decimal
+	DeliveryFee decimal.Decimal
+}
+
+// Create 新增配送区域，新建的区域为停用状态，确认无误后再启用
+func (s *DeliveryZoneService) Create(ctx *gin.Context, zoneDTO *dto.DeliveryZoneDTO) error {
+	zone, err := toDeliveryZone(zoneDTO)
+	if err != nil {
+		return err
+	}
+	zone.Status = constant.ZoneDisable
+	if err = s.deliveryZoneDAO.Create(ctx, global.DB, zone); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// Update 修改配送区域
+func (s *DeliveryZoneService) Update(ctx *gin.Context, zoneDTO *dto.DeliveryZoneDTO) error {
+	zone, err := toDeliveryZone(zoneDTO)
+	if err != nil {
+		return err
+	}
+	if err = s.deliveryZoneDAO.Update(ctx, global.DB, zone); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.New(constant.CodeNotFound, constant.MsgZoneNotFound)
+		}
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// UpdateStatus 启用、停用配送区域
+func (s *DeliveryZoneService) UpdateStatus(id, status int) error {
+	rows, err := s.deliveryZoneDAO.UpdateStatus(global.DB, id, status)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if rows == 0 {
+		return errs.New(constant.CodeNotFound, constant.MsgZoneNotFound)
+	}
+	return nil
+}
+
+// Delete 删除配送区域
+func (s *DeliveryZoneService) Delete(id int) error {
+	rows, err := s.deliveryZoneDAO.Delete(global.DB, id)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if rows == 0 {
+		return errs.New(constant.CodeNotFound, constant.MsgZoneNotFound)
+	}
+	return nil
+}
+
+// List 商家查询全部配送区域
+func (s *DeliveryZoneService) List() ([]*vo.DeliveryZoneVO, error) {
+	zones, err := s.deliveryZoneDAO.List(global.DB, constant.InvalidStatus)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	list := make([]*vo.DeliveryZoneVO, 0, len(zones))
+	for _, zone := range zones {
+		polygon, _ := zonePolygon(zone)
+		list = append(list, &vo.DeliveryZoneVO{
+			ID:          zone.ID,
+			Name:        zone.Name,
+			Type:        zone.Type,
+			Radius:      zone.Radius,
+			Polygon:     polygon,
+			MinAmount:   zone.MinAmount,
+			DeliveryFee: zone.DeliveryFee,
+			Sort:        zone.Sort,
+			Status:      zone.Status,
+			UpdateTime:  zone.UpdateTime,
+		})
+	}
+	return list, nil
+}
+
+// Quote 计算收货地址所在的配送区域及配送费，不在任何启用的区域内时返回超出配送范围
+// 没有启用任何区域时不限制范围，使用配置的配送费
+// 可能调用外部地图接口，不要在持有行锁的事务中调用
+func (s *DeliveryZoneService) Quote(db *gorm.DB, address *entity.AddressBook) (*DeliveryQuote, error) {
+	zones, err := s.deliveryZoneDAO.List(db, constant.ZoneEnable)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if len(zones) == 0 {
+		return &DeliveryQuote{
+			DeliveryFee: decimal.NewFromFloat(global.Config.Shop.DeliveryFee).Round(2),
+		}, nil
+	}
+
+	point, err := s.locate(db, address)
+	if err != nil {
+		return nil, err
+	}
+	// 只有按距离划分的区域才需要计算配送距离
+	distance := -1
+	for _, zone := range zones {
+		if zone.Type == constant.ZoneRadius {
+			if distance, err = deliveryDistance(point); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	for _, zone := range zones {
+		var in bool
+		switch zone.Type {
+		case constant.ZoneRadius:
+			in = distance <= zone.Radius
+		case constant.ZonePolygon:
+			polygon, e := zonePolygon(zone)
+			if e != nil {
+				logger.Error(constant.MsgZoneParamError, zap.Int("zoneID", zone.ID), zap.Error(e))
+				continue
+			}
+			in = geo.InPolygon(point, polygon)
+		}
+		if in {
+			return &DeliveryQuote{
+				ZoneName:    zone.Name,
+				Distance:    max(distance, 0),
+				MinAmount:   zone.MinAmount,
+				DeliveryFee: zone.DeliveryFee.Round(2),
+			}, nil
+		}
+	}
+	return nil, errs.New(constant.CodeBusinessError, constant.MsgOutOfRange)
+}
+
+// locate 获取收货地址坐标，优先使用地址保存的坐标，没有时解析文字地址并回写
+func (s *DeliveryZoneService) locate(db *gorm.DB, address *entity.AddressBook) (geo.Point, error) {
+	point := geo.Point{Lat: address.Lat, Lng: address.Lng}
+	if !point.IsZero() {
+		return point, nil
+	}
+	point, err := geo.GetProvider().Geocode(address.ProvinceName + address.CityName + address.DistrictName + address.Detail)
+	if err != nil {
+		return point, errs.Wrap(err, constant.CodeBusinessError, constant.MsgAddressLocateFail)
+	}
+	if err = s.addressBookDAO.UpdateLocation(db, address.ID, point.Lat, point.Lng); err != nil {
+		return point, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	address.Lat, address.Lng = point.Lat, point.Lng
+	return point, nil
+}
+
+// deliveryDistance 店铺到收货地址的配送距离，路线规划失败时退回直线距离
+func deliveryDistance(to geo.Point) (int, error) {
+	from, err := getShopPoint()
+	if err != nil {
+		return 0, err
+	}
+	distance, err := geo.GetProvider().Distance(from, to)
+	if err != nil {
+		logger.Error("路线规划失败，使用直线距离", zap.Error(err))
+		return int(math.Round(geo.Haversine(from, to))), nil
+	}
+	return distance, nil
+}
+
+// getShopPoint 店铺坐标，优先使用配置，未配置时解析店铺地址
+func getShopPoint() (geo.Point, error) {
+	point := geo.Point{Lat: global.Config.Shop.Lat, Lng: global.Config.Shop.Lng}
+	if !point.IsZero() {
+		return point, nil
+	}
+	shopPointMu.Lock()
+	defer shopPointMu.Unlock()
+	if !shopPoint.IsZero() {
+		return shopPoint, nil
+	}
+	point, err := geo.GetProvider().Geocode(global.Config.Shop.Address)
+	if err != nil {
+		return point, errs.Wrap(err, constant.CodeServerError, constant.MsgShopLocateFail)
+	}
+	shopPoint = point
+	return point, nil
+}
+
+// toDeliveryZone 校验并转换配送区域参数
+func toDeliveryZone(zoneDTO *dto.DeliveryZoneDTO) (*entity.DeliveryZone, error) {
+	if zoneDTO.MinAmount.IsNegative() || zoneDTO.DeliveryFee.IsNegative() {
+		return nil, errs.New(constant.CodeBadRequest, constant.MsgZoneParamError)
+	}
+	zone := &entity.DeliveryZone{
+		ID:          zoneDTO.ID,
+		Name:        zoneDTO.Name,
+		Type:        zoneDTO.Type,
+		MinAmount:   zoneDTO.MinAmount.Round(2),
+		DeliveryFee: zoneDTO.DeliveryFee.Round(2),
+		Sort:        zoneDTO.Sort,
+	}
+	switch zoneDTO.Type {
+	case constant.ZoneRadius:
+		if zoneDTO.Radius <= 0 {
+			return nil, errs.New(constant.CodeBadRequest, constant.MsgZoneParamError)
+		}
+		zone.Radius = zoneDTO.Radius
+	case constant.ZonePolygon:
+		if len(zoneDTO.Polygon) < 3 {
+			return nil, errs.New(constant.CodeBadRequest, constant.MsgZoneParamError)
+		}
+		polygon, err := json.Marshal(zoneDTO.Polygon)
+		if err != nil {
+			return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgZoneParamError)
+		}
+		zone.Polygon = string(polygon)
+	default:
+		return nil, errs.New(constant.CodeBadRequest, constant.MsgZoneParamError)
+	}
+	return zone, nil
+}
+
+// zonePolygon 解析区域保存的多边形顶点
+func zonePolygon(zone *entity.DeliveryZone) ([]geo.Point, error) {
+	if zone.Polygon == "" {
+		return nil, nil
+	}
+	var polygon []geo.Point
+	err := json.Unmarshal([]byte(zone.Polygon), &polygon)
+	return polygon, err
+}
@@ -24,6 +24,9 @@ type DishService struct {
 	dishDAO        dao.DishDAO
 	dishFlavorDAO  dao.DishFlavorDAO
 	setmealDishDAO dao.SetmealDishDAO
+	stockDAO       dao.StockDAO
+	flavorService  FlavorService
+	reviewService  ReviewService
 }
 
 // CreateWithFlavors 创建菜品及其口味（事务操作）
@@ -44,16 +47,9 @@ func (s *DishService) CreateWithFlavors(ctx *gin.Context, createDTO *dto.DishDTO
 			return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgCreateFail)
 		}
 
-		// 如果存在口味信息，批量创建口味
-		if len(createDTO.Flavors) > 0 {
-			// 设置菜品ID
-			for i := range createDTO.Flavors {
-				createDTO.Flavors[i].DishID = dish.ID
-			}
-			// 批量创建口味
-			if err = s.dishFlavorDAO.BatchCreateWithTx(createDTO.Flavors, tx); err != nil {
-				return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgCreateFail)
-			}
+		// 如果存在口味信息，校验后批量创建口味组及选项
+		if err = s.flavorService.Save(tx, dish.ID, createDTO.Flavors); err != nil {
+			return err
 		}
 
 		err = utils.CleanCache(constant.CacheDishKey + strconv.Itoa(createDTO.CategoryID))
@@ -139,9 +135,9 @@ func (s *DishService) GetByID(id int) (*vo.DishVO, error) {
 	}
 
 	// 查询口味数据
-	flavors, err := s.dishFlavorDAO.GetByDishID(id)
+	flavors, err := s.flavorService.Groups(global.DB, id)
 	if err != nil {
-		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgQueryFail)
+		return nil, err
 	}
 	dishVO.Flavors = flavors
 	return dishVO, nil
@@ -155,6 +151,16 @@ func (s *DishService) Update(ctx *gin.Context, dishDTO *dto.DishDTO) error {
 	}
 	// 开启事务
 	return global.DB.Transaction(func(tx *gorm.DB) error {
+		old, err := s.dishDAO.GetByID(tx, dish.ID)
+		if err != nil {
+			return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgQueryFail)
+		}
+		// 手动修改了库存，之前订单扣减的库存在取消时不再归还
+		if !sameStock(old.Stock, dish.Stock) {
+			if err = s.stockDAO.ExpireByDish(tx, dish.ID); err != nil {
+				return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgUpdateFail)
+			}
+		}
 		// 更新菜品基本信息
 		if err := s.dishDAO.UpdateTx(ctx, dish, tx); err != nil {
 			if strings.Contains(err.Error(), constant.MsgKeyDuplicateError) {
@@ -167,19 +173,12 @@ func (s *DishService) Update(ctx *gin.Context, dishDTO *dto.DishDTO) error {
 			return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDeleteFail)
 		}
 		// 新增口味数据
-		// 如果存在口味信息，批量创建口味
-		if len(dishDTO.Flavors) > 0 {
-			// 设置菜品ID
-			for i := range dishDTO.Flavors {
-				dishDTO.Flavors[i].DishID = dish.ID
-			}
-			// 批量创建口味
-			if err := s.dishFlavorDAO.BatchCreateWithTx(dishDTO.Flavors, tx); err != nil {
-				return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgCreateFail)
-			}
+		// 如果存在口味信息，校验后批量创建口味组及选项
+		if err := s.flavorService.Save(tx, dish.ID, dishDTO.Flavors); err != nil {
+			return err
 		}
 		// 修改菜品的话，缓存也是要全部删除，可能涉及多个分类
-		err := utils.CleanCache(constant.CacheDishKey + "*")
+		err = utils.CleanCache(constant.CacheDishKey + "*")
 		if err != nil {
 			return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
 		}
@@ -218,6 +217,9 @@ func (s *DishService) ListByCategoryID(categoryID int) ([]*vo.DishVO, error) {
 			return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgUnmarshalFail)
 		}
 		// logger.Infof("缓存命中，list : %v", list)
+		if err = s.fillRatings(list); err != nil {
+			return nil, err
+		}
 		return list, nil
 	}
 
@@ -233,9 +235,9 @@ func (s *DishService) ListByCategoryID(categoryID int) ([]*vo.DishVO, error) {
 		if err = utils.CopyProperties(dish, &dishVO); err != nil {
 			return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgCopyPropertiesFail)
 		}
-		flavors, e := s.dishFlavorDAO.GetByDishID(dish.ID)
+		flavors, e := s.flavorService.Groups(global.DB, dish.ID)
 		if e != nil {
-			return nil, errs.Wrap(e, constant.CodeDatabaseError, constant.MsgQueryFail)
+			return nil, e
 		}
 		dishVO.Flavors = flavors
 		list = append(list, &dishVO)
@@ -252,5 +254,34 @@ func (s *DishService) ListByCategoryID(categoryID int) ([]*vo.DishVO, error) {
 		return nil, errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
 	}
 
+	if err = s.fillRatings(list); err != nil {
+		return nil, err
+	}
 	return list, nil
 }
+
+// fillRatings 填充菜品评分，评分变化频繁，不放入菜品缓存
+func (s *DishService) fillRatings(list []*vo.DishVO) error {
+	ids := make([]int, 0, len(list))
+	for _, dishVO := range list {
+		ids = append(ids, dishVO.ID)
+	}
+	ratings, err := s.reviewService.DishRatings(ids)
+	if err != nil {
+		return err
+	}
+	for _, dishVO := range list {
+		if rating, ok := ratings[dishVO.ID]; ok {
+			dishVO.Rating, dishVO.ReviewCount = rating.Rating, rating.ReviewCount
+		}
+	}
+	return nil
+}
+
+// sameStock 比较两个库存值，都为空表示都不限量
+func sameStock(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
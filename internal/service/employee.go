@@ -1,67 +1,135 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"strconv"
 	"strings"
 	"takeout/internal/dao"
+	"time"
 
 	"takeout/common/constant"
 	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/common/logger"
 	"takeout/common/utils"
 	"takeout/model/dto"
 	"takeout/model/entity"
 	"takeout/model/vo"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 // EmployeeService 员工服务
 type EmployeeService struct {
-	employeeDAO dao.EmployeeDAO
+	employeeDAO       dao.EmployeeDAO
+	roleDAO           dao.RoleDAO
+	permissionService PermissionService
+	loginGuard        LoginGuardService
 }
 
 // Login 员工登录
-func (s *EmployeeService) Login(loginDTO *dto.EmployeeLoginDTO) (*vo.EmployeeLoginVO, error) {
+// 用户名不存在、密码错误统一返回 MsgLoginFail，失败次数过多时要求验证码并临时锁定
+func (s *EmployeeService) Login(ctx *gin.Context, loginDTO *dto.EmployeeLoginDTO) (*vo.EmployeeLoginVO, error) {
+	ip := ctx.ClientIP()
+	err := s.loginGuard.Check(ctx, constant.AudienceAdmin, loginDTO.Username, ip, loginDTO.CaptchaID, loginDTO.CaptchaCode)
+	if err != nil {
+		return nil, err
+	}
+
 	// 根据用户名查询员工
 	employee, err := s.employeeDAO.GetByUsername(loginDTO.Username)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errs.New(constant.CodeUserNotExist, constant.MsgUserNotExist)
+			// 与密码错误耗时一致，避免枚举用户名
+			utils.DummyVerify(loginDTO.Password)
+			return nil, s.loginFail(ctx, loginDTO.Username, ip)
 		}
 		return nil, errs.Wrap(err, constant.CodeDatabaseError, "查询员工信息失败")
 	}
 
-	// 检查员工状态
+	// 密码比对，兼容旧版 MD5 摘要
+	ok, rehash := utils.VerifyPassword(employee.Password, loginDTO.Password)
+	if !ok {
+		return nil, s.loginFail(ctx, loginDTO.Username, ip)
+	}
+	s.loginGuard.Succeed(ctx, constant.AudienceAdmin, loginDTO.Username)
+
+	// 密码正确后再检查员工状态，避免泄露账号是否存在
 	if employee.Status == 0 {
 		return nil, errs.New(constant.CodeUserDisabled, "账号已禁用")
 	}
 
-	// 密码加密比对
-	md5PasswordStr := utils.Encrypt(loginDTO.Password)
-
-	if employee.Password != md5PasswordStr {
-		return nil, errs.New(constant.CodePasswordError, constant.MsgPasswordIncorrect)
+	// 仍在使用默认密码的历史账号同样要求修改密码
+	mustChange := employee.MustChangePassword || loginDTO.Password == constant.DefaultPassword
+	if rehash || mustChange != employee.MustChangePassword {
+		s.upgradePassword(employee.ID, loginDTO.Password, mustChange)
 	}
 
-	// 生成JWT令牌
-	token, err := utils.GenerateToken(constant.EmpID, strconv.Itoa(employee.ID))
+	// 创建会话并生成JWT令牌
+	tokens, err := utils.IssueTokens(context.Background(), constant.AudienceAdmin, employee.ID)
 	if err != nil {
-		return nil, errs.Wrap(err, constant.CodeServerError, "生成令牌失败")
+		return nil, err
+	}
+
+	// 标记需要修改密码，JwtAdmin 据此拦截其他接口，有效期与会话一致
+	if mustChange {
+		ttl := time.Duration(global.Config.JWT.RefreshTTL) * time.Second
+		key := constant.RedisKeyPasswordReset + strconv.Itoa(employee.ID)
+		if err = global.Redis.Set(context.Background(), key, 1, ttl).Err(); err != nil {
+			return nil, errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+		}
 	}
 
 	// 构建返回数据
 	loginVO := &vo.EmployeeLoginVO{
-		ID:       employee.ID,
-		Username: employee.Username,
-		Name:     employee.Name,
-		Token:    token,
+		ID:                 employee.ID,
+		Username:           employee.Username,
+		Name:               employee.Name,
+		Token:              tokens.AccessToken,
+		RefreshToken:       tokens.RefreshToken,
+		ExpiresIn:          tokens.ExpiresIn,
+		MustChangePassword: mustChange,
 	}
 
 	return loginVO, nil
 }
 
+// loginFail 记录登录失败，返回统一的错误
+func (s *EmployeeService) loginFail(ctx *gin.Context, username, ip string) error {
+	if err := s.loginGuard.Fail(ctx, constant.AudienceAdmin, username, ip); err != nil {
+		logger.Error(constant.MsgCacheError, zap.String("username", username), zap.Error(err))
+	}
+	return errs.New(constant.CodePasswordError, constant.MsgLoginFail)
+}
+
+// Captcha 生成登录验证码
+func (s *EmployeeService) Captcha(ctx *gin.Context) (*vo.CaptchaVO, error) {
+	return s.loginGuard.Captcha(ctx)
+}
+
+// Unlock 解除员工的登录锁定，不能操作权限高于自己的员工
+func (s *EmployeeService) Unlock(ctx *gin.Context, id int) error {
+	employee, err := s.checkOperable(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.loginGuard.Unlock(ctx, constant.AudienceAdmin, employee.Username)
+}
+
+// upgradePassword 登录成功后用当前算法重新生成密码哈希，失败不影响本次登录，下次登录会再次尝试
+func (s *EmployeeService) upgradePassword(id int, password string, mustChange bool) {
+	hashed, err := utils.HashPassword(password)
+	if err == nil {
+		err = s.employeeDAO.UpdatePassword(id, hashed, mustChange)
+	}
+	if err != nil {
+		logger.Error(constant.MsgPasswordUpgradeFail, zap.Int("id", id), zap.Error(err))
+	}
+}
+
 // Create 创建新员工
 func (s *EmployeeService) Create(ctx *gin.Context, createDTO *dto.EmployeeCreateDTO) error {
 	// 检查用户名是否已存在
@@ -73,15 +141,26 @@ func (s *EmployeeService) Create(ctx *gin.Context, createDTO *dto.EmployeeCreate
 		return errs.New(constant.CodeEmployeeCreateFail, "用户名已存在")
 	}
 
-	// 获取当前操作用户ID
-	//id, err := utils.GetId(ctx)
-	//if err != nil {
-	//	return err
-	//}
+	// 未指定角色时默认为收银，指定的角色不能超出当前员工的权限
+	operatorID, err := utils.GetId(ctx)
+	if err != nil {
+		return err
+	}
+	if createDTO.RoleID == 0 {
+		role, e := s.roleDAO.GetByCode(global.DB, constant.RoleCashier)
+		if e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgRoleQueryFail)
+		}
+		createDTO.RoleID = role.ID
+	}
+	if err = s.permissionService.CanGrant(ctx, operatorID, createDTO.RoleID); err != nil {
+		return err
+	}
 
 	// 创建员工实体
 	employee := &entity.Employee{
-		Status: constant.EmployeeStatusEnable, // 默认启用状态
+		Status:             constant.EmployeeStatusEnable, // 默认启用状态
+		MustChangePassword: true,                          // 默认密码首次登录必须修改
 		//CreateUser: id,
 		//UpdateUser: id,
 	}
@@ -92,7 +171,10 @@ func (s *EmployeeService) Create(ctx *gin.Context, createDTO *dto.EmployeeCreate
 	}
 
 	// 设置默认密码并加密
-	employee.Password = utils.Encrypt(constant.DefaultPassword)
+	employee.Password, err = utils.HashPassword(constant.DefaultPassword)
+	if err != nil {
+		return err
+	}
 
 	// 保存到数据库
 	err = s.employeeDAO.Create(ctx, employee)
@@ -130,7 +212,12 @@ func (s *EmployeeService) GetById(id int) (*vo.EmployeeDetailVO, error) {
 }
 
 // UpdateStatusById 更新单个员工状态
-func (s *EmployeeService) UpdateStatusById(status int, id int) error {
+func (s *EmployeeService) UpdateStatusById(ctx *gin.Context, status int, id int) error {
+	// 不能启用、禁用权限高于自己的员工
+	if _, err := s.checkOperable(ctx, id); err != nil {
+		return err
+	}
+
 	// Update employee status directly in one operation
 	err := s.employeeDAO.UpdateStatus(id, status)
 	if err != nil {
@@ -140,11 +227,25 @@ func (s *EmployeeService) UpdateStatusById(status int, id int) error {
 		return errs.Wrap(err, constant.CodeDatabaseError, "更新员工状态失败")
 	}
 
+	// 禁用后立即注销该员工的所有会话
+	if status == constant.EmployeeStatusDisable {
+		return utils.RevokeAccount(context.Background(), constant.AudienceAdmin, id)
+	}
+
 	return nil
 }
 
 // UpdatePassword 修改员工密码
+// 修改自己的密码需要验证原密码；重置他人密码需要 employee:password 权限，重置后对方下次登录必须修改密码
 func (s *EmployeeService) UpdatePassword(ctx *gin.Context, passwordDTO *dto.EmployeePasswordDTO) error {
+	operatorID, err := utils.GetId(ctx)
+	if err != nil {
+		return err
+	}
+	if passwordDTO.EmpId != operatorID {
+		return s.resetPassword(ctx, passwordDTO)
+	}
+
 	// 根据ID查询员工
 	employee, err := s.employeeDAO.GetById(passwordDTO.EmpId)
 	if err != nil {
@@ -155,54 +256,107 @@ func (s *EmployeeService) UpdatePassword(ctx *gin.Context, passwordDTO *dto.Empl
 	}
 
 	// 验证旧密码
-	md5OldPasswordStr := utils.Encrypt(passwordDTO.OldPassword)
-
-	if employee.Password != md5OldPasswordStr {
+	if ok, _ := utils.VerifyPassword(employee.Password, passwordDTO.OldPassword); !ok {
 		return errs.New(constant.CodePasswordError, "原密码错误")
 	}
 
-	// 获取当前操作用户ID
-	//id, err := utils.GetId(ctx)
-	//if err != nil {
-	//	return err
-	//}
+	// 新密码强度校验
+	if passwordDTO.NewPassword == passwordDTO.OldPassword {
+		return errs.New(constant.CodeBadRequest, constant.MsgPasswordNotChanged)
+	}
+	if err = utils.CheckPasswordStrength(passwordDTO.NewPassword); err != nil {
+		return err
+	}
 
 	// 加密新密码
-	md5NewPasswordStr := utils.Encrypt(passwordDTO.NewPassword)
+	hashed, err := utils.HashPassword(passwordDTO.NewPassword)
+	if err != nil {
+		return err
+	}
 
-	// 更新密码
-	employee.Password = md5NewPasswordStr
-	// employee.UpdateUser = id
+	// 保存到数据库，同时清除强制改密标记
+	if err = s.employeeDAO.UpdatePassword(employee.ID, hashed, false); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, "修改密码失败")
+	}
+	key := constant.RedisKeyPasswordReset + strconv.Itoa(employee.ID)
+	if err = global.Redis.Del(ctx, key).Err(); err != nil {
+		return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
 
-	// 保存到数据库
-	err = s.employeeDAO.UpdateById(ctx, employee)
+	return nil
+}
+
+// resetPassword 店长等有权限的员工重置他人密码，对方的会话全部注销
+func (s *EmployeeService) resetPassword(ctx *gin.Context, passwordDTO *dto.EmployeePasswordDTO) error {
+	operatorID, err := utils.GetId(ctx)
 	if err != nil {
-		var myErr *errs.Error
-		if errors.As(err, &myErr) {
-			return myErr
-		}
+		return err
+	}
+	allowed, err := s.permissionService.HasAny(ctx, operatorID, constant.PermEmployeePassword)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errs.New(constant.CodeForbidden, constant.MsgPermissionDenied)
+	}
+	employee, err := s.checkOperable(ctx, passwordDTO.EmpId)
+	if err != nil {
+		return err
+	}
+	if err = utils.CheckPasswordStrength(passwordDTO.NewPassword); err != nil {
+		return err
+	}
+	hashed, err := utils.HashPassword(passwordDTO.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err = s.employeeDAO.UpdatePassword(employee.ID, hashed, true); err != nil {
 		return errs.Wrap(err, constant.CodeDatabaseError, "修改密码失败")
 	}
+	return utils.RevokeAccount(ctx, constant.AudienceAdmin, employee.ID)
+}
 
-	return nil
+// checkOperable 操作其他员工时，对方角色的权限不能超出当前员工
+func (s *EmployeeService) checkOperable(ctx *gin.Context, id int) (*entity.Employee, error) {
+	employee, err := s.employeeDAO.GetById(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.New(constant.CodeUserNotExist, "员工ID不存在: "+strconv.Itoa(id))
+		}
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, "查询员工信息失败")
+	}
+	operatorID, err := utils.GetId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if employee.ID != operatorID && employee.RoleID != 0 {
+		if err = s.permissionService.CanGrant(ctx, operatorID, employee.RoleID); err != nil {
+			return nil, errs.New(constant.CodeForbidden, constant.MsgPermissionDenied)
+		}
+	}
+	return employee, nil
 }
 
 // Update 更新员工信息
 func (s *EmployeeService) Update(ctx *gin.Context, updateDTO *dto.EmployeeUpdateDTO) error {
-	// 根据ID查询员工
-	employee, err := s.employeeDAO.GetById(updateDTO.ID)
+	// 根据ID查询员工，不能修改权限高于自己的员工
+	employee, err := s.checkOperable(ctx, updateDTO.ID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errs.New(constant.CodeUserNotExist, constant.MsgUserNotExist)
+		return err
+	}
+
+	// 修改角色时，新角色同样不能超出自己的权限
+	roleChanged := updateDTO.RoleID != 0 && updateDTO.RoleID != employee.RoleID
+	if roleChanged {
+		operatorID, e := utils.GetId(ctx)
+		if e != nil {
+			return e
+		}
+		if e = s.permissionService.CanGrant(ctx, operatorID, updateDTO.RoleID); e != nil {
+			return e
 		}
-		return errs.Wrap(err, constant.CodeDatabaseError, "查询员工信息失败")
 	}
 
-	// 获取当前操作用户ID
-	//id, err := utils.GetId(ctx)
-	//if err != nil {
-	//	return err
-	//}
 	// 更新员工信息
 	// 使用deepcopier复制DTO到实体
 	err = utils.CopyProperties(updateDTO, employee)
@@ -225,6 +379,10 @@ func (s *EmployeeService) Update(ctx *gin.Context, updateDTO *dto.EmployeeUpdate
 		return errs.Wrap(err, constant.CodeDatabaseError, "更新员工信息失败")
 	}
 
+	if roleChanged {
+		return s.permissionService.Evict(employee.ID)
+	}
+
 	return nil
 }
 
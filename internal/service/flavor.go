@@ -0,0 +1,268 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/internal/dao"
+	"takeout/model/dto"
+	"takeout/model/entity"
+)
+
+// flavorSeparator 口味展示文字中选项之间的分隔符，与旧版客户端一致
+const flavorSeparator = ","
+
+// FlavorService 菜品口味选项服务
+type FlavorService struct {
+	dishFlavorDAO dao.DishFlavorDAO
+}
+
+// FlavorChoice 校验后的口味选择
+type FlavorChoice struct {
+	Text      string                   // 展示文字，按口味组和选项的顺序拼接
+	Selected  []*entity.SelectedFlavor // 已选口味快照
+	Surcharge decimal.Decimal          // 每份加价合计
+}
+
+// Snapshot 已选口味快照的 JSON
+func (c *FlavorChoice) Snapshot() (string, error) {
+	if len(c.Selected) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(c.Selected)
+	if err != nil {
+		return "", errs.Wrap(err, constant.CodeInternalError, constant.MsgMarshalFail)
+	}
+	return string(data), nil
+}
+
+// Groups 查询菜品的口味组及选项
+// 旧数据没有选项记录时按 Value 中的名称生成不加价的选项
+func (s *FlavorService) Groups(db *gorm.DB, dishID int) ([]*entity.DishFlavor, error) {
+	groups, err := s.dishFlavorDAO.GetByDishID(db, dishID)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if len(groups) == 0 {
+		return groups, nil
+	}
+	options, err := s.dishFlavorDAO.ListOptionsByDishID(db, dishID)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	byGroup := make(map[int][]*entity.DishFlavorOption, len(groups))
+	for _, option := range options {
+		byGroup[option.FlavorID] = append(byGroup[option.FlavorID], option)
+	}
+	for _, group := range groups {
+		group.Options = byGroup[group.ID]
+		if len(group.Options) == 0 {
+			group.Options = legacyOptions(group)
+		}
+	}
+	return groups, nil
+}
+
+// Save 校验并保存菜品的口味组，需要在菜品事务中调用，调用前应删除旧数据
+func (s *FlavorService) Save(tx *gorm.DB, dishID int, groups []*entity.DishFlavor) error {
+	if len(groups) == 0 {
+		return nil
+	}
+	groupNames := make(map[string]bool, len(groups))
+	for i, group := range groups {
+		group.ID = 0
+		group.DishID = dishID
+		group.Name = strings.TrimSpace(group.Name)
+		if len(group.Options) == 0 {
+			group.Options = legacyOptions(group)
+		}
+		if group.Name == "" || groupNames[group.Name] || len(group.Options) == 0 ||
+			(group.Multiple != constant.FlavorSingle && group.Multiple != constant.FlavorMultiple) ||
+			(group.Required != constant.FlavorOptional && group.Required != constant.FlavorRequired) {
+			return errs.New(constant.CodeBadRequest, constant.MsgFlavorInvalid)
+		}
+		groupNames[group.Name] = true
+		if group.Sort == 0 {
+			group.Sort = i
+		}
+
+		names := make([]string, 0, len(group.Options))
+		optionNames := make(map[string]bool, len(group.Options))
+		for _, option := range group.Options {
+			option.Name = strings.TrimSpace(option.Name)
+			// 选项名称会拼接到展示文字中，不能包含分隔符
+			if option.Name == "" || optionNames[option.Name] || strings.Contains(option.Name, flavorSeparator) ||
+				option.Surcharge.IsNegative() {
+				return errs.New(constant.CodeBadRequest, constant.MsgFlavorInvalid)
+			}
+			optionNames[option.Name] = true
+			names = append(names, option.Name)
+		}
+		value, err := json.Marshal(names)
+		if err != nil {
+			return errs.Wrap(err, constant.CodeInternalError, constant.MsgMarshalFail)
+		}
+		group.Value = string(value)
+	}
+
+	if err := s.dishFlavorDAO.BatchCreateWithTx(groups, tx); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgCreateFail)
+	}
+	var options []*entity.DishFlavorOption
+	for _, group := range groups {
+		for i, option := range group.Options {
+			option.ID = 0
+			option.FlavorID = group.ID
+			option.DishID = dishID
+			option.Surcharge = option.Surcharge.Round(2)
+			if option.Sort == 0 {
+				option.Sort = i
+			}
+			options = append(options, option)
+		}
+	}
+	if err := s.dishFlavorDAO.BatchCreateOptionsTx(options, tx); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgCreateFail)
+	}
+	return nil
+}
+
+// Resolve 按菜品的口味组校验用户的选择，计算加价
+// choices 为空时按 text 解析旧版客户端传入的逗号分隔选项名称
+func (s *FlavorService) Resolve(groups []*entity.DishFlavor, choices []*dto.FlavorChoiceDTO, text string) (*FlavorChoice, error) {
+	if len(choices) == 0 && strings.TrimSpace(text) != "" {
+		choices = parseFlavorText(groups, text)
+		if choices == nil {
+			return nil, errs.New(constant.CodeBusinessError, constant.MsgFlavorNotFound)
+		}
+	}
+	chosen := make(map[string][]string, len(choices))
+	for _, choice := range choices {
+		if _, ok := chosen[choice.Name]; ok {
+			return nil, errs.New(constant.CodeBadRequest, constant.MsgBadRequest)
+		}
+		chosen[choice.Name] = choice.Options
+	}
+
+	result := &FlavorChoice{}
+	var texts []string
+	for _, group := range groups {
+		names, ok := chosen[group.Name]
+		delete(chosen, group.Name)
+		if !ok || len(names) == 0 {
+			if group.Required == constant.FlavorRequired {
+				return nil, errs.New(constant.CodeBusinessError, constant.MsgFlavorRequired+"："+group.Name)
+			}
+			continue
+		}
+		if group.Multiple != constant.FlavorMultiple && len(names) > 1 {
+			return nil, errs.New(constant.CodeBusinessError, constant.MsgFlavorSingle+"："+group.Name)
+		}
+		picked := make(map[string]bool, len(names))
+		for _, name := range names {
+			if picked[name] {
+				return nil, errs.New(constant.CodeBadRequest, constant.MsgBadRequest)
+			}
+			picked[name] = true
+		}
+		// 按选项定义的顺序输出，同样的选择得到同样的展示文字
+		selected := &entity.SelectedFlavor{Name: group.Name}
+		for _, option := range group.Options {
+			if !picked[option.Name] {
+				continue
+			}
+			delete(picked, option.Name)
+			selected.Options = append(selected.Options, &entity.SelectedOption{Name: option.Name, Surcharge: option.Surcharge})
+			result.Surcharge = result.Surcharge.Add(option.Surcharge)
+			texts = append(texts, option.Name)
+		}
+		if len(picked) > 0 {
+			return nil, errs.New(constant.CodeBusinessError, constant.MsgFlavorNotFound+"："+group.Name)
+		}
+		result.Selected = append(result.Selected, selected)
+	}
+	if len(chosen) > 0 {
+		return nil, errs.New(constant.CodeBusinessError, constant.MsgFlavorNotFound)
+	}
+	result.Text = strings.Join(texts, flavorSeparator)
+	result.Surcharge = result.Surcharge.Round(2)
+	return result, nil
+}
+
+// ResolveCart 按菜品当前的口味组重新校验购物车中保存的口味，口味被修改或删除时返回业务错误
+func (s *FlavorService) ResolveCart(groups []*entity.DishFlavor, cart *entity.ShoppingCart) (*FlavorChoice, error) {
+	var choices []*dto.FlavorChoiceDTO
+	if cart.Flavors != "" {
+		var selected []*entity.SelectedFlavor
+		if err := json.Unmarshal([]byte(cart.Flavors), &selected); err != nil {
+			return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgFlavorUnmarshal)
+		}
+		for _, flavor := range selected {
+			choice := &dto.FlavorChoiceDTO{Name: flavor.Name}
+			for _, option := range flavor.Options {
+				choice.Options = append(choice.Options, option.Name)
+			}
+			choices = append(choices, choice)
+		}
+	}
+	choice, err := s.Resolve(groups, choices, cart.DishFlavor)
+	if err != nil {
+		if errs.GetCode(err) == constant.CodeBusinessError {
+			return nil, errs.Wrap(err, constant.CodeBusinessError, constant.MsgFlavorChanged+"："+cart.Name)
+		}
+		return nil, err
+	}
+	return choice, nil
+}
+
+// parseFlavorText 将逗号分隔的选项名称匹配到口味组，有无法匹配的名称时返回 nil
+func parseFlavorText(groups []*entity.DishFlavor, text string) []*dto.FlavorChoiceDTO {
+	byGroup := make(map[string]*dto.FlavorChoiceDTO)
+	var choices []*dto.FlavorChoiceDTO
+	for _, name := range strings.Split(text, flavorSeparator) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		var group *entity.DishFlavor
+		for _, g := range groups {
+			for _, option := range g.Options {
+				if option.Name == name {
+					group = g
+					break
+				}
+			}
+			if group != nil {
+				break
+			}
+		}
+		if group == nil {
+			return nil
+		}
+		choice, ok := byGroup[group.Name]
+		if !ok {
+			choice = &dto.FlavorChoiceDTO{Name: group.Name}
+			byGroup[group.Name] = choice
+			choices = append(choices, choice)
+		}
+		choice.Options = append(choice.Options, name)
+	}
+	return choices
+}
+
+// legacyOptions 解析旧版口味的 Value，如 ["不辣","微辣"]，解析失败时返回 nil
+func legacyOptions(group *entity.DishFlavor) []*entity.DishFlavorOption {
+	var names []string
+	if err := json.Unmarshal([]byte(group.Value), &names); err != nil {
+		return nil
+	}
+	options := make([]*entity.DishFlavorOption, 0, len(names))
+	for i, name := range names {
+		options = append(options, &entity.DishFlavorOption{FlavorID: group.ID, DishID: group.DishID, Name: name, Sort: i})
+	}
+	return options
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/internal/dao"
+	"takeout/internal/websocket"
+	"takeout/model/dto"
+	"takeout/model/entity"
+	"takeout/model/vo"
+)
+
+// KitchenService 后厨出餐服务，商家接单后将订单拆分为各档口的工单
+type KitchenService struct {
+	kitchenDAO     dao.KitchenDAO
+	orderDAO       dao.OrderDAO
+	orderDetailDAO dao.OrderDetailDAO
+	setmealDishDAO dao.SetmealDishDAO
+	dishDAO        dao.DishDAO
+}
+
+// CreateStation 新增档口
+func (s *KitchenService) CreateStation(ctx *gin.Context, stationDTO *dto.KitchenStationDTO) error {
+	station, err := toKitchenStation(stationDTO)
+	if err != nil {
+		return err
+	}
+	return global.DB.Transaction(func(tx *gorm.DB) error {
+		if e := s.kitchenDAO.CreateStation(ctx, tx, station); e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		return s.saveCategories(tx, station)
+	})
+}
+
+// UpdateStation 修改档口，已生成的工单不受影响
+func (s *KitchenService) UpdateStation(ctx *gin.Context, stationDTO *dto.KitchenStationDTO) error {
+	station, err := toKitchenStation(stationDTO)
+	if err != nil {
+		return err
+	}
+	return global.DB.Transaction(func(tx *gorm.DB) error {
+		if e := s.kitchenDAO.UpdateStation(ctx, tx, station); e != nil {
+			if errors.Is(e, gorm.ErrRecordNotFound) {
+				return errs.New(constant.CodeNotFound, constant.MsgStationNotFound)
+			}
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		return s.saveCategories(tx, station)
+	})
+}
+
+// DeleteStation 删除档口，其分类之后的工单归入默认档口
+func (s *KitchenService) DeleteStation(id int) error {
+	return global.DB.Transaction(func(tx *gorm.DB) error {
+		rows, err := s.kitchenDAO.DeleteStation(tx, id)
+		if err != nil {
+			return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if rows == 0 {
+			return errs.New(constant.CodeNotFound, constant.MsgStationNotFound)
+		}
+		return nil
+	})
+}
+
+// ListStations 查询全部档口及其分类
+func (s *KitchenService) ListStations() ([]*entity.KitchenStation, error) {
+	stations, err := s.kitchenDAO.ListStations(global.DB)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	categories, err := s.kitchenDAO.ListStationCategories(global.DB)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	byStation := make(map[int][]int, len(stations))
+	for _, c := range categories {
+		byStation[c.StationID] = append(byStation[c.StationID], c.CategoryID)
+	}
+	for _, station := range stations {
+		station.CategoryIDs = byStation[station.ID]
+		if station.CategoryIDs == nil {
+			station.CategoryIDs = make([]int, 0)
+		}
+	}
+	return stations, nil
+}
+
+// Explode 将已接单的订单拆分为工单，套餐按套餐菜品和份数展开，需要在状态流转事务中调用
+func (s *KitchenService) Explode(db *gorm.DB, order *entity.Order) error {
+	details, err := s.orderDetailDAO.GetByOrderID(db, order.ID)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	var setmealIDs []int
+	for _, detail := range details {
+		if detail.SetmealID != 0 {
+			setmealIDs = append(setmealIDs, detail.SetmealID)
+		}
+	}
+	setmealDishes := make(map[int][]*entity.SetmealDish, len(setmealIDs))
+	if len(setmealIDs) > 0 {
+		list, e := s.setmealDishDAO.ListBySetmealIDs(db, setmealIDs)
+		if e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		for _, sd := range list {
+			setmealDishes[sd.SetmealID] = append(setmealDishes[sd.SetmealID], sd)
+		}
+	}
+
+	var tickets []*entity.KitchenTicket
+	newTicket := func(detail *entity.OrderDetail) *entity.KitchenTicket {
+		return &entity.KitchenTicket{
+			OrderID:       order.ID,
+			OrderNumber:   order.Number,
+			OrderDetailID: detail.ID,
+			Remark:        order.Remark,
+			Status:        constant.TicketQueued,
+		}
+	}
+	for _, detail := range details {
+		if detail.SetmealID == 0 {
+			ticket := newTicket(detail)
+			ticket.DishID = detail.DishID
+			ticket.Name = detail.Name
+			ticket.DishFlavor = detail.DishFlavor
+			ticket.Number = detail.Number
+			tickets = append(tickets, ticket)
+			continue
+		}
+		for _, sd := range setmealDishes[detail.SetmealID] {
+			ticket := newTicket(detail)
+			ticket.DishID = sd.DishID
+			ticket.SetmealID = detail.SetmealID
+			ticket.SetmealName = detail.Name
+			ticket.Name = sd.Name
+			ticket.Number = detail.Number * max(sd.Copies, 1)
+			tickets = append(tickets, ticket)
+		}
+	}
+	if len(tickets) == 0 {
+		return nil
+	}
+	if err = s.assignStations(db, tickets); err != nil {
+		return err
+	}
+	if err = s.kitchenDAO.BatchCreateTickets(db, tickets); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// Discard 订单取消时撤下工单，需要在状态流转事务中调用
+func (s *KitchenService) Discard(db *gorm.DB, orderID int) error {
+	if _, err := s.kitchenDAO.DeleteTicketsByOrderID(db, orderID); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// Tickets 按档口分组查询工单，档口按排序返回，默认档口在最后
+func (s *KitchenService) Tickets(queryDTO *dto.KitchenTicketQueryDTO) ([]*vo.KitchenStationTicketsVO, error) {
+	status := []int{constant.TicketQueued, constant.TicketCooking}
+	switch queryDTO.Status {
+	case 0:
+	case constant.TicketQueued, constant.TicketCooking, constant.TicketReady:
+		status = []int{queryDTO.Status}
+	default:
+		return nil, errs.New(constant.CodeBadRequest, constant.MsgBadRequest)
+	}
+	stationID := constant.InvalidStatus
+	if queryDTO.StationID != nil {
+		stationID = *queryDTO.StationID
+	}
+	tickets, err := s.kitchenDAO.ListTickets(global.DB, stationID, status)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	stations, err := s.kitchenDAO.ListStations(global.DB)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+
+	groups := make(map[int]*vo.KitchenStationTicketsVO, len(stations)+1)
+	result := make([]*vo.KitchenStationTicketsVO, 0, len(stations)+1)
+	for _, station := range append(stations, &entity.KitchenStation{ID: constant.DefaultStation, Name: constant.DefaultStationName}) {
+		if stationID != constant.InvalidStatus && station.ID != stationID {
+			continue
+		}
+		group := &vo.KitchenStationTicketsVO{
+			StationID:   station.ID,
+			StationName: station.Name,
+			Tickets:     make([]*entity.KitchenTicket, 0),
+		}
+		groups[station.ID] = group
+		result = append(result, group)
+	}
+	for _, ticket := range tickets {
+		group, ok := groups[ticket.StationID]
+		if !ok {
+			// 档口已删除的工单归入默认档口
+			group = groups[constant.DefaultStation]
+		}
+		if group != nil {
+			group.Tickets = append(group.Tickets, ticket)
+		}
+	}
+	return result, nil
+}
+
+// UpdateTicketStatus 更新工单进度，只能向前流转；订单的工单全部出餐后标记订单待取餐
+func (s *KitchenService) UpdateTicketStatus(id, status int) error {
+	if status != constant.TicketCooking && status != constant.TicketReady {
+		return errs.New(constant.CodeBadRequest, constant.MsgTicketStatusError)
+	}
+	ticket, err := s.kitchenDAO.GetTicket(global.DB, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.New(constant.CodeNotFound, constant.MsgTicketNotFound)
+		}
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if ticket.Status >= status {
+		return errs.New(constant.CodeBusinessError, constant.MsgTicketStatusError)
+	}
+	rows, err := s.kitchenDAO.UpdateTicketStatus(global.DB, id, ticket.Status, status)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if rows == 0 {
+		return errs.New(constant.CodeBusinessError, constant.MsgTicketStatusChanged)
+	}
+	websocket.SendToMerchant(map[string]any{
+		"type":      constant.KitchenChange,
+		"orderId":   ticket.OrderID,
+		"ticketId":  ticket.ID,
+		"stationId": ticket.StationID,
+		"status":    status,
+	})
+	if status != constant.TicketReady {
+		return nil
+	}
+
+	// 并发出餐时只有一方能标记成功，不会重复通知
+	unready, err := s.kitchenDAO.CountUnready(global.DB, ticket.OrderID)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if unready > 0 {
+		return nil
+	}
+	rows, err = s.orderDAO.MarkMealReady(global.DB, ticket.OrderID)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if rows == 0 {
+		return nil
+	}
+	order, err := s.orderDAO.GetByID(global.DB, ticket.OrderID)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	msg := map[string]any{"type": constant.OrderReady, "orderId": order.ID, "content": "订单已出餐，等待取餐：" + order.Number}
+	websocket.SendToMerchant(msg)
+	if order.RiderID != 0 {
+		websocket.SendToRider(order.RiderID, msg)
+	}
+	return nil
+}
+
+// notifyOrder 订单进入或撤出后厨时通知后厨屏幕刷新
+func (s *KitchenService) notifyOrder(order *entity.Order, content string) {
+	websocket.SendToMerchant(map[string]any{
+		"type":    constant.KitchenChange,
+		"orderId": order.ID,
+		"status":  order.Status,
+		"content": content,
+	})
+}
+
+// assignStations 按菜品分类为工单分配档口
+func (s *KitchenService) assignStations(db *gorm.DB, tickets []*entity.KitchenTicket) error {
+	mappings, err := s.kitchenDAO.ListStationCategories(db)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if len(mappings) == 0 {
+		return nil
+	}
+	stationByCategory := make(map[int]int, len(mappings))
+	for _, m := range mappings {
+		stationByCategory[m.CategoryID] = m.StationID
+	}
+	dishIDs := make([]int, 0, len(tickets))
+	for _, ticket := range tickets {
+		dishIDs = append(dishIDs, ticket.DishID)
+	}
+	dishes, err := s.dishDAO.ListByIDs(db, dishIDs)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	categoryByDish := make(map[int]int, len(dishes))
+	for _, dish := range dishes {
+		categoryByDish[dish.ID] = dish.CategoryID
+	}
+	for _, ticket := range tickets {
+		ticket.StationID = stationByCategory[categoryByDish[ticket.DishID]]
+	}
+	return nil
+}
+
+// saveCategories 保存档口负责的分类，分类已分配给其他档口时返回业务错误
+func (s *KitchenService) saveCategories(tx *gorm.DB, station *entity.KitchenStation) error {
+	if err := s.kitchenDAO.ReplaceCategories(tx, station.ID, station.CategoryIDs); err != nil {
+		if strings.Contains(err.Error(), constant.MsgKeyDuplicateError) {
+			return errs.Wrap(err, constant.CodeBusinessError, constant.MsgStationCategoryUsed)
+		}
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// toKitchenStation 校验并转换档口参数
+func toKitchenStation(stationDTO *dto.KitchenStationDTO) (*entity.KitchenStation, error) {
+	name := strings.TrimSpace(stationDTO.Name)
+	if name == "" {
+		return nil, errs.New(constant.CodeBadRequest, constant.MsgStationParamError)
+	}
+	seen := make(map[int]bool, len(stationDTO.CategoryIDs))
+	for _, categoryID := range stationDTO.CategoryIDs {
+		if categoryID <= 0 || seen[categoryID] {
+			return nil, errs.New(constant.CodeBadRequest, constant.MsgStationParamError)
+		}
+		seen[categoryID] = true
+	}
+	return &entity.KitchenStation{
+		ID:          stationDTO.ID,
+		Name:        name,
+		Sort:        stationDTO.Sort,
+		CategoryIDs: stationDTO.CategoryIDs,
+	}, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/common/utils"
+	"takeout/model/vo"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 登录失败计数保存在 Redis 中：
+//   login::fail::{aud}::user::{username}   用户名在窗口期内的失败次数
+//   login::fail::{aud}::ip::{ip}           IP 在窗口期内的失败次数
+//   login::lock::{aud}::user|ip::{...}     锁定标记，过期自动解锁
+//   login::delay::{aud}::{username}        递增等待期，期内的尝试直接拒绝
+// 用户名不区分是否存在，统一计数，避免通过锁定行为判断账号是否存在
+
+// LoginGuardService 登录防暴力破解
+type LoginGuardService struct{}
+
+func loginFailKey(aud, kind, value string) string {
+	return constant.RedisKeyLoginFail + aud + "::" + kind + "::" + value
+}
+
+func loginLockKey(aud, kind, value string) string {
+	return constant.RedisKeyLoginLock + aud + "::" + kind + "::" + value
+}
+
+func loginDelayKey(aud, username string) string {
+	return constant.RedisKeyLoginDelay + aud + "::" + username
+}
+
+// normalizeUsername 计数时忽略大小写和首尾空格，防止换个写法绕过
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// Check 登录前检查是否被锁定、是否处于等待期、是否需要验证码
+func (s *LoginGuardService) Check(ctx context.Context, aud, username, ip, captchaID, captchaCode string) error {
+	username = normalizeUsername(username)
+	values, err := global.Redis.MGet(ctx,
+		loginLockKey(aud, "user", username),
+		loginLockKey(aud, "ip", ip),
+		loginDelayKey(aud, username),
+		loginFailKey(aud, "user", username),
+		loginFailKey(aud, "ip", ip),
+	).Result()
+	if err != nil {
+		return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	if values[0] != nil || values[1] != nil {
+		return errs.New(constant.CodeTooManyRequests, constant.MsgLoginLocked)
+	}
+	if values[2] != nil {
+		return errs.New(constant.CodeTooManyRequests, constant.MsgLoginTooFrequent)
+	}
+
+	userFails, ipFails := redisInt(values[3]), redisInt(values[4])
+	if userFails < constant.LoginCaptchaThreshold && ipFails < constant.LoginMaxFailures {
+		return nil
+	}
+	if captchaID == "" || captchaCode == "" {
+		return errs.New(constant.CodeCaptchaRequired, constant.MsgCaptchaRequired)
+	}
+	return s.verifyCaptcha(ctx, captchaID, captchaCode)
+}
+
+// Fail 记录一次失败，达到阈值后进入递增等待期或锁定
+func (s *LoginGuardService) Fail(ctx context.Context, aud, username, ip string) error {
+	username = normalizeUsername(username)
+	window := time.Duration(constant.LoginFailWindow) * time.Second
+	var userIncr, ipIncr *redis.IntCmd
+	_, err := global.Redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		userIncr = pipe.Incr(ctx, loginFailKey(aud, "user", username))
+		ipIncr = pipe.Incr(ctx, loginFailKey(aud, "ip", ip))
+		return nil
+	})
+	if err != nil {
+		return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+
+	lock := time.Duration(constant.LoginLockDuration) * time.Second
+	_, err = global.Redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		// 窗口期从第一次失败开始计算
+		if userIncr.Val() == 1 {
+			pipe.Expire(ctx, loginFailKey(aud, "user", username), window)
+		}
+		if ipIncr.Val() == 1 {
+			pipe.Expire(ctx, loginFailKey(aud, "ip", ip), window)
+		}
+		userFails := int(userIncr.Val())
+		switch {
+		case userFails >= constant.LoginMaxFailures:
+			pipe.Set(ctx, loginLockKey(aud, "user", username), 1, lock)
+			pipe.Del(ctx, loginFailKey(aud, "user", username), loginDelayKey(aud, username))
+		case userFails >= constant.LoginCaptchaThreshold:
+			// 1、2、4 … 秒递增，最长 LoginMaxDelay 秒
+			delay := 1 << (userFails - constant.LoginCaptchaThreshold)
+			if delay > constant.LoginMaxDelay {
+				delay = constant.LoginMaxDelay
+			}
+			pipe.Set(ctx, loginDelayKey(aud, username), 1, time.Duration(delay)*time.Second)
+		}
+		if ipIncr.Val() >= constant.LoginIPMaxFailures {
+			pipe.Set(ctx, loginLockKey(aud, "ip", ip), 1, lock)
+			pipe.Del(ctx, loginFailKey(aud, "ip", ip))
+		}
+		return nil
+	})
+	if err != nil {
+		return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	return nil
+}
+
+// Succeed 登录成功后清除用户名的失败计数，IP 计数保留到窗口期结束
+func (s *LoginGuardService) Succeed(ctx context.Context, aud, username string) {
+	username = normalizeUsername(username)
+	global.Redis.Del(ctx, loginFailKey(aud, "user", username), loginDelayKey(aud, username))
+}
+
+// Unlock 管理员手动解除用户名的锁定和失败计数
+func (s *LoginGuardService) Unlock(ctx context.Context, aud, username string) error {
+	username = normalizeUsername(username)
+	err := global.Redis.Del(ctx,
+		loginLockKey(aud, "user", username),
+		loginFailKey(aud, "user", username),
+		loginDelayKey(aud, username),
+	).Err()
+	if err != nil {
+		return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	return nil
+}
+
+// Captcha 生成验证码，答案保存在 Redis 中，只能校验一次
+func (s *LoginGuardService) Captcha(ctx context.Context) (*vo.CaptchaVO, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errs.Wrap(err, constant.CodeServerError, constant.MsgCaptchaFail)
+	}
+	captchaID := hex.EncodeToString(b)
+	image, answer := utils.NewCaptcha()
+	ttl := time.Duration(constant.CaptchaTTL) * time.Second
+	if err := global.Redis.Set(ctx, constant.RedisKeyCaptcha+captchaID, answer, ttl).Err(); err != nil {
+		return nil, errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	return &vo.CaptchaVO{CaptchaID: captchaID, Image: image}, nil
+}
+
+func (s *LoginGuardService) verifyCaptcha(ctx context.Context, captchaID, captchaCode string) error {
+	key := constant.RedisKeyCaptcha + captchaID
+	var get *redis.StringCmd
+	_, err := global.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	answer := get.Val()
+	if answer == "" || answer != strings.TrimSpace(captchaCode) {
+		return errs.New(constant.CodeCaptchaRequired, constant.MsgCaptchaIncorrect)
+	}
+	return nil
+}
+
+// redisInt MGET 返回的计数值，不存在时为 0
+func redisInt(v interface{}) int {
+	str, _ := v.(string)
+	n, _ := strconv.Atoi(str)
+	return n
+}
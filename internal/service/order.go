@@ -1,16 +1,18 @@
 package service
 
 import (
-	"encoding/json"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"strconv"
 	"strings"
 	"takeout/common/constant"
 	"takeout/common/errs"
 	"takeout/common/global"
+	"takeout/common/logger"
+	"takeout/common/payment"
 	"takeout/common/utils"
 	"takeout/internal/dao"
 	"takeout/internal/websocket"
@@ -27,6 +29,21 @@ type OrderService struct {
 	shoppingCartDAO dao.ShoppingCartDAO
 	orderDetailDAO  dao.OrderDetailDAO
 	userDAO         dao.UserDAO
+
+	orderStatusHistoryDAO dao.OrderStatusHistoryDAO
+	stateMachine          OrderStateMachine
+	refundService         RefundService
+	priceCalculator       PriceCalculator
+	stockService          StockService
+	couponService         CouponService
+	pointsService         PointsService
+	walletService         WalletService
+	riderDAO              dao.RiderDAO
+	riderDispatcher       RiderDispatcher
+	deliverySlotService   DeliverySlotService
+	deliveryZoneService   DeliveryZoneService
+	shopService           ShopService
+	printService          PrintService
 }
 
 // Submit 提交订单
@@ -35,22 +52,29 @@ func (s *OrderService) Submit(ctx *gin.Context, submitDTO *dto.OrderSubmitDTO) (
 	if err != nil {
 		return nil, err
 	}
-	var submitVO vo.OrderSubmitVO
-	err = global.DB.Transaction(func(db *gorm.DB) error {
-		address, e := s.addressBookDAO.GetByID(db, submitDTO.AddressBookID)
-		if e != nil {
-			if errors.Is(e, gorm.ErrRecordNotFound) {
-				return errs.Wrap(e, constant.CodeBusinessError, constant.MsgAddressBookIsNull)
-			}
-			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	// 不在营业时间内不能立即送出，预约单只要求时段在营业时间内，由占用时段时校验
+	if submitDTO.DeliveryStatus == constant.DeliveryImmediate {
+		if err = s.shopService.CheckOpen(); err != nil {
+			return nil, err
 		}
-
-		// 判断是否可以配送
-		//e = utils.CheckOutOfRange(address.CityName + address.DistrictName + address.Detail)
-		//if e != nil {
-		//	return errs.Wrap(e, constant.CodeBusinessError, "无法配送")
-		//}
-
+	}
+	address, err := s.addressBookDAO.GetByID(global.DB, submitDTO.AddressBookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.Wrap(err, constant.CodeBusinessError, constant.MsgAddressBookIsNull)
+		}
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	// 判断是否可以配送并计算配送费，地址解析和路线规划会调用外部接口，放在事务之外，避免长时间持有行锁
+	quote, err := s.deliveryZoneService.Quote(global.DB, address)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		submitVO vo.OrderSubmitVO
+		soldOut  bool
+	)
+	err = global.DB.Transaction(func(db *gorm.DB) error {
 		cartList, e := s.shoppingCartDAO.List(db, &entity.ShoppingCart{UserID: userID})
 		if e != nil {
 			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
@@ -59,34 +83,128 @@ func (s *OrderService) Submit(ctx *gin.Context, submitDTO *dto.OrderSubmitDTO) (
 			return errs.New(constant.CodeBusinessError, constant.MsgShoppingCartIsNull)
 		}
 
+		// 按商品当前价格重新计算订单金额，客户端传入的金额只用于校验
+		price, e := s.priceCalculator.Calculate(db, cartList, submitDTO.TablewareStatus, submitDTO.TablewareNumber)
+		if e != nil {
+			return e
+		}
+		// 价格变动的商品需要用户先在购物车中确认
+		for _, line := range price.Lines {
+			if !line.Cart.Amount.Equal(line.UnitPrice) {
+				return errs.New(constant.CodeBusinessError, constant.MsgCartChanged)
+			}
+		}
+		// 按所在配送区域校验起送金额
+		if price.GoodsAmount.LessThan(quote.MinAmount) {
+			return errs.New(constant.CodeBusinessError, constant.MsgBelowMinAmount)
+		}
+		price.SetDeliveryFee(quote.DeliveryFee)
+		if submitDTO.CouponID != 0 {
+			if e = s.couponService.Apply(db, userID, submitDTO.CouponID, price); e != nil {
+				return e
+			}
+		}
+		// 积分抵扣在优惠券之后计算，抵扣上限按券后金额计算
+		pointsAmount := decimal.Zero
+		if submitDTO.Points != 0 {
+			if pointsAmount, e = s.pointsService.Apply(db, userID, submitDTO.Points, price); e != nil {
+				return e
+			}
+		}
+		if !price.Amount.Equal(submitDTO.Amount.Round(2)) {
+			return errs.New(constant.CodeBusinessError, constant.MsgOrderAmountChanged)
+		}
+
+		// 预约单校验并占用配送时段
+		var reservation *SlotReservation
+		switch submitDTO.DeliveryStatus {
+		case constant.DeliveryImmediate:
+		case constant.DeliveryScheduled:
+			reservation, e = s.deliverySlotService.Reserve(db, submitDTO.DeliverySlotID, submitDTO.EstimatedDeliveryTime.Time())
+			if e != nil {
+				return e
+			}
+		default:
+			return errs.New(constant.CodeBadRequest, constant.MsgDeliveryStatusErr)
+		}
+
 		// 插入一条订单数据
 		order := &entity.Order{}
 		e = utils.CopyProperties(submitDTO, order)
 		if e != nil {
 			return errs.Wrap(e, constant.CodeInternalError, constant.MsgCopyPropertiesFail)
 		}
-		order.Number = strconv.FormatInt(time.Now().UnixMilli(), 10)
+		order.Number, e = utils.NextSerialNumber("order", "")
+		if e != nil {
+			return errs.Wrap(e, constant.CodeCacheError, constant.MsgCacheError)
+		}
 		order.Status = constant.PendingPayment
 		order.PayStatus = constant.UnPaid
 		order.Phone = address.Phone
 		order.Consignee = address.Consignee
 		order.UserID = userID
 		order.Address = address.Detail
+		order.Amount = price.Amount
+		order.GoodsAmount = price.GoodsAmount
+		order.PackAmount = price.PackAmount
+		order.TablewareAmount = price.TablewareAmount
+		order.DeliveryFee = price.DeliveryFee
+		order.DiscountAmount = price.DiscountAmount
+		order.PointsUsed = submitDTO.Points
+		order.PointsAmount = pointsAmount
+		order.DeliverySlotID = 0
+		if reservation != nil {
+			// 未到出餐提前期的预约单先对后厨隐藏，由定时任务到点推送
+			order.DeliverySlotID = reservation.SlotID
+			order.EstimatedDeliveryTime = wrap.LocalTime(reservation.DeliveryTime)
+			order.ReleaseTime = wrap.LocalTime(reservation.ReleaseTime)
+			if reservation.ReleaseTime.After(time.Now()) {
+				order.Held = constant.OrderHeld
+			}
+		}
 		e = s.orderDAO.Insert(db, order)
 		if e != nil {
 			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
 		}
+		// 扣减库存并按订单记录扣减的份数，取消时按记录归还
+		if soldOut, e = s.stockService.Deduct(db, order.ID, cartList); e != nil {
+			return e
+		}
+		if order.CouponID != 0 {
+			if e = s.couponService.Use(db, userID, order.CouponID, order.ID); e != nil {
+				return e
+			}
+		}
+		if order.PointsUsed != 0 {
+			if e = s.pointsService.Use(db, userID, order.PointsUsed, order.ID); e != nil {
+				return e
+			}
+		}
 
 		// 向订单明细表插入数据
 		var orderDetailList []*entity.OrderDetail
 		// index, value := range ☆
-		for _, cart := range cartList {
+		for _, line := range price.Lines {
 			orderDetail := &entity.OrderDetail{}
-			e = utils.CopyProperties(cart, orderDetail)
+			e = utils.CopyProperties(line.Cart, orderDetail)
 			if e != nil {
 				return errs.Wrap(e, constant.CodeInternalError, constant.MsgCopyPropertiesFail)
 			}
+			// 明细保存下单时的价格快照
+			orderDetail.ID = 0
 			orderDetail.OrderID = order.ID
+			orderDetail.Name = line.Name
+			orderDetail.Image = line.Image
+			orderDetail.Amount = line.UnitPrice
+			orderDetail.PackAmount = line.PackAmount
+			orderDetail.TotalAmount = line.Amount
+			if line.Flavor != nil {
+				orderDetail.DishFlavor = line.Flavor.Text
+				orderDetail.FlavorFee = line.Flavor.Surcharge
+				if orderDetail.Flavors, e = line.Flavor.Snapshot(); e != nil {
+					return e
+				}
+			}
 			orderDetailList = append(orderDetailList, orderDetail)
 		}
 		e = s.orderDetailDAO.BatchInsert(db, orderDetailList)
@@ -109,111 +227,168 @@ func (s *OrderService) Submit(ctx *gin.Context, submitDTO *dto.OrderSubmitDTO) (
 	if err != nil {
 		return nil, err
 	}
+	// 有菜品售罄停售时，事务提交后再清除缓存，避免缓存被重新写入旧数据
+	if soldOut {
+		s.stockService.cleanCache()
+	}
 	return &submitVO, nil
 }
 
-// RealPayment 实际微信支付
-func (s *OrderService) RealPayment(ctx *gin.Context, payDTO *dto.OrderPaymentDTO) (*vo.OrderPaymentVO, error) {
-	userID, err := utils.GetId(ctx)
-	if err != nil {
-		return nil, err
-	}
-	user, err := s.userDAO.GetByID(global.DB, userID)
+// PaySuccess 支付成功后修改订单状态
+func (s *OrderService) PaySuccess(no string) error { // no是订单号
+	order, err := s.orderDAO.GetByNumber(global.DB, no)
 	if err != nil {
-		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
-	}
-	jsonObject, err := utils.Pay(payDTO.OrderNumber, decimal.NewFromFloat(0.01), "外卖订单", user.OpenID)
-	if err != nil { // 检查错误
-		return nil, errs.Wrap(err, constant.CodeInternalError, "支付失败")
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
 	}
-	// 使用map解析
-	var jsonMap map[string]any
-	err = json.Unmarshal(jsonObject, &jsonMap)
-	if err != nil {
-		return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgUnmarshalFail)
+	// 支付回调可能重复推送，已支付的订单直接返回
+	if order.PayStatus == constant.Paid {
+		return nil
 	}
-	if code, ok := jsonMap["code"]; ok {
-		if code.(string) == "ORDERPAID" {
-			return nil, errs.New(constant.CodeBusinessError, constant.MsgOrderPaid)
-		}
+	// 订单已超时或被取消后才收到付款，记为已支付后原路退款
+	if order.Status == constant.Cancelled {
+		return s.refundLatePayment(order)
 	}
-	// 反序列化为VO
-	var payVO vo.OrderPaymentVO
-	if err = json.Unmarshal(jsonObject, &payVO); err != nil {
-		return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgUnmarshalFail)
+	if _, err = s.paySuccess(global.DB, order, constant.PayWeChat); err != nil {
+		return err
 	}
-	payVO.PackageStr = jsonMap["package"].(string)
-	return &payVO, nil
+	s.notifyMerchant(order)
+	return nil
 }
 
-// PaySuccess 支付成功后修改订单状态
-func (s *OrderService) PaySuccess(no string) error { // no是订单号
-	order, err := s.orderDAO.GetByNumber(global.DB, no)
-	if err != nil {
-		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+// paySuccess 将订单流转为待接单并记录支付方式
+// 在外层事务中调用时，由调用方在事务提交后推送给用户和商户
+func (s *OrderService) paySuccess(db *gorm.DB, order *entity.Order, payMethod int) (*OrderTransition, error) {
+	t := &OrderTransition{
+		Order:        order,
+		To:           constant.ToBeConfirmed,
+		OperatorType: constant.OperatorSystem,
+		Reason:       "支付成功",
+		Updates: &entity.Order{
+			PayMethod:    constant.PayWeChat,
+			PayStatus:    constant.Paid,
+			CheckoutTime: wrap.LocalTime(time.Now()),
+		},
+	}
+	if err := s.stateMachine.Transit(db, t); err != nil {
+		return nil, err
 	}
-	order.Status = constant.ToBeConfirmed
+	order.PayMethod = payMethod
 	order.PayStatus = constant.Paid
-	order.CheckoutTime = wrap.LocalTime(time.Now())
-	err = s.orderDAO.Update(global.DB, order)
+	return t, nil
+}
+
+// refundLatePayment 已取消的订单收到付款时标记为已支付并创建退款单，提交失败由定时任务重试
+// 余额支付与订单状态流转在同一事务中，不会出现这种情况
+func (s *OrderService) refundLatePayment(order *entity.Order) error {
+	var refund *entity.Refund
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		rows, e := s.orderDAO.MarkPaid(tx, &entity.Order{
+			ID:           order.ID,
+			PayMethod:    constant.PayWeChat,
+			PayStatus:    constant.Paid,
+			CheckoutTime: wrap.LocalTime(time.Now()),
+		})
+		if e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		// 重复的回调已经处理过
+		if rows == 0 {
+			return nil
+		}
+		order.PayMethod = constant.PayWeChat
+		order.PayStatus = constant.Paid
+		refund, e = s.refundService.Create(tx, order, "订单已取消")
+		return e
+	})
 	if err != nil {
-		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		return err
+	}
+	if refund != nil {
+		if e := s.refundService.Submit(refund); e != nil {
+			logger.Error(constant.MsgRefundFail, zap.String("refundNumber", refund.Number), zap.Error(e))
+		}
 	}
-	// 通知商户
-	m := map[string]any{"type": constant.NotifyOrder, "orderId": order.ID, "content": "订单号：" + order.Number}
-	websocket.SendToAllClients(m)
 	return nil
 }
 
-// Payment 绕过微信支付
-func (s *OrderService) Payment(dto *dto.OrderPaymentDTO) (*vo.OrderPaymentVO, error) {
-	//userID, err := utils.GetId(ctx)
-	//if err != nil {
-	//	return nil, err
-	//}
-	//user, err := s.userDAO.GetByID(global.DB, userID)
-	//if err != nil {
-	//	return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
-	//}
+// notifyMerchant 通知商户有新的已支付订单，预约单到出餐提前期后再通知
+func (s *OrderService) notifyMerchant(order *entity.Order) {
+	if order.Held == constant.OrderHeld {
+		return
+	}
+	m := map[string]any{"type": constant.NotifyOrder, "orderId": order.ID, "content": "订单号：" + order.Number}
+	websocket.SendToMerchant(m)
+}
 
-	timeStamp := strconv.FormatInt(time.Now().Unix(), 10)
-	nonceStr := utils.GenerateRandomNumericString(32)
-	jo := map[string]any{
-		"timeStamp": timeStamp,
-		"nonceStr":  nonceStr,
-		"package":   "prepay_id=" + strconv.Itoa(123),
-		"signType":  "RSA",
-		"paySign":   "success",
+// payByWallet 使用余额支付，扣款和订单状态流转在同一个事务中完成
+func (s *OrderService) payByWallet(order *entity.Order) error {
+	var t *OrderTransition
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if e := s.walletService.Pay(tx, order); e != nil {
+			return e
+		}
+		var e error
+		t, e = s.paySuccess(tx, order, constant.PayWallet)
+		return e
+	})
+	if err != nil {
+		return err
 	}
-	// 转换为 JSON
-	jsonObject, err := json.Marshal(jo)
+	s.stateMachine.Notify(t)
+	s.notifyMerchant(order)
+	return nil
+}
+
+// Payment 订单支付，余额支付直接扣款；其它方式向支付渠道预下单，支付结果由回调通知
+func (s *OrderService) Payment(ctx *gin.Context, payDTO *dto.OrderPaymentDTO) (*vo.OrderPaymentVO, error) {
+	userID, err := utils.GetId(ctx)
 	if err != nil {
 		return nil, err
 	}
-	var jsonMap map[string]any
-	err = json.Unmarshal(jsonObject, &jsonMap)
+	order, err := s.orderDAO.GetByNumber(global.DB, payDTO.OrderNumber)
 	if err != nil {
-		return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgUnmarshalFail)
-	}
-	if code, ok := jsonMap["code"]; ok {
-		if code.(string) == "ORDERPAID" {
-			return nil, errs.New(constant.CodeBusinessError, constant.MsgOrderPaid)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.Wrap(err, constant.CodeBusinessError, constant.MsgOrderNotFound)
 		}
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
 	}
-	// 反序列化为VO
-	var payVO vo.OrderPaymentVO
-	if err = json.Unmarshal(jsonObject, &payVO); err != nil {
-		return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgUnmarshalFail)
+	if order.UserID != userID {
+		return nil, errs.New(constant.CodeBusinessError, constant.MsgOrderNotFound)
 	}
-	payVO.PackageStr = jsonMap["package"].(string)
-
-	// 直接更改支付状态
-	err = s.PaySuccess(dto.OrderNumber)
+	if order.PayStatus == constant.Paid {
+		return nil, errs.New(constant.CodeBusinessError, constant.MsgOrderPaid)
+	}
+	if order.Status != constant.PendingPayment {
+		return nil, errs.New(constant.CodeBusinessError, constant.MsgOrderStatusError)
+	}
+	switch payDTO.PayMethod {
+	case constant.PayWallet:
+		// 余额支付同步完成，不需要调起支付
+		return nil, s.payByWallet(order)
+	case 0, constant.PayWeChat:
+	default:
+		return nil, errs.New(constant.CodeBadRequest, constant.MsgPayMethodInvalid)
+	}
+	user, err := s.userDAO.GetByID(global.DB, userID)
 	if err != nil {
-		return nil, err
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
 	}
-
-	return &payVO, nil
+	result, err := payment.GetProvider().Prepay(&payment.PrepayRequest{
+		OrderNumber: order.Number,
+		Description: "外卖订单",
+		Amount:      order.Amount, // 按服务端计算并保存的金额收款
+		OpenID:      user.OpenID,
+	})
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgPayFail)
+	}
+	return &vo.OrderPaymentVO{
+		NonceStr:   result.NonceStr,
+		PaySign:    result.PaySign,
+		TimeStamp:  result.TimeStamp,
+		SignType:   result.SignType,
+		PackageStr: result.Package,
+	}, nil
 }
 
 // Page 分页查询
@@ -223,6 +398,7 @@ func (s *OrderService) Page(ctx *gin.Context, queryDTO *dto.OrderPageQueryDTO) (
 		return nil, err
 	}
 	queryDTO.UserID = userID
+	queryDTO.Held = constant.InvalidStatus
 	total, list, err := s.orderDAO.Page(global.DB, queryDTO)
 	if err != nil {
 		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
@@ -246,7 +422,7 @@ func (s *OrderService) Page(ctx *gin.Context, queryDTO *dto.OrderPageQueryDTO) (
 	return &vo.PageResult{Total: total, Records: orderVOs}, nil
 }
 
-// Detail 查询订单详细信息
+// Detail 商家查询订单详细信息
 func (s *OrderService) Detail(id int) (*vo.OrderVO, error) {
 	// 查询订单
 	order, err := s.orderDAO.GetByID(global.DB, id)
@@ -256,8 +432,46 @@ func (s *OrderService) Detail(id int) (*vo.OrderVO, error) {
 		}
 		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
 	}
+	return s.detail(order, true)
+}
+
+// UserDetail 用户查询自己的订单详细信息，不返回操作人 ID
+func (s *OrderService) UserDetail(ctx *gin.Context, id int) (*vo.OrderVO, error) {
+	order, _, err := s.userOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(order, false)
+}
+
+// userOrder 查询当前用户的订单，他人的订单按不存在处理，避免泄露订单号是否有效
+func (s *OrderService) userOrder(ctx *gin.Context, id int) (*entity.Order, int, error) {
+	userID, err := utils.GetId(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	order, err := s.orderDAO.GetByID(global.DB, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, errs.Wrap(err, constant.CodeBusinessError, constant.MsgOrderNotFound)
+		}
+		return nil, 0, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if order.UserID != userID {
+		return nil, 0, errs.New(constant.CodeBusinessError, constant.MsgOrderNotFound)
+	}
+	return order, userID, nil
+}
+
+// detail 组装订单详情，withOperator 为 false 时时间线不包含操作人 ID
+func (s *OrderService) detail(order *entity.Order, withOperator bool) (*vo.OrderVO, error) {
 	// 查询订单详细
-	orderDetail, err := s.orderDetailDAO.GetByOrderID(global.DB, id)
+	orderDetail, err := s.orderDetailDAO.GetByOrderID(global.DB, order.ID)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	// 查询状态流转时间线
+	timeline, err := s.orderStatusHistoryDAO.ListByOrderID(global.DB, order.ID)
 	if err != nil {
 		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
 	}
@@ -266,40 +480,104 @@ func (s *OrderService) Detail(id int) (*vo.OrderVO, error) {
 		return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgCopyPropertiesFail)
 	}
 	orderVO.OrderDetailList = orderDetail
+	orderVO.StatusTimeline = make([]*vo.OrderStatusVO, 0, len(timeline))
+	for _, h := range timeline {
+		statusVO := &vo.OrderStatusVO{
+			FromStatus:   h.FromStatus,
+			ToStatus:     h.ToStatus,
+			OperatorType: h.OperatorType,
+			Reason:       h.Reason,
+			CreateTime:   h.CreateTime,
+		}
+		if withOperator {
+			statusVO.OperatorID = h.OperatorID
+		}
+		orderVO.StatusTimeline = append(orderVO.StatusTimeline, statusVO)
+	}
+	if order.RiderID != 0 {
+		rider, e := s.riderDAO.GetByID(global.DB, order.RiderID)
+		if e != nil && !errors.Is(e, gorm.ErrRecordNotFound) {
+			return nil, errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if e == nil {
+			orderVO.Rider = &vo.OrderRiderVO{
+				ID:           rider.ID,
+				Name:         rider.Name,
+				Phone:        rider.Phone,
+				Latitude:     rider.Latitude,
+				Longitude:    rider.Longitude,
+				LocationTime: rider.LocationTime,
+			}
+		}
+	}
 	return orderVO, nil
 }
 
 // CancelByUser 用户取消订单
-func (s *OrderService) CancelByUser(id int) error {
-	order, err := s.orderDAO.GetByID(global.DB, id)
+func (s *OrderService) CancelByUser(ctx *gin.Context, id int) error {
+	order, userID, err := s.userOrder(ctx, id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// 业务错误：未查询到订单
-			return errs.Wrap(err, constant.CodeBusinessError, constant.MsgOrderNotFound)
-		}
-		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		return err
 	}
+	// 商家接单后用户不能自行取消
 	if order.Status > constant.ToBeConfirmed {
 		return errs.New(constant.CodeBusinessError, constant.MsgOrderStatusError)
 	}
-	o := &entity.Order{ID: id}
-	if order.Status == constant.ToBeConfirmed {
-		// 已付款的订单需要退款
-		// _ = utils.Refund(order.Number, order.Number, decimal.NewFromFloat(0.01), decimal.NewFromFloat(0.01))
-		o.Status = constant.ReFund
+	updates := &entity.Order{
+		CancelReason: "用户取消",
+		CancelTime:   wrap.LocalTime(time.Now()),
 	}
-	o.Status = constant.Cancelled
-	o.CancelReason = "用户取消"
-	o.CancelTime = wrap.LocalTime(time.Now())
-	if err = s.orderDAO.Update(global.DB, o); err != nil {
-		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	// 已付款的订单会自动退款
+	return s.cancelWithRefund(&OrderTransition{
+		Order:        order,
+		To:           constant.Cancelled,
+		OperatorType: constant.OperatorUser,
+		OperatorID:   userID,
+		Reason:       updates.CancelReason,
+		Updates:      updates,
+	})
+}
+
+// cancelWithRefund 取消订单，已支付的订单在同一事务中退款
+// 余额支付的订单直接退回余额；其它订单创建退款单，提交后再向支付平台申请退款
+func (s *OrderService) cancelWithRefund(t *OrderTransition) error {
+	var refund *entity.Refund
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if e := s.stateMachine.Transit(tx, t); e != nil {
+			return e
+		}
+		if t.Order.PayStatus != constant.Paid {
+			return nil
+		}
+		if t.Order.PayMethod == constant.PayWallet {
+			if e := s.walletService.Refund(tx, t.Order); e != nil {
+				return e
+			}
+			if e := s.orderDAO.Update(tx, &entity.Order{ID: t.Order.ID, PayStatus: constant.ReFund}); e != nil {
+				return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+			}
+			return nil
+		}
+		var e error
+		refund, e = s.refundService.Create(tx, t.Order, t.Reason)
+		return e
+	})
+	if err != nil {
+		return err
+	}
+	s.stateMachine.Notify(t)
+	if refund != nil {
+		// 申请失败不影响取消结果，由定时任务重试
+		if e := s.refundService.Submit(refund); e != nil {
+			logger.Error(constant.MsgRefundFail, zap.String("refundNumber", refund.Number), zap.Error(e))
+		}
 	}
 	return nil
 }
 
 // Repetition 再来一单
 func (s *OrderService) Repetition(ctx *gin.Context, id int) error {
-	userID, err := utils.GetId(ctx)
+	_, userID, err := s.userOrder(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -374,70 +652,115 @@ func (s *OrderService) Statistics() (*vo.OrderStatisticsVO, error) {
 }
 
 // Confirm 商家接单
-func (s *OrderService) Confirm(dto *dto.OrderConfirmDTO) error {
-	order := &entity.Order{
-		ID:     dto.OrderID,
-		Status: constant.Confirmed,
-	}
-	err := s.orderDAO.Update(global.DB, order)
+func (s *OrderService) Confirm(ctx *gin.Context, dto *dto.OrderConfirmDTO) error {
+	empID, err := utils.GetId(ctx)
 	if err != nil {
-		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		return err
 	}
-	return nil
-}
-
-// Reject 商家拒单
-func (s *OrderService) Reject(dto *dto.OrderRejectionDTO) error {
 	order, err := s.orderDAO.GetByID(global.DB, dto.OrderID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errs.Wrap(err, constant.CodeBusinessError, constant.MsgOrderStatusError)
+			return errs.Wrap(err, constant.CodeBusinessError, constant.MsgOrderNotFound)
 		}
 		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
 	}
-	if order.PayStatus == constant.Paid {
-		// 退款
-		// _ = utils.Refund(order.Number, order.Number, decimal.NewFromFloat(0.01), decimal.NewFromFloat(0.01))
+	// 预约单到出餐提前期后才进入后厨，此前接单会让后厨提前开始制作
+	if order.Held == constant.OrderHeld {
+		return errs.New(constant.CodeBusinessError, constant.MsgOrderHeld)
+	}
+	err = s.stateMachine.Transit(global.DB, &OrderTransition{
+		Order:        order,
+		To:           constant.Confirmed,
+		OperatorType: constant.OperatorAdmin,
+		OperatorID:   empID,
+		Reason:       "商家接单",
+	})
+	if err != nil {
+		return err
 	}
-	o := &entity.Order{
-		ID:              order.ID,
-		Status:          constant.Cancelled,
-		RejectionReason: dto.RejectionReason,
-		CancelTime:      wrap.LocalTime(time.Now()),
+	// 自动指派失败不影响接单，商家可以再手动指派
+	if global.Config.Shop.AutoAssign {
+		if e := s.riderDispatcher.Assign(global.DB, order.ID, 0); e != nil {
+			logger.Error(constant.MsgRiderAssignFail, zap.Int("orderId", order.ID), zap.Error(e))
+		}
 	}
-	err = s.orderDAO.Update(global.DB, o)
+	// 打印同样不影响接单，打印机可能离线，异步发送避免拖慢接单，失败的任务由定时任务重试
+	jobs, err := s.printService.Enqueue(global.DB, order, 0)
 	if err != nil {
-		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		logger.Error(constant.MsgPrintFail, zap.Int("orderId", order.ID), zap.Error(err))
+		return nil
 	}
+	go s.printService.SendAll(jobs)
 	return nil
 }
 
-// Cancel 商家取消订单
-func (s *OrderService) Cancel(dto *dto.OrderCancelDTO) error {
+// AssignRider 商家为订单指派骑手，RiderID 为 0 时自动指派
+func (s *OrderService) AssignRider(assignDTO *dto.OrderAssignDTO) error {
+	return s.riderDispatcher.Assign(global.DB, assignDTO.OrderID, assignDTO.RiderID)
+}
+
+// Reject 商家拒单
+func (s *OrderService) Reject(ctx *gin.Context, dto *dto.OrderRejectionDTO) error {
+	empID, err := utils.GetId(ctx)
+	if err != nil {
+		return err
+	}
 	order, err := s.orderDAO.GetByID(global.DB, dto.OrderID)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.Wrap(err, constant.CodeBusinessError, constant.MsgOrderStatusError)
+		}
 		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
 	}
-	if order.PayStatus == constant.Paid {
-		// 退款
-		// _ = utils.Refund(order.Number, order.Number, decimal.NewFromFloat(0.01), decimal.NewFromFloat(0.01))
+	// 只有待接单的订单才能拒单
+	if order.Status != constant.ToBeConfirmed {
+		return errs.New(constant.CodeBusinessError, constant.MsgOrderStatusError)
 	}
+	return s.cancelWithRefund(&OrderTransition{
+		Order:        order,
+		To:           constant.Cancelled,
+		OperatorType: constant.OperatorAdmin,
+		OperatorID:   empID,
+		Reason:       dto.RejectionReason,
+		Updates: &entity.Order{
+			RejectionReason: dto.RejectionReason,
+			CancelTime:      wrap.LocalTime(time.Now()),
+		},
+	})
+}
 
-	o := &entity.Order{
-		ID:           order.ID,
-		Status:       constant.Cancelled,
-		CancelReason: dto.CancelReason,
-		CancelTime:   wrap.LocalTime(time.Now()),
+// Cancel 商家取消订单
+func (s *OrderService) Cancel(ctx *gin.Context, dto *dto.OrderCancelDTO) error {
+	empID, err := utils.GetId(ctx)
+	if err != nil {
+		return err
 	}
-	err = s.orderDAO.Update(global.DB, o)
+	order, err := s.orderDAO.GetByID(global.DB, dto.OrderID)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.Wrap(err, constant.CodeBusinessError, constant.MsgOrderNotFound)
+		}
 		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
 	}
-	return nil
+	return s.cancelWithRefund(&OrderTransition{
+		Order:        order,
+		To:           constant.Cancelled,
+		OperatorType: constant.OperatorAdmin,
+		OperatorID:   empID,
+		Reason:       dto.CancelReason,
+		Updates: &entity.Order{
+			CancelReason: dto.CancelReason,
+			CancelTime:   wrap.LocalTime(time.Now()),
+		},
+	})
 }
 
 // Delivery 派送订单
-func (s *OrderService) Delivery(id int) error {
+func (s *OrderService) Delivery(ctx *gin.Context, id int) error {
+	empID, err := utils.GetId(ctx)
+	if err != nil {
+		return err
+	}
 	order, err := s.orderDAO.GetByID(global.DB, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -445,60 +768,77 @@ func (s *OrderService) Delivery(id int) error {
 		}
 		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
 	}
-	// logger.Infof("%v", *order)
-	// 必须是接单的订单才能派送
-	if order.Status != constant.Confirmed {
-		return errs.New(constant.CodeBusinessError, constant.MsgOrderStatusError)
-	}
-	o := &entity.Order{
-		ID:     order.ID,
-		Status: constant.DeliveryInProgress,
-	}
-	err = s.orderDAO.Update(global.DB, o)
+	// 必须是接单的订单才能派送，由状态机校验
+	return s.stateMachine.Transit(global.DB, &OrderTransition{
+		Order:        order,
+		To:           constant.DeliveryInProgress,
+		OperatorType: constant.OperatorAdmin,
+		OperatorID:   empID,
+		Reason:       "商家派送",
+	})
+}
+
+// Complete 完成订单
+func (s *OrderService) Complete(ctx *gin.Context, id int) error {
+	empID, err := utils.GetId(ctx)
 	if err != nil {
-		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		return err
 	}
-	return nil
+	_, err = s.complete(global.DB, id, constant.OperatorAdmin, empID)
+	return err
 }
 
-// Complete 完成订单
-func (s *OrderService) Complete(id int) error {
-	order, err := s.orderDAO.GetByID(global.DB, id)
+// complete 完成订单，商家手动完成和骑手送达都经过这里
+func (s *OrderService) complete(db *gorm.DB, id, operatorType, operatorID int) (*OrderTransition, error) {
+	order, err := s.orderDAO.GetByID(db, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errs.Wrap(err, constant.CodeBusinessError, constant.MsgOrderStatusError)
+			return nil, errs.Wrap(err, constant.CodeBusinessError, constant.MsgOrderStatusError)
 		}
-		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
-	}
-	if order.Status != constant.DeliveryInProgress {
-		return errs.New(constant.CodeBusinessError, constant.MsgOrderStatusError)
-	}
-	o := &entity.Order{
-		ID:           order.ID,
-		Status:       constant.Completed,
-		DeliveryTime: wrap.LocalTime(time.Now()),
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
 	}
-	err = s.orderDAO.Update(global.DB, o)
-	if err != nil {
-		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	t := &OrderTransition{
+		Order:        order,
+		To:           constant.Completed,
+		OperatorType: operatorType,
+		OperatorID:   operatorID,
+		Reason:       "订单完成",
+		Updates:      &entity.Order{DeliveryTime: wrap.LocalTime(time.Now())},
 	}
-	return nil
+	return t, s.stateMachine.Transit(db, t)
 }
 
 // Reminder 用户催单
-func (s *OrderService) Reminder(id int) error {
-	order, err := s.orderDAO.GetByID(global.DB, id)
+func (s *OrderService) Reminder(ctx *gin.Context, id int) error {
+	order, _, err := s.userOrder(ctx, id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errs.New(constant.CodeBusinessError, constant.MsgOrderNotFound)
-		}
-		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		return err
 	}
 	m := map[string]any{"type": constant.UserRemind, "orderId": id, "content": "订单号：" + order.Number}
 	//js, err := json.Marshal(m)
 	//if err != nil {
 	//	return errs.Wrap(err, constant.CodeInternalError, constant.MsgMarshalFail)
 	//}
-	websocket.SendToAllClients(m)
+	websocket.SendToMerchant(m)
+	return nil
+}
+
+// ReleaseScheduled 将已到出餐提前期的预约单推送给商家
+func (s *OrderService) ReleaseScheduled() error {
+	orders, err := s.orderDAO.ListDueHeld(global.DB, time.Now())
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	for _, order := range orders {
+		rows, e := s.orderDAO.Release(global.DB, order.ID)
+		if e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if rows == 0 {
+			continue
+		}
+		content := "预约订单：" + order.Number + "，送达时间：" + order.EstimatedDeliveryTime.String()
+		websocket.SendToMerchant(map[string]any{"type": constant.NotifyOrder, "orderId": order.ID, "content": content})
+	}
 	return nil
 }
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/internal/dao"
+	"takeout/internal/websocket"
+	"takeout/model/entity"
+)
+
+// orderTransitions 订单允许的状态流转，key 为当前状态，value 为可以流转到的状态
+// 已完成、已取消为终态，不能再流转
+var orderTransitions = map[int][]int{
+	constant.PendingPayment:     {constant.ToBeConfirmed, constant.Cancelled},
+	constant.ToBeConfirmed:      {constant.Confirmed, constant.Cancelled},
+	constant.Confirmed:          {constant.DeliveryInProgress, constant.Cancelled},
+	constant.DeliveryInProgress: {constant.Completed, constant.Cancelled},
+}
+
+// orderStatusText 推送给用户的状态描述
+var orderStatusText = map[int]string{
+	constant.ToBeConfirmed:      "支付成功，等待商家接单",
+	constant.Confirmed:          "商家已接单，正在备餐",
+	constant.DeliveryInProgress: "订单正在配送中",
+	constant.Completed:          "订单已送达",
+	constant.Cancelled:          "订单已取消",
+}
+
+// CanTransit 判断订单能否从 from 状态流转到 to 状态
+func CanTransit(from, to int) bool {
+	for _, s := range orderTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderTransition 描述一次订单状态流转
+type OrderTransition struct {
+	Order        *entity.Order // 流转前的订单，至少需要 ID、UserID、Status 和 Amount
+	To           int           // 目标状态
+	OperatorType int           // 操作人类型
+	OperatorID   int           // 操作人ID，系统操作为 0
+	Reason       string        // 流转原因
+	Updates      *entity.Order // 随状态一起更新的其它字段，可以为 nil
+
+	from      int  // 流转前的状态，由 Transit 记录
+	restocked bool // 取消订单后是否有售罄菜品重新起售，由 Transit 记录
+}
+
+// OrderStateMachine 订单状态机，所有订单状态的修改都应该经过这里
+type OrderStateMachine struct {
+	orderDAO              dao.OrderDAO
+	orderStatusHistoryDAO dao.OrderStatusHistoryDAO
+	stockService          StockService
+	couponService         CouponService
+	pointsService         PointsService
+	kitchenService        KitchenService
+}
+
+// Transit 校验并执行状态流转
+// 使用 WHERE status = ? 条件更新，并发修改时只有一方能成功；同时写入状态流转历史
+// 商家接单时生成后厨工单，订单完成时发放积分，订单取消时撤下工单并归还库存、优惠券和积分
+// db 不是事务时流转成功后立即推送给用户；在外层事务中调用时，由调用方在事务提交后调用 Notify
+func (m *OrderStateMachine) Transit(db *gorm.DB, t *OrderTransition) error {
+	from := t.Order.Status
+	if !CanTransit(from, t.To) {
+		return errs.New(constant.CodeBusinessError, constant.MsgOrderStatusError)
+	}
+	updates := t.Updates
+	if updates == nil {
+		updates = &entity.Order{}
+	}
+	updates.ID = t.Order.ID
+	updates.Status = t.To
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		rows, e := m.orderDAO.UpdateStatus(tx, from, updates)
+		if e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if rows == 0 {
+			// 订单状态已被其他操作修改
+			return errs.New(constant.CodeBusinessError, constant.MsgOrderStatusChanged)
+		}
+		history := &entity.OrderStatusHistory{
+			OrderID:      t.Order.ID,
+			FromStatus:   from,
+			ToStatus:     t.To,
+			OperatorType: t.OperatorType,
+			OperatorID:   t.OperatorID,
+			Reason:       t.Reason,
+		}
+		if e = m.orderStatusHistoryDAO.Insert(tx, history); e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if t.To == constant.Cancelled {
+			if t.restocked, e = m.stockService.Restore(tx, t.Order.ID); e != nil {
+				return e
+			}
+			if e = m.couponService.Return(tx, t.Order.ID); e != nil {
+				return e
+			}
+			if e = m.kitchenService.Discard(tx, t.Order.ID); e != nil {
+				return e
+			}
+			return m.pointsService.Refund(tx, t.Order)
+		}
+		if t.To == constant.Confirmed {
+			return m.kitchenService.Explode(tx, t.Order)
+		}
+		if t.To == constant.Completed {
+			return m.pointsService.Earn(tx, t.Order)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	t.from = from
+	t.Order.Status = t.To
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); !inTx {
+		m.Notify(t)
+	}
+	return nil
+}
+
+// Notify 向下单用户推送订单状态变更，消息格式与商家端的来单提醒一致
+// 订单进入或撤出后厨时同时通知后厨屏幕；取消订单归还库存后清除菜品缓存
+func (m *OrderStateMachine) Notify(t *OrderTransition) {
+	if t.restocked {
+		m.stockService.cleanCache()
+	}
+	switch {
+	case t.To == constant.Confirmed:
+		m.kitchenService.notifyOrder(t.Order, "新的后厨工单")
+	case t.To == constant.Cancelled && t.from >= constant.Confirmed:
+		m.kitchenService.notifyOrder(t.Order, "订单已取消，工单已撤下")
+	}
+	if t.Order.UserID == 0 {
+		return
+	}
+	websocket.SendToUser(t.Order.UserID, map[string]any{
+		"type":                  constant.OrderChange,
+		"orderId":               t.Order.ID,
+		"content":               orderStatusText[t.To],
+		"status":                t.To,
+		"reason":                t.Reason,
+		"estimatedDeliveryTime": t.Order.EstimatedDeliveryTime,
+	})
+}
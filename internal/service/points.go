@@ -0,0 +1,278 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/common/utils"
+	"takeout/internal/dao"
+	"takeout/model/dto"
+	"takeout/model/entity"
+	"takeout/model/vo"
+)
+
+// PointsService 会员积分服务，余额保存在 user.points，每次变动都锁定用户行并写入流水
+type PointsService struct {
+	pointsDAO dao.PointsDAO
+	orderDAO  dao.OrderDAO
+}
+
+// memberTier 用户当前的会员等级
+type memberTier struct {
+	spend   decimal.Decimal
+	current *global.TierConfig // 未配置等级时为 nil
+	next    *global.TierConfig // 已是最高等级时为 nil
+}
+
+// multiplier 积分倍率，未配置等级时为 1
+func (t *memberTier) multiplier() float64 {
+	if t.current == nil || t.current.Multiplier <= 0 {
+		return 1
+	}
+	return t.current.Multiplier
+}
+
+// Info 查询积分余额和会员等级进度
+func (s *PointsService) Info(ctx *gin.Context) (*vo.PointsInfoVO, error) {
+	userID, err := utils.GetId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var user entity.User
+	if err = global.DB.Select("id", "points").Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.New(constant.CodeNotFound, constant.MsgUserNotFound)
+		}
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	tier, err := s.tier(global.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+	config := global.Config.Points
+	info := &vo.PointsInfoVO{
+		Points:         user.Points,
+		RedeemRate:     config.RedeemRate,
+		MaxRedeemRatio: config.MaxRedeemRatio,
+		Multiplier:     tier.multiplier(),
+		Spend:          tier.spend,
+		WindowDays:     config.TierWindowDays,
+	}
+	if tier.current != nil {
+		info.Tier = tier.current.Name
+	}
+	if tier.next != nil {
+		info.NextTier = tier.next.Name
+		info.NextTierSpend = decimal.NewFromFloat(tier.next.MinSpend)
+		info.Remaining = info.NextTierSpend.Sub(tier.spend)
+	}
+	return info, nil
+}
+
+// Page 用户分页查询自己的积分流水
+func (s *PointsService) Page(ctx *gin.Context, queryDTO *dto.PointsPageQueryDTO) (*vo.PageResult, error) {
+	userID, err := utils.GetId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	queryDTO.UserID = userID
+	return s.PageQuery(queryDTO)
+}
+
+// PageQuery 商家分页查询积分流水
+func (s *PointsService) PageQuery(queryDTO *dto.PointsPageQueryDTO) (*vo.PageResult, error) {
+	total, list, err := s.pointsDAO.PageByUser(global.DB, queryDTO.UserID, queryDTO.Page, queryDTO.PageSize)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return &vo.PageResult{Total: total, Records: list}, nil
+}
+
+// Adjust 商家人工调整积分，扣减后余额不能为负
+func (s *PointsService) Adjust(ctx *gin.Context, adjustDTO *dto.PointsAdjustDTO) error {
+	empID, err := utils.GetId(ctx)
+	if err != nil {
+		return err
+	}
+	reason := strings.TrimSpace(adjustDTO.Reason)
+	if reason == "" {
+		return errs.New(constant.CodeBadRequest, constant.MsgPointsReasonMissing)
+	}
+	return global.DB.Transaction(func(tx *gorm.DB) error {
+		log := &entity.PointsLog{
+			UserID:     adjustDTO.UserID,
+			Type:       constant.PointsAdjust,
+			Change:     adjustDTO.Change,
+			Reason:     reason,
+			OperatorID: empID,
+		}
+		return s.change(tx, log)
+	})
+}
+
+// Apply 校验下单抵扣的积分并从应付金额中扣除，此时只检查余额，订单创建后由 Use 扣减
+func (s *PointsService) Apply(db *gorm.DB, userID, points int, price *OrderPrice) (decimal.Decimal, error) {
+	config := global.Config.Points
+	if config.RedeemRate <= 0 || config.MaxRedeemRatio <= 0 {
+		return decimal.Zero, errs.New(constant.CodeBusinessError, constant.MsgPointsDisabled)
+	}
+	if points <= 0 {
+		return decimal.Zero, errs.New(constant.CodeBadRequest, constant.MsgPointsInvalid)
+	}
+	var user entity.User
+	if err := db.Select("id", "points").Where("id = ?", userID).First(&user).Error; err != nil {
+		return decimal.Zero, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if user.Points < points {
+		return decimal.Zero, errs.New(constant.CodeBusinessError, constant.MsgPointsNotEnough)
+	}
+	// 抵扣金额精确到分，不足一分的积分不能使用
+	amount := decimal.NewFromInt(int64(points)).Div(decimal.NewFromInt(int64(config.RedeemRate))).Truncate(2)
+	limit := price.Amount.Mul(decimal.NewFromFloat(config.MaxRedeemRatio)).Truncate(2)
+	if amount.IsZero() || amount.GreaterThan(limit) {
+		return decimal.Zero, errs.New(constant.CodeBusinessError, constant.MsgPointsExceed)
+	}
+	price.ApplyDiscount(amount)
+	return amount, nil
+}
+
+// Use 订单创建后扣减抵扣的积分，需要在下单事务中调用
+func (s *PointsService) Use(db *gorm.DB, userID, points, orderID int) error {
+	return s.change(db, &entity.PointsLog{
+		UserID:  userID,
+		Type:    constant.PointsRedeem,
+		Change:  -points,
+		OrderID: orderID,
+		Reason:  "下单抵扣",
+	})
+}
+
+// Earn 订单完成后按实付金额和会员等级倍率发放积分，需要在状态流转事务中调用
+func (s *PointsService) Earn(db *gorm.DB, order *entity.Order) error {
+	ratio := global.Config.Points.EarnRatio
+	if ratio <= 0 || order.UserID == 0 {
+		return nil
+	}
+	tier, err := s.tier(db, order.UserID)
+	if err != nil {
+		return err
+	}
+	points := order.Amount.Mul(decimal.NewFromFloat(ratio * tier.multiplier())).IntPart()
+	if points <= 0 {
+		return nil
+	}
+	return s.change(db, &entity.PointsLog{
+		UserID:  order.UserID,
+		Type:    constant.PointsEarn,
+		Change:  int(points),
+		OrderID: order.ID,
+		Reason:  "订单完成",
+	})
+}
+
+// Refund 订单退款时退还抵扣的积分，并扣回该订单获得的积分，余额不足时扣到 0 为止
+// 需要在状态流转事务中调用
+func (s *PointsService) Refund(db *gorm.DB, order *entity.Order) error {
+	redeemed, err := s.pointsDAO.SumByOrder(db, order.ID, constant.PointsRedeem)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	returned, err := s.pointsDAO.SumByOrder(db, order.ID, constant.PointsReturn)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	earned, err := s.pointsDAO.SumByOrder(db, order.ID, constant.PointsEarn)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	reversed, err := s.pointsDAO.SumByOrder(db, order.ID, constant.PointsReverse)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+
+	// 抵扣记录为负数，退还记录为正数
+	if back := -redeemed - returned; back > 0 {
+		err = s.change(db, &entity.PointsLog{
+			UserID:  order.UserID,
+			Type:    constant.PointsReturn,
+			Change:  back,
+			OrderID: order.ID,
+			Reason:  "订单退款退还",
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if take := earned + reversed; take > 0 {
+		balance, e := s.pointsDAO.GetBalanceForUpdate(db, order.UserID)
+		if e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		take = min(take, balance)
+		if take == 0 {
+			return nil
+		}
+		return s.change(db, &entity.PointsLog{
+			UserID:  order.UserID,
+			Type:    constant.PointsReverse,
+			Change:  -take,
+			OrderID: order.ID,
+			Reason:  "订单退款扣回",
+		})
+	}
+	return nil
+}
+
+// change 锁定用户行后修改余额并写入流水，余额不能为负
+func (s *PointsService) change(db *gorm.DB, log *entity.PointsLog) error {
+	if log.Change == 0 {
+		return errs.New(constant.CodeBadRequest, constant.MsgPointsInvalid)
+	}
+	balance, err := s.pointsDAO.GetBalanceForUpdate(db, log.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.New(constant.CodeNotFound, constant.MsgUserNotFound)
+		}
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	balance += log.Change
+	if balance < 0 {
+		return errs.New(constant.CodeBusinessError, constant.MsgPointsNotEnough)
+	}
+	if err = s.pointsDAO.UpdateBalance(db, log.UserID, balance); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	log.Balance = balance
+	if err = s.pointsDAO.CreateLog(db, log); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// tier 按最近 tier_window_days 天完成订单的实付金额计算会员等级
+func (s *PointsService) tier(db *gorm.DB, userID int) (*memberTier, error) {
+	config := global.Config.Points
+	since := time.Now().AddDate(0, 0, -config.TierWindowDays)
+	spend, err := s.orderDAO.GetUserSpend(db, userID, since)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	tier := &memberTier{spend: spend}
+	for i := range config.Tiers {
+		t := &config.Tiers[i]
+		if spend.GreaterThanOrEqual(decimal.NewFromFloat(t.MinSpend)) {
+			tier.current = t
+			continue
+		}
+		tier.next = t
+		break
+	}
+	return tier, nil
+}
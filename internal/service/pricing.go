@@ -0,0 +1,145 @@
+package service
+
+import (
+	"errors"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/internal/dao"
+	"takeout/model/entity"
+)
+
+// LinePrice 购物车中一行商品的价格明细
+type LinePrice struct {
+	Cart       *entity.ShoppingCart
+	CategoryID int // 商品所属分类，用于判断优惠券适用范围
+	Name       string
+	Image      string
+	Flavor     *FlavorChoice   // 按菜品当前口味组校验后的口味，套餐为 nil
+	UnitPrice  decimal.Decimal // 商品当前单价，含口味加价
+	PackAmount decimal.Decimal // 该行打包费
+	Amount     decimal.Decimal // 该行小计 = 单价 * 数量 + 打包费
+}
+
+// OrderPrice 订单价格明细
+type OrderPrice struct {
+	Lines           []*LinePrice
+	GoodsAmount     decimal.Decimal // 商品金额
+	PackAmount      decimal.Decimal // 打包费
+	TablewareAmount decimal.Decimal // 餐具费
+	DeliveryFee     decimal.Decimal // 配送费
+	DiscountAmount  decimal.Decimal // 优惠金额
+	Amount          decimal.Decimal // 应付金额
+}
+
+// ApplyDiscount 从应付金额中扣除优惠
+func (p *OrderPrice) ApplyDiscount(discount decimal.Decimal) {
+	p.DiscountAmount = p.DiscountAmount.Add(discount).Round(2)
+	p.Amount = p.Amount.Sub(discount).Round(2)
+}
+
+// SetDeliveryFee 按配送区域替换配送费
+func (p *OrderPrice) SetDeliveryFee(fee decimal.Decimal) {
+	p.Amount = p.Amount.Sub(p.DeliveryFee).Add(fee).Round(2)
+	p.DeliveryFee = fee.Round(2)
+}
+
+// PriceCalculator 订单计价器，以商品的当前价格为准计算订单金额
+type PriceCalculator struct {
+	dishDAO        dao.DishDAO
+	setmealDAO     dao.SetmealDAO
+	setmealDishDAO dao.SetmealDishDAO
+	flavorService  FlavorService
+}
+
+// Calculate 计算购物车的订单金额，商品已删除或停售时返回业务错误
+func (c *PriceCalculator) Calculate(db *gorm.DB, cartList []*entity.ShoppingCart, tablewareStatus, tablewareNumber int) (*OrderPrice, error) {
+	shop := global.Config.Shop
+	packFee := decimal.NewFromFloat(shop.PackFee)
+	price := &OrderPrice{
+		DeliveryFee: decimal.NewFromFloat(shop.DeliveryFee).Round(2),
+	}
+	itemCount := 0
+	for _, cart := range cartList {
+		line, err := c.linePrice(db, cart, packFee)
+		if err != nil {
+			return nil, err
+		}
+		price.Lines = append(price.Lines, line)
+		price.GoodsAmount = price.GoodsAmount.Add(line.UnitPrice.Mul(decimal.NewFromInt(int64(cart.Number))))
+		price.PackAmount = price.PackAmount.Add(line.PackAmount)
+		itemCount += cart.Number
+	}
+
+	// 按餐量提供时餐具数量等于商品份数
+	if tablewareStatus == constant.TablewareByMeal {
+		tablewareNumber = itemCount
+	}
+	price.TablewareAmount = decimal.NewFromFloat(shop.TablewareFee).Mul(decimal.NewFromInt(int64(tablewareNumber))).Round(2)
+
+	price.Amount = price.GoodsAmount.Add(price.PackAmount).Add(price.TablewareAmount).Add(price.DeliveryFee).Round(2)
+	return price, nil
+}
+
+// Line 按商品当前价格计算购物车中一行的价格，商品已删除或停售时返回业务错误
+func (c *PriceCalculator) Line(db *gorm.DB, cart *entity.ShoppingCart) (*LinePrice, error) {
+	return c.linePrice(db, cart, decimal.NewFromFloat(global.Config.Shop.PackFee))
+}
+
+// 计算单行商品的价格
+func (c *PriceCalculator) linePrice(db *gorm.DB, cart *entity.ShoppingCart, packFee decimal.Decimal) (*LinePrice, error) {
+	line := &LinePrice{Cart: cart}
+	if cart.DishID != 0 {
+		dish, err := c.dishDAO.GetByID(db, cart.DishID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errs.New(constant.CodeBusinessError, constant.MsgGoodsUnavailable+"："+cart.Name)
+			}
+			return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if dish.Status != constant.DishEnable {
+			return nil, errs.New(constant.CodeBusinessError, constant.MsgGoodsUnavailable+"："+dish.Name)
+		}
+		// 按当前口味组重新计算口味加价，所选口味已删除时不能下单
+		groups, err := c.flavorService.Groups(db, dish.ID)
+		if err != nil {
+			return nil, err
+		}
+		line.Flavor, err = c.flavorService.ResolveCart(groups, cart)
+		if err != nil {
+			return nil, err
+		}
+		line.CategoryID, line.Name, line.Image = dish.CategoryID, dish.Name, dish.Image
+		line.UnitPrice = dish.Price.Add(line.Flavor.Surcharge)
+	} else {
+		setmeal, err := c.setmealDAO.GetByID(db, cart.SetmealID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errs.New(constant.CodeBusinessError, constant.MsgGoodsUnavailable+"："+cart.Name)
+			}
+			return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if setmeal.Status != constant.SetmealEnable {
+			return nil, errs.New(constant.CodeBusinessError, constant.MsgGoodsUnavailable+"："+setmeal.Name)
+		}
+		// 套餐是否可售取决于所含菜品，任一菜品停售或售罄则套餐不可售
+		dishIDs, err := c.setmealDishDAO.GetDishIdsBySetmealId(db, setmeal.ID)
+		if err != nil {
+			return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		halt, err := c.dishDAO.CountHaltSales(db, dishIDs)
+		if err != nil {
+			return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if halt > 0 {
+			return nil, errs.New(constant.CodeBusinessError, constant.MsgGoodsUnavailable+"："+setmeal.Name)
+		}
+		line.CategoryID, line.Name, line.Image, line.UnitPrice = setmeal.CategoryID, setmeal.Name, setmeal.Image, setmeal.Price
+	}
+	number := decimal.NewFromInt(int64(cart.Number))
+	line.PackAmount = packFee.Mul(number).Round(2)
+	line.Amount = line.UnitPrice.Mul(number).Add(line.PackAmount).Round(2)
+	return line, nil
+}
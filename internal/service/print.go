@@ -0,0 +1,205 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/common/logger"
+	"takeout/common/printer"
+	"takeout/internal/dao"
+	"takeout/model/entity"
+	"takeout/model/wrap"
+)
+
+// PrintService 小票打印，接单后生成顾客小票和后厨单，发送失败的任务由定时任务重试
+type PrintService struct {
+	printJobDAO    dao.PrintJobDAO
+	orderDAO       dao.OrderDAO
+	orderDetailDAO dao.OrderDetailDAO
+}
+
+// Enqueue 渲染订单的打印内容并保存为待打印任务，printType 为 0 时同时生成小票和后厨单
+func (s *PrintService) Enqueue(db *gorm.DB, order *entity.Order, printType int) ([]*entity.PrintJob, error) {
+	details, err := s.orderDetailDAO.GetByOrderID(db, order.ID)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	jobs := make([]*entity.PrintJob, 0, 2)
+	if printType == 0 || printType == constant.PrintReceipt {
+		jobs = append(jobs, &entity.PrintJob{
+			OrderID:     order.ID,
+			OrderNumber: order.Number,
+			Type:        constant.PrintReceipt,
+			Content:     renderReceipt(order, details),
+			Status:      constant.PrintPending,
+		})
+	}
+	if printType == 0 || printType == constant.PrintKitchen {
+		jobs = append(jobs, &entity.PrintJob{
+			OrderID:     order.ID,
+			OrderNumber: order.Number,
+			Type:        constant.PrintKitchen,
+			Content:     renderKitchen(order, details),
+			Status:      constant.PrintPending,
+		})
+	}
+	if err = s.printJobDAO.BatchInsert(db, jobs); err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return jobs, nil
+}
+
+// Send 把打印任务发送到打印机并记录结果
+func (s *PrintService) Send(job *entity.PrintJob) error {
+	from := []int{constant.PrintPending, constant.PrintFailed}
+	update := &entity.PrintJob{ID: job.ID, RetryCount: job.RetryCount + 1}
+	printErr := printer.GetPrinter().Print(job.Content)
+	if printErr != nil {
+		update.Status = constant.PrintFailed
+		update.FailReason = printErr.Error()
+	} else {
+		update.Status = constant.PrintSucceeded
+		update.PrintTime = wrap.LocalTime(time.Now())
+	}
+	if _, err := s.printJobDAO.UpdateStatus(global.DB, from, update); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if printErr != nil {
+		return errs.Wrap(printErr, constant.CodeInternalError, constant.MsgPrintFail)
+	}
+	return nil
+}
+
+// SendAll 依次发送打印任务，返回第一个错误，失败的任务不影响后面的任务
+func (s *PrintService) SendAll(jobs []*entity.PrintJob) error {
+	var result error
+	for _, job := range jobs {
+		if err := s.Send(job); err != nil {
+			logger.Error(constant.MsgPrintFail, zap.Int("jobId", job.ID), zap.Error(err))
+			if result == nil {
+				result = err
+			}
+		}
+	}
+	return result
+}
+
+// Reprint 商家补打小票，只能补打已接单且未取消的订单
+func (s *PrintService) Reprint(id, printType int) error {
+	order, err := s.orderDAO.GetByID(global.DB, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.Wrap(err, constant.CodeBusinessError, constant.MsgOrderNotFound)
+		}
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if order.Status < constant.Confirmed || order.Status == constant.Cancelled {
+		return errs.New(constant.CodeBusinessError, constant.MsgPrintStatusError)
+	}
+	jobs, err := s.Enqueue(global.DB, order, printType)
+	if err != nil {
+		return err
+	}
+	return s.SendAll(jobs)
+}
+
+// RetryFailed 重新发送打印失败的任务，以及发送过程中服务重启而一直停留在待打印的任务
+func (s *PrintService) RetryFailed() error {
+	jobs, err := s.printJobDAO.ListRetry(global.DB, []int{constant.PrintPending, constant.PrintFailed},
+		time.Now().Add(-time.Minute), constant.PrintMaxRetry)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	_ = s.SendAll(jobs)
+	return nil
+}
+
+// renderReceipt 顾客小票：订单明细、金额、备注、餐具、收货信息和订单号二维码
+func renderReceipt(order *entity.Order, details []*entity.OrderDetail) []byte {
+	config := global.Config.Printer
+	b := printer.NewBuilder(config.Width)
+	b.Align(printer.AlignCenter).Size(2).Line(config.Title).Size(1).Line("顾客联")
+	b.Align(printer.AlignLeft).Divider("=")
+	b.Bold(true).Line("订单号：" + order.Number).Bold(false)
+	b.Line("下单时间：" + formatTime(order.OrderTime))
+	if !time.Time(order.EstimatedDeliveryTime).IsZero() {
+		b.Line("预计送达：" + formatTime(order.EstimatedDeliveryTime))
+	}
+	b.Divider("-")
+	for _, detail := range details {
+		b.Columns(fmt.Sprintf("%s x%d", detail.Name, detail.Number), detail.TotalAmount.StringFixed(2))
+		if detail.DishFlavor != "" {
+			b.Line("  " + detail.DishFlavor)
+		}
+	}
+	b.Divider("-")
+	b.Columns("商品金额", order.GoodsAmount.StringFixed(2))
+	if !order.PackAmount.IsZero() {
+		b.Columns("打包费", order.PackAmount.StringFixed(2))
+	}
+	b.Columns("配送费", order.DeliveryFee.StringFixed(2))
+	if !order.TablewareAmount.IsZero() {
+		b.Columns("餐具费", order.TablewareAmount.StringFixed(2))
+	}
+	if !order.DiscountAmount.IsZero() {
+		b.Columns("优惠", "-"+order.DiscountAmount.StringFixed(2))
+	}
+	b.Bold(true).Columns("实付", order.Amount.StringFixed(2)).Bold(false)
+	b.Divider("-")
+	if order.Remark != "" {
+		b.Bold(true).Line("备注：" + order.Remark).Bold(false)
+	}
+	b.Line("餐具：" + tablewareText(order))
+	b.Divider("-")
+	b.Line(order.Consignee + "  " + order.Phone)
+	b.Line(order.Address)
+	b.Feed(1).Align(printer.AlignCenter).QRCode(order.Number, 6).Line(order.Number)
+	return b.Feed(3).Cut().Bytes()
+}
+
+// renderKitchen 后厨单：只保留制作需要的信息，菜品和备注放大打印
+func renderKitchen(order *entity.Order, details []*entity.OrderDetail) []byte {
+	config := global.Config.Printer
+	b := printer.NewBuilder(config.Width)
+	b.Align(printer.AlignCenter).Size(2).Line("后厨单").Size(1)
+	b.Align(printer.AlignLeft).Divider("=")
+	b.Bold(true).Line("订单号：" + order.Number).Bold(false)
+	b.Line("下单时间：" + formatTime(order.OrderTime))
+	if !time.Time(order.EstimatedDeliveryTime).IsZero() {
+		b.Line("预计送达：" + formatTime(order.EstimatedDeliveryTime))
+	}
+	b.Divider("-")
+	for _, detail := range details {
+		b.Size(2).Line(fmt.Sprintf("%s x%d", detail.Name, detail.Number)).Size(1)
+		if detail.DishFlavor != "" {
+			b.Line("  " + detail.DishFlavor)
+		}
+	}
+	b.Divider("-")
+	if order.Remark != "" {
+		b.Size(2).Line("备注：" + order.Remark).Size(1)
+	}
+	b.Line("餐具：" + tablewareText(order))
+	b.Feed(1).Align(printer.AlignCenter).QRCode(order.Number, 6).Line(order.Number)
+	return b.Feed(3).Cut().Bytes()
+}
+
+func formatTime(t wrap.LocalTime) string {
+	return time.Time(t).Format(wrap.TimeFormat)
+}
+
+func tablewareText(order *entity.Order) string {
+	if order.TablewareStatus == constant.TablewareByMeal {
+		return "按餐量提供"
+	}
+	if order.TablewareNumber == 0 {
+		return "不需要"
+	}
+	return fmt.Sprintf("%d 份", order.TablewareNumber)
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/common/logger"
+	"takeout/common/payment"
+	"takeout/common/utils"
+	"takeout/internal/dao"
+	"takeout/internal/websocket"
+	"takeout/model/dto"
+	"takeout/model/entity"
+	"takeout/model/vo"
+	"takeout/model/wrap"
+	"time"
+)
+
+// RefundService 退款服务
+type RefundService struct {
+	refundDAO dao.RefundDAO
+	orderDAO  dao.OrderDAO
+}
+
+// Create 为已支付的订单创建全额退款单，需要与取消订单放在同一个事务中
+func (s *RefundService) Create(db *gorm.DB, order *entity.Order, reason string) (*entity.Refund, error) {
+	// 退款单号加上前缀与订单号区分
+	number, err := utils.NextSerialNumber("refund", "RF")
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	refund := &entity.Refund{
+		Number:      number,
+		OrderID:     order.ID,
+		OrderNumber: order.Number,
+		Amount:      order.Amount,
+		Total:       order.Amount,
+		Status:      constant.RefundRequested,
+		Reason:      reason,
+	}
+	if err := s.refundDAO.Insert(db, refund); err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return refund, nil
+}
+
+// Submit 向支付平台提交退款申请，提交失败的退款单由定时任务重试
+func (s *RefundService) Submit(refund *entity.Refund) error {
+	from := []int{constant.RefundRequested, constant.RefundProcessing, constant.RefundFailed}
+	update := &entity.Refund{ID: refund.ID, RetryCount: refund.RetryCount + 1}
+	submitErr := payment.GetProvider().Refund(&payment.RefundRequest{
+		OrderNumber:  refund.OrderNumber,
+		RefundNumber: refund.Number,
+		Refund:       refund.Amount,
+		Total:        refund.Total,
+	})
+	if submitErr != nil {
+		update.Status = constant.RefundFailed
+		update.FailReason = submitErr.Error()
+	} else {
+		update.Status = constant.RefundProcessing
+	}
+	if _, err := s.refundDAO.UpdateStatus(global.DB, from, update); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if submitErr != nil {
+		// 自动重试到此为止，之后只能由商家在退款列表中重新提交
+		if update.RetryCount == constant.RefundMaxRetry {
+			s.alertExhausted(refund, submitErr)
+		}
+		return errs.Wrap(submitErr, constant.CodeInternalError, constant.MsgRefundFail)
+	}
+	return nil
+}
+
+// alertExhausted 退款重试次数用尽时记录错误日志并通知商家
+func (s *RefundService) alertExhausted(refund *entity.Refund, submitErr error) {
+	logger.Error(constant.MsgRefundExhausted,
+		zap.String("refundNumber", refund.Number),
+		zap.String("orderNumber", refund.OrderNumber),
+		zap.String("amount", refund.Amount.StringFixed(2)),
+		zap.Error(submitErr))
+	websocket.SendToMerchant(map[string]any{
+		"type":     constant.RefundAlert,
+		"orderId":  refund.OrderID,
+		"refundId": refund.ID,
+		"content":  constant.MsgRefundExhausted + "：" + refund.OrderNumber,
+	})
+}
+
+// PageQuery 商家分页查询退款单，用于查找失败或长时间未完成的退款
+func (s *RefundService) PageQuery(queryDTO *dto.RefundPageQueryDTO) (*vo.PageResult, error) {
+	total, list, err := s.refundDAO.Page(global.DB, queryDTO)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return &vo.PageResult{Total: total, Records: list}, nil
+}
+
+// Resubmit 商家手动重新提交退款，不受自动重试次数限制
+// 退款单号不变，支付平台对同一退款单号的重复申请是幂等的，处理中的退款单也可以重新提交
+func (s *RefundService) Resubmit(id int) error {
+	refund, err := s.refundDAO.GetByID(global.DB, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.New(constant.CodeNotFound, constant.MsgRefundNotFound)
+		}
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if refund.Status == constant.RefundSucceeded {
+		return errs.New(constant.CodeBusinessError, constant.MsgRefundStatusError)
+	}
+	return s.Submit(refund)
+}
+
+// Callback 处理支付平台的退款回调，成功时退款单和订单支付状态一起改为已退款
+func (s *RefundService) Callback(number string, success bool, failReason string) error {
+	refund, err := s.refundDAO.GetByNumber(global.DB, number)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.Wrap(err, constant.CodeBusinessError, constant.MsgRefundNotFound)
+		}
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	// 回调可能重复推送，已成功的退款单直接返回
+	if refund.Status == constant.RefundSucceeded {
+		return nil
+	}
+	from := []int{constant.RefundRequested, constant.RefundProcessing, constant.RefundFailed}
+	if !success {
+		_, err = s.refundDAO.UpdateStatus(global.DB, from, &entity.Refund{
+			ID:         refund.ID,
+			Status:     constant.RefundFailed,
+			FailReason: failReason,
+		})
+		if err != nil {
+			return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		return nil
+	}
+	return global.DB.Transaction(func(tx *gorm.DB) error {
+		rows, e := s.refundDAO.UpdateStatus(tx, from, &entity.Refund{
+			ID:          refund.ID,
+			Status:      constant.RefundSucceeded,
+			SuccessTime: wrap.LocalTime(time.Now()),
+		})
+		if e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if rows == 0 {
+			return nil
+		}
+		if e = s.orderDAO.Update(tx, &entity.Order{ID: refund.OrderID, PayStatus: constant.ReFund}); e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		return nil
+	})
+}
+
+// RetryStuck 重新提交卡住的退款单：未提交、提交失败或长时间没有收到回调
+func (s *RefundService) RetryStuck() error {
+	now := time.Now()
+	pending, err := s.refundDAO.ListStuck(global.DB,
+		[]int{constant.RefundRequested, constant.RefundFailed}, now.Add(-time.Minute), constant.RefundMaxRetry)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	processing, err := s.refundDAO.ListStuck(global.DB,
+		[]int{constant.RefundProcessing}, now.Add(-30*time.Minute), constant.RefundMaxRetry)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	// 退款单号不变，支付平台对同一退款单号的重复申请是幂等的
+	for _, refund := range append(pending, processing...) {
+		if e := s.Submit(refund); e != nil {
+			logger.Error(constant.MsgRefundFail, zap.String("refundNumber", refund.Number), zap.Error(e))
+		}
+	}
+	return nil
+}
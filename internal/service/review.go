@@ -0,0 +1,313 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/common/utils"
+	"takeout/internal/dao"
+	"takeout/model/dto"
+	"takeout/model/entity"
+	"takeout/model/vo"
+)
+
+// ReviewService 订单评价服务
+type ReviewService struct {
+	reviewDAO      dao.ReviewDAO
+	orderDAO       dao.OrderDAO
+	orderDetailDAO dao.OrderDetailDAO
+}
+
+// Submit 用户评价已完成的订单，每个订单只能评价一次，需在完成后 shop.review_days 天内评价
+func (s *ReviewService) Submit(ctx *gin.Context, reviewDTO *dto.ReviewDTO) error {
+	userID, err := utils.GetId(ctx)
+	if err != nil {
+		return err
+	}
+	order, err := s.orderDAO.GetByID(global.DB, reviewDTO.OrderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.New(constant.CodeBusinessError, constant.MsgReviewNotAllowed)
+		}
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if order.UserID != userID || order.Status != constant.Completed {
+		return errs.New(constant.CodeBusinessError, constant.MsgReviewNotAllowed)
+	}
+	// 订单完成时间记录在送达时间中
+	deadline := order.DeliveryTime.Time().AddDate(0, 0, global.Config.Shop.ReviewDays)
+	if time.Now().After(deadline) {
+		return errs.New(constant.CodeBusinessError, constant.MsgReviewExpired)
+	}
+
+	if !validRating(reviewDTO.Rating) {
+		return errs.New(constant.CodeBadRequest, constant.MsgReviewRatingInvalid)
+	}
+	if !validImages(reviewDTO.Images) {
+		return errs.New(constant.CodeBadRequest, constant.MsgReviewImageInvalid)
+	}
+	images, err := json.Marshal(reviewDTO.Images)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeInternalError, constant.MsgMarshalFail)
+	}
+	review := &entity.Review{
+		OrderID: order.ID,
+		UserID:  userID,
+		Rating:  reviewDTO.Rating,
+		Content: strings.TrimSpace(reviewDTO.Content),
+		Images:  string(images),
+		Status:  constant.ReviewShow,
+	}
+
+	details, err := s.orderDetailDAO.GetByOrderID(global.DB, order.ID)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	detailMap := make(map[int]*entity.OrderDetail, len(details))
+	for _, detail := range details {
+		detailMap[detail.ID] = detail
+	}
+	items := make([]*entity.ReviewItem, 0, len(reviewDTO.Items))
+	for _, itemDTO := range reviewDTO.Items {
+		detail, ok := detailMap[itemDTO.OrderDetailID]
+		if !ok {
+			return errs.New(constant.CodeBadRequest, constant.MsgReviewItemInvalid)
+		}
+		// 同一条明细只能评一次
+		delete(detailMap, itemDTO.OrderDetailID)
+		if !validRating(itemDTO.Rating) {
+			return errs.New(constant.CodeBadRequest, constant.MsgReviewRatingInvalid)
+		}
+		items = append(items, &entity.ReviewItem{
+			OrderID:       order.ID,
+			OrderDetailID: detail.ID,
+			DishID:        detail.DishID,
+			SetmealID:     detail.SetmealID,
+			Name:          detail.Name,
+			Rating:        itemDTO.Rating,
+			Content:       strings.TrimSpace(itemDTO.Content),
+			Status:        constant.ReviewShow,
+		})
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		return s.reviewDAO.Create(tx, review, items)
+	})
+	if err != nil {
+		// 并发提交时由唯一索引兜底
+		if strings.Contains(err.Error(), constant.MsgKeyDuplicateError) {
+			return errs.Wrap(err, constant.CodeBusinessError, constant.MsgReviewExists)
+		}
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// GetByOrder 用户查看自己订单的评价，未评价时返回 nil
+func (s *ReviewService) GetByOrder(ctx *gin.Context, orderID int) (*vo.ReviewVO, error) {
+	userID, err := utils.GetId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	review, err := s.reviewDAO.GetByOrderID(global.DB, orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if review.UserID != userID {
+		return nil, nil
+	}
+	list, err := s.toVOs([]*entity.Review{review})
+	if err != nil {
+		return nil, err
+	}
+	return list[0], nil
+}
+
+// PageQuery 商家分页查询评价
+func (s *ReviewService) PageQuery(queryDTO *dto.ReviewPageQueryDTO) (*vo.PageResult, error) {
+	total, reviews, err := s.reviewDAO.PageQuery(global.DB, queryDTO)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	list, err := s.toVOs(reviews)
+	if err != nil {
+		return nil, err
+	}
+	return &vo.PageResult{Total: total, Records: list}, nil
+}
+
+// Reply 商家回复评价
+func (s *ReviewService) Reply(ctx *gin.Context, replyDTO *dto.ReviewReplyDTO) error {
+	empID, err := utils.GetId(ctx)
+	if err != nil {
+		return err
+	}
+	reply := strings.TrimSpace(replyDTO.Reply)
+	if reply == "" {
+		return errs.New(constant.CodeBadRequest, constant.MsgBadRequest)
+	}
+	rows, err := s.reviewDAO.Reply(global.DB, replyDTO.ID, reply, empID)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if rows == 0 {
+		return errs.New(constant.CodeNotFound, constant.MsgReviewNotFound)
+	}
+	return nil
+}
+
+// UpdateStatus 显示或隐藏评价，隐藏的评价不再计入菜品评分
+func (s *ReviewService) UpdateStatus(id, status int) error {
+	return global.DB.Transaction(func(tx *gorm.DB) error {
+		rows, err := s.reviewDAO.UpdateStatus(tx, id, status)
+		if err != nil {
+			return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if rows == 0 {
+			// 状态未变化时 RowsAffected 也为 0，需要区分评价不存在
+			if _, err = s.reviewDAO.GetByID(tx, id); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errs.New(constant.CodeNotFound, constant.MsgReviewNotFound)
+				}
+				return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+			}
+		}
+		return nil
+	})
+}
+
+// DishRatings 查询菜品的评分汇总，按菜品ID索引
+func (s *ReviewService) DishRatings(dishIDs []int) (map[int]*vo.DishRatingVO, error) {
+	list, err := s.reviewDAO.DishRatings(global.DB, dishIDs)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	ratings := make(map[int]*vo.DishRatingVO, len(list))
+	for _, rating := range list {
+		ratings[rating.DishID] = rating
+	}
+	return ratings, nil
+}
+
+// LowRatedDishes 低分菜品报表
+func (s *ReviewService) LowRatedDishes(queryDTO *dto.LowRatedQueryDTO) ([]*vo.DishRatingVO, error) {
+	threshold, minCount := queryDTO.Threshold, queryDTO.MinCount
+	if threshold <= 0 {
+		threshold = constant.ReviewLowThreshold
+	}
+	if minCount <= 0 {
+		minCount = constant.ReviewLowMinCount
+	}
+	var begin, end time.Time
+	var err error
+	if queryDTO.Begin != "" {
+		if begin, err = time.ParseInLocation(time.DateOnly, queryDTO.Begin, time.Local); err != nil {
+			return nil, errs.Wrap(err, constant.CodeBadRequest, constant.MsgBadRequest)
+		}
+	}
+	if queryDTO.End != "" {
+		if end, err = time.ParseInLocation(time.DateOnly, queryDTO.End, time.Local); err != nil {
+			return nil, errs.Wrap(err, constant.CodeBadRequest, constant.MsgBadRequest)
+		}
+		// 包含结束日期当天
+		end = end.AddDate(0, 0, 1)
+	}
+	list, err := s.reviewDAO.LowRatedDishes(global.DB, threshold, minCount, begin, end)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if list == nil {
+		list = make([]*vo.DishRatingVO, 0)
+	}
+	return list, nil
+}
+
+// toVOs 组装评价及其商品评分
+func (s *ReviewService) toVOs(reviews []*entity.Review) ([]*vo.ReviewVO, error) {
+	ids := make([]int, 0, len(reviews))
+	for _, review := range reviews {
+		ids = append(ids, review.ID)
+	}
+	items, err := s.reviewDAO.ListItems(global.DB, ids)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	itemMap := make(map[int][]*vo.ReviewItemVO, len(reviews))
+	for _, item := range items {
+		itemMap[item.ReviewID] = append(itemMap[item.ReviewID], &vo.ReviewItemVO{
+			OrderDetailID: item.OrderDetailID,
+			DishID:        item.DishID,
+			SetmealID:     item.SetmealID,
+			Name:          item.Name,
+			Rating:        item.Rating,
+			Content:       item.Content,
+		})
+	}
+
+	list := make([]*vo.ReviewVO, 0, len(reviews))
+	for _, review := range reviews {
+		reviewVO := &vo.ReviewVO{
+			ID:         review.ID,
+			OrderID:    review.OrderID,
+			UserID:     review.UserID,
+			Rating:     review.Rating,
+			Content:    review.Content,
+			Images:     make([]string, 0),
+			Status:     review.Status,
+			Reply:      review.Reply,
+			ReplyTime:  review.ReplyTime,
+			CreateTime: review.CreateTime,
+			Items:      itemMap[review.ID],
+		}
+		if review.Images != "" {
+			if err = json.Unmarshal([]byte(review.Images), &reviewVO.Images); err != nil {
+				return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgUnmarshalFail)
+			}
+		}
+		if reviewVO.Items == nil {
+			reviewVO.Items = make([]*vo.ReviewItemVO, 0)
+		}
+		order, e := s.orderDAO.GetByID(global.DB, review.OrderID)
+		if e != nil && !errors.Is(e, gorm.ErrRecordNotFound) {
+			return nil, errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if e == nil {
+			reviewVO.OrderNumber = order.Number
+		}
+		list = append(list, reviewVO)
+	}
+	return list, nil
+}
+
+func validRating(rating int) bool {
+	return rating >= constant.ReviewMinRating && rating <= constant.ReviewMaxRating
+}
+
+// validImages 图片必须是上传接口返回的地址，即 OSS 存储桶域名下的 https 地址
+func validImages(images []string) bool {
+	if len(images) > constant.ReviewMaxImages {
+		return false
+	}
+	host := global.Config.OSS.BucketName + "." + global.Config.OSS.Endpoint
+	for _, image := range images {
+		if len(image) > constant.ReviewMaxImageSize {
+			return false
+		}
+		u, err := url.Parse(image)
+		if err != nil || u.Scheme != "https" || u.Host != host {
+			return false
+		}
+	}
+	return true
+}
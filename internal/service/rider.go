@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"strings"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/common/logger"
+	"takeout/common/utils"
+	"takeout/internal/dao"
+	"takeout/internal/websocket"
+	"takeout/model/dto"
+	"takeout/model/entity"
+	"takeout/model/vo"
+	"time"
+)
+
+// RiderDispatcher 骑手调度，为订单指派骑手
+type RiderDispatcher struct {
+	orderDAO dao.OrderDAO
+	riderDAO dao.RiderDAO
+}
+
+// Assign 为订单指派骑手，riderID 为 0 时自动选择进行中订单最少的接单骑手
+func (d *RiderDispatcher) Assign(db *gorm.DB, orderID, riderID int) error {
+	if riderID == 0 {
+		riders, err := d.riderDAO.ListOnline(db)
+		if err != nil {
+			return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if len(riders) == 0 {
+			return errs.New(constant.CodeBusinessError, constant.MsgRiderUnavailable)
+		}
+		riderID = riders[0].ID
+	} else {
+		rider, err := d.riderDAO.GetByID(db, riderID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.New(constant.CodeBusinessError, constant.MsgRiderNotFound)
+			}
+			return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if rider.Status != constant.RiderEnable {
+			return errs.New(constant.CodeBusinessError, constant.MsgRiderDisabled)
+		}
+	}
+	// 只有已接单且骑手还未取餐的订单可以指派或改派
+	rows, err := d.orderDAO.AssignRider(db, orderID, riderID)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if rows == 0 {
+		return errs.New(constant.CodeBusinessError, constant.MsgOrderStatusError)
+	}
+	websocket.SendToRider(riderID, map[string]any{"type": constant.RiderAssign, "orderId": orderID, "content": "您有新的配送订单"})
+	return nil
+}
+
+// RiderService 骑手服务
+type RiderService struct {
+	riderDAO     dao.RiderDAO
+	orderDAO     dao.OrderDAO
+	stateMachine OrderStateMachine
+	orderService OrderService
+}
+
+// Login 骑手登录
+func (s *RiderService) Login(loginDTO *dto.RiderLoginDTO) (*vo.RiderLoginVO, error) {
+	rider, err := s.riderDAO.GetByUsername(global.DB, loginDTO.Username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.New(constant.CodeUserNotExist, constant.MsgRiderNotFound)
+		}
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if rider.Status != constant.RiderEnable {
+		return nil, errs.New(constant.CodeUserDisabled, constant.MsgRiderDisabled)
+	}
+	ok, rehash := utils.VerifyPassword(rider.Password, loginDTO.Password)
+	if !ok {
+		return nil, errs.New(constant.CodePasswordError, constant.MsgPasswordIncorrect)
+	}
+	// 旧版 MD5 密码登录成功后升级为当前算法，失败不影响登录
+	if rehash {
+		hashed, e := utils.HashPassword(loginDTO.Password)
+		if e == nil {
+			e = s.riderDAO.UpdatePassword(global.DB, rider.ID, hashed)
+		}
+		if e != nil {
+			logger.Error(constant.MsgPasswordUpgradeFail, zap.Int("riderId", rider.ID), zap.Error(e))
+		}
+	}
+	tokens, err := utils.IssueTokens(context.Background(), constant.AudienceRider, rider.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &vo.RiderLoginVO{
+		ID:           rider.ID,
+		Username:     rider.Username,
+		Name:         rider.Name,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}, nil
+}
+
+// Create 新增骑手，使用默认密码
+func (s *RiderService) Create(ctx *gin.Context, createDTO *dto.RiderDTO) error {
+	password, err := utils.HashPassword(constant.DefaultPassword)
+	if err != nil {
+		return err
+	}
+	rider := &entity.Rider{
+		Username: createDTO.Username,
+		Password: password,
+		Name:     createDTO.Name,
+		Phone:    createDTO.Phone,
+		Status:   constant.RiderEnable,
+	}
+	if err := s.riderDAO.Create(ctx, global.DB, rider); err != nil {
+		if strings.Contains(err.Error(), constant.MsgKeyDuplicateError) {
+			return errs.Wrap(err, constant.CodeBusinessError, constant.MsgNameConflict)
+		}
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// Update 修改骑手信息
+func (s *RiderService) Update(ctx *gin.Context, updateDTO *dto.RiderDTO) error {
+	rider := &entity.Rider{
+		ID:       updateDTO.ID,
+		Username: updateDTO.Username,
+		Name:     updateDTO.Name,
+		Phone:    updateDTO.Phone,
+	}
+	if err := s.riderDAO.Update(ctx, global.DB, rider); err != nil {
+		if strings.Contains(err.Error(), constant.MsgKeyDuplicateError) {
+			return errs.Wrap(err, constant.CodeBusinessError, constant.MsgNameConflict)
+		}
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// PageQuery 分页查询骑手
+func (s *RiderService) PageQuery(queryDTO *dto.RiderPageQueryDTO) (*vo.PageResult, error) {
+	total, list, err := s.riderDAO.PageQuery(global.DB, queryDTO.Name, queryDTO.Status, queryDTO.Page, queryDTO.PageSize)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgQueryFail)
+	}
+	return &vo.PageResult{
+		Total:   total,
+		Records: list,
+	}, nil
+}
+
+// UpdateStatus 启用或禁用骑手
+func (s *RiderService) UpdateStatus(id, status int) error {
+	rows, err := s.riderDAO.UpdateStatus(global.DB, id, status)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgUpdateFail)
+	}
+	if rows == 0 {
+		return errs.New(constant.CodeNotFound, constant.MsgRiderNotFound)
+	}
+	// 禁用后立即注销该骑手的所有会话
+	if status == constant.RiderDisable {
+		return utils.RevokeAccount(context.Background(), constant.AudienceRider, id)
+	}
+	return nil
+}
+
+// ListOnline 查询接单中的骑手，供商家指派时选择
+func (s *RiderService) ListOnline() ([]*vo.RiderVO, error) {
+	list, err := s.riderDAO.ListOnline(global.DB)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgQueryFail)
+	}
+	return list, nil
+}
+
+// UpdateWorkStatus 骑手切换接单、休息状态
+func (s *RiderService) UpdateWorkStatus(ctx *gin.Context, workStatus int) error {
+	riderID, err := utils.GetId(ctx)
+	if err != nil {
+		return err
+	}
+	if err = s.riderDAO.UpdateWorkStatus(global.DB, riderID, workStatus); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgUpdateFail)
+	}
+	return nil
+}
+
+// ReportLocation 骑手上报当前位置
+func (s *RiderService) ReportLocation(ctx *gin.Context, locationDTO *dto.RiderLocationDTO) error {
+	riderID, err := utils.GetId(ctx)
+	if err != nil {
+		return err
+	}
+	if err = s.riderDAO.UpdateLocation(global.DB, riderID, locationDTO.Latitude, locationDTO.Longitude, time.Now()); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// Orders 查询指派给当前骑手的订单，riderStatus 为 0 时查询进行中的订单
+func (s *RiderService) Orders(ctx *gin.Context, riderStatus int) ([]*entity.Order, error) {
+	riderID, err := utils.GetId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := []int{constant.RiderAssigned, constant.RiderAccepted, constant.RiderPickedUp}
+	if riderStatus != 0 {
+		statuses = []int{riderStatus}
+	}
+	orders, err := s.orderDAO.ListByRider(global.DB, riderID, statuses)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgQueryFail)
+	}
+	return orders, nil
+}
+
+// Accept 骑手接受指派的订单
+func (s *RiderService) Accept(ctx *gin.Context, orderID int) error {
+	riderID, err := utils.GetId(ctx)
+	if err != nil {
+		return err
+	}
+	return s.advance(global.DB, orderID, riderID, constant.RiderAssigned, constant.RiderAccepted)
+}
+
+// Reject 骑手拒绝指派的订单，订单回到未指派状态由商家重新指派
+func (s *RiderService) Reject(ctx *gin.Context, orderID int) error {
+	riderID, err := utils.GetId(ctx)
+	if err != nil {
+		return err
+	}
+	rows, err := s.orderDAO.ReleaseRider(global.DB, orderID, riderID)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if rows == 0 {
+		return errs.New(constant.CodeBusinessError, constant.MsgRiderOrderStatusError)
+	}
+	return nil
+}
+
+// Pickup 骑手到店取餐，订单进入派送中
+func (s *RiderService) Pickup(ctx *gin.Context, orderID int) error {
+	riderID, err := utils.GetId(ctx)
+	if err != nil {
+		return err
+	}
+	var t *OrderTransition
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if e := s.advance(tx, orderID, riderID, constant.RiderAccepted, constant.RiderPickedUp); e != nil {
+			return e
+		}
+		order, e := s.orderDAO.GetByID(tx, orderID)
+		if e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		// 商家可能已经手动派送
+		if order.Status == constant.DeliveryInProgress {
+			return nil
+		}
+		t = &OrderTransition{
+			Order:        order,
+			To:           constant.DeliveryInProgress,
+			OperatorType: constant.OperatorRider,
+			OperatorID:   riderID,
+			Reason:       "骑手取餐",
+		}
+		return s.stateMachine.Transit(tx, t)
+	})
+	if err != nil {
+		return err
+	}
+	if t != nil {
+		s.stateMachine.Notify(t)
+	}
+	return nil
+}
+
+// Deliver 骑手送达，同时完成订单
+func (s *RiderService) Deliver(ctx *gin.Context, orderID int) error {
+	riderID, err := utils.GetId(ctx)
+	if err != nil {
+		return err
+	}
+	var t *OrderTransition
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if e := s.advance(tx, orderID, riderID, constant.RiderPickedUp, constant.RiderDelivered); e != nil {
+			return e
+		}
+		var e error
+		t, e = s.orderService.complete(tx, orderID, constant.OperatorRider, riderID)
+		return e
+	})
+	if err != nil {
+		return err
+	}
+	s.stateMachine.Notify(t)
+	return nil
+}
+
+// 推进骑手配送进度
+func (s *RiderService) advance(db *gorm.DB, orderID, riderID, from, to int) error {
+	rows, err := s.orderDAO.UpdateRiderStatus(db, orderID, riderID, from, to)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if rows == 0 {
+		return errs.New(constant.CodeBusinessError, constant.MsgRiderOrderStatusError)
+	}
+	return nil
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/common/utils"
+	"takeout/internal/dao"
+	"takeout/model/dto"
+	"takeout/model/entity"
+	"takeout/model/vo"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// permissionCatalog 可分配给角色的全部权限
+var permissionCatalog = []vo.PermissionVO{
+	{Code: constant.PermEmployeeView, Name: "查看员工"},
+	{Code: constant.PermEmployeeManage, Name: "管理员工"},
+	{Code: constant.PermEmployeePassword, Name: "重置员工密码"},
+	{Code: constant.PermRoleManage, Name: "管理角色"},
+	{Code: constant.PermAuditView, Name: "查看操作日志"},
+	{Code: constant.PermMenuView, Name: "查看菜单"},
+	{Code: constant.PermMenuManage, Name: "管理菜单"},
+	{Code: constant.PermOrderView, Name: "查看订单"},
+	{Code: constant.PermOrderOperate, Name: "处理订单"},
+	{Code: constant.PermOrderCancel, Name: "拒单和取消订单"},
+	{Code: constant.PermKitchenOperate, Name: "后厨出餐"},
+	{Code: constant.PermReportView, Name: "查看报表"},
+	{Code: constant.PermReportExport, Name: "导出报表"},
+	{Code: constant.PermShopManage, Name: "设置营业状态"},
+	{Code: constant.PermCouponManage, Name: "管理优惠券"},
+	{Code: constant.PermReviewManage, Name: "管理评价"},
+	{Code: constant.PermMemberManage, Name: "管理会员积分和余额"},
+	{Code: constant.PermRiderView, Name: "查看骑手"},
+	{Code: constant.PermRiderManage, Name: "管理骑手"},
+}
+
+// permissionCacheTTL 员工权限缓存时间，角色变更时会主动清理
+const permissionCacheTTL = 10 * time.Minute
+
+// PermissionService 员工权限校验
+type PermissionService struct {
+	employeeDAO dao.EmployeeDAO
+	roleDAO     dao.RoleDAO
+}
+
+// Permissions 查询员工拥有的权限码，结果缓存在 Redis 中
+func (s *PermissionService) Permissions(ctx context.Context, empID int) ([]string, error) {
+	key := constant.CachePermissionKey + strconv.Itoa(empID)
+	cacheData, err := global.Redis.Get(ctx, key).Result()
+	if err == nil {
+		var permissions []string
+		if err = json.Unmarshal([]byte(cacheData), &permissions); err != nil {
+			return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgUnmarshalFail)
+		}
+		return permissions, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		return nil, errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+
+	employee, err := s.employeeDAO.GetById(empID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.New(constant.CodeUserNotExist, constant.MsgUserNotExist)
+		}
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	permissions := make([]string, 0)
+	if employee.RoleID != 0 {
+		if permissions, err = s.roleDAO.ListPermissions(global.DB, employee.RoleID); err != nil {
+			return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+	}
+
+	data, err := json.Marshal(permissions)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgMarshalFail)
+	}
+	if err = global.Redis.Set(ctx, key, data, permissionCacheTTL).Err(); err != nil {
+		return nil, errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	return permissions, nil
+}
+
+// HasAny 员工拥有其中任一权限即返回 true
+func (s *PermissionService) HasAny(ctx context.Context, empID int, required ...string) (bool, error) {
+	permissions, err := s.Permissions(ctx, empID)
+	if err != nil {
+		return false, err
+	}
+	if slices.Contains(permissions, constant.PermAll) {
+		return true, nil
+	}
+	for _, p := range required {
+		if slices.Contains(permissions, p) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CanGrant 只能把不超过自身权限的角色授予他人，防止员工给自己或他人提权
+func (s *PermissionService) CanGrant(ctx context.Context, empID, roleID int) error {
+	if _, err := s.roleDAO.GetByID(global.DB, roleID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.New(constant.CodeBadRequest, constant.MsgRoleNotFound)
+		}
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	granted, err := s.roleDAO.ListPermissions(global.DB, roleID)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return s.CanAssign(ctx, empID, granted)
+}
+
+// CanAssign 角色中只能配置不超过自身的权限，防止员工通过修改角色提权
+func (s *PermissionService) CanAssign(ctx context.Context, empID int, permissions []string) error {
+	owned, err := s.Permissions(ctx, empID)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(owned, constant.PermAll) {
+		return nil
+	}
+	for _, p := range permissions {
+		if !slices.Contains(owned, p) {
+			return errs.New(constant.CodeForbidden, constant.MsgRoleGrantDenied)
+		}
+	}
+	return nil
+}
+
+// Evict 清除员工的权限缓存，员工角色变更后调用
+func (s *PermissionService) Evict(empID int) error {
+	if err := utils.CleanCache(constant.CachePermissionKey + strconv.Itoa(empID)); err != nil {
+		return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	return nil
+}
+
+// RoleService 角色管理
+type RoleService struct {
+	roleDAO           dao.RoleDAO
+	employeeDAO       dao.EmployeeDAO
+	permissionService PermissionService
+}
+
+// Permissions 可分配的全部权限
+func (s *RoleService) Permissions() []vo.PermissionVO {
+	return permissionCatalog
+}
+
+// List 查询全部角色及其权限
+func (s *RoleService) List() ([]*vo.RoleVO, error) {
+	roles, err := s.roleDAO.List(global.DB)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgRoleQueryFail)
+	}
+	list := make([]*vo.RoleVO, 0, len(roles))
+	for _, role := range roles {
+		roleVO, e := s.toVO(role)
+		if e != nil {
+			return nil, e
+		}
+		list = append(list, roleVO)
+	}
+	return list, nil
+}
+
+// GetByID 查询角色详情
+func (s *RoleService) GetByID(id int) (*vo.RoleVO, error) {
+	role, err := s.getRole(global.DB, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toVO(role)
+}
+
+// Create 新增角色
+func (s *RoleService) Create(ctx *gin.Context, roleDTO *dto.RoleDTO) error {
+	permissions, err := s.checkPermissions(ctx, roleDTO.Permissions)
+	if err != nil {
+		return err
+	}
+	return global.DB.Transaction(func(tx *gorm.DB) error {
+		role := &entity.Role{Code: roleDTO.Code, Name: roleDTO.Name}
+		if e := s.roleDAO.Create(ctx, tx, role); e != nil {
+			if strings.Contains(e.Error(), constant.MsgKeyDuplicateError) {
+				return errs.Wrap(e, constant.CodeBusinessError, constant.MsgNameConflict)
+			}
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgRoleCreateFail)
+		}
+		if e := s.roleDAO.ReplacePermissions(tx, role.ID, permissions); e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgRoleCreateFail)
+		}
+		return nil
+	})
+}
+
+// Update 修改角色名称和权限，店主角色不能修改，非店主不能修改自己所属的角色
+func (s *RoleService) Update(ctx *gin.Context, roleDTO *dto.RoleDTO) error {
+	permissions, err := s.checkPermissions(ctx, roleDTO.Permissions)
+	if err != nil {
+		return err
+	}
+	empID, err := utils.GetId(ctx)
+	if err != nil {
+		return err
+	}
+	employee, err := s.employeeDAO.GetById(empID)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if employee.RoleID == roleDTO.ID {
+		owner, e := s.permissionService.HasAny(ctx, empID, constant.PermAll)
+		if e != nil {
+			return e
+		}
+		if !owner {
+			return errs.New(constant.CodeForbidden, constant.MsgRoleSelfEdit)
+		}
+	}
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		role, e := s.getRole(tx, roleDTO.ID)
+		if e != nil {
+			return e
+		}
+		if role.Code == constant.RoleOwner {
+			return errs.New(constant.CodeBusinessError, constant.MsgRoleOwnerReadonly)
+		}
+		role.Name = roleDTO.Name
+		if e = s.roleDAO.Update(ctx, tx, role); e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgRoleUpdateFail)
+		}
+		if e = s.roleDAO.ReplacePermissions(tx, role.ID, permissions); e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgRoleUpdateFail)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return s.evictAll()
+}
+
+// Delete 删除角色，内置角色和仍有员工使用的角色不能删除
+func (s *RoleService) Delete(id int) error {
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		role, e := s.getRole(tx, id)
+		if e != nil {
+			return e
+		}
+		if role.Builtin {
+			return errs.New(constant.CodeBusinessError, constant.MsgRoleBuiltin)
+		}
+		count, e := s.roleDAO.CountEmployees(tx, id)
+		if e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgRoleDeleteFail)
+		}
+		if count > 0 {
+			return errs.New(constant.CodeBusinessError, constant.MsgRoleInUse)
+		}
+		if e = s.roleDAO.Delete(tx, id); e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgRoleDeleteFail)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return s.evictAll()
+}
+
+// checkPermissions 校验权限码，并且只能配置操作人自身拥有的权限
+func (s *RoleService) checkPermissions(ctx *gin.Context, permissions []string) ([]string, error) {
+	permissions, err := normalizePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+	empID, err := utils.GetId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.permissionService.CanAssign(ctx, empID, permissions); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (s *RoleService) getRole(db *gorm.DB, id int) (*entity.Role, error) {
+	role, err := s.roleDAO.GetByID(db, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.New(constant.CodeNotFound, constant.MsgRoleNotFound)
+		}
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgRoleQueryFail)
+	}
+	return role, nil
+}
+
+func (s *RoleService) toVO(role *entity.Role) (*vo.RoleVO, error) {
+	permissions, err := s.roleDAO.ListPermissions(global.DB, role.ID)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgPermissionQueryFail)
+	}
+	return &vo.RoleVO{
+		ID:          role.ID,
+		Code:        role.Code,
+		Name:        role.Name,
+		Builtin:     role.Builtin,
+		Permissions: permissions,
+	}, nil
+}
+
+// evictAll 角色权限变更影响该角色下的所有员工，直接清空权限缓存
+func (s *RoleService) evictAll() error {
+	if err := utils.CleanCache(constant.CachePermissionKey + "*"); err != nil {
+		return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	return nil
+}
+
+// normalizePermissions 校验权限码并去重，通配权限只能属于内置的店主角色
+func normalizePermissions(permissions []string) ([]string, error) {
+	result := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		valid := slices.ContainsFunc(permissionCatalog, func(item vo.PermissionVO) bool {
+			return item.Code == p
+		})
+		if !valid {
+			return nil, errs.New(constant.CodeBadRequest, constant.MsgPermissionInvalid+": "+p)
+		}
+		if !slices.Contains(result, p) {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
@@ -1,14 +1,29 @@
 package service
 
 import (
+	"errors"
+	"sort"
 	"takeout/common/constant"
 	"takeout/common/errs"
+	"takeout/common/global"
 	"takeout/internal/dao"
+	"takeout/model/dto"
+	"takeout/model/entity"
+	"takeout/model/vo"
+	"takeout/model/wrap"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
 // ShopService 店铺服务
+// 实际营业状态由手动开关、忙碌暂停和营业日历共同决定：
+// 手动打烊时始终打烊；暂停期间不接单；其余时间按特殊营业日或每周营业时间判断，未配置营业时间时全天营业
 type ShopService struct {
-	shopDAO dao.ShopDAO
+	shopDAO          dao.ShopDAO
+	businessHoursDAO dao.BusinessHoursDAO
+	specialDayDAO    dao.SpecialDayDAO
 }
 
 // SetStatus 设置店铺状态
@@ -28,3 +43,296 @@ func (s *ShopService) GetStatus() (int, error) {
 	}
 	return status, nil
 }
+
+// Status 计算店铺当前的实际营业状态和下次营业时间
+func (s *ShopService) Status() (*vo.ShopStatusVO, error) {
+	now := time.Now()
+	manual, err := s.shopDAO.GetStatus()
+	if errors.Is(err, redis.Nil) {
+		manual, err = constant.ShopClosed, nil
+	}
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	statusVO := &vo.ShopStatusVO{Status: constant.ShopClosed, Manual: manual}
+	if manual != constant.ShopOpen {
+		return statusVO, nil
+	}
+
+	from := now
+	resume, err := s.shopDAO.GetPause()
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	if resume.After(now) {
+		statusVO.Paused = true
+		resumeTime := wrap.LocalTime(resume)
+		statusVO.ResumeTime = &resumeTime
+		from = resume
+	}
+
+	cal, err := s.loadCalendar(now, constant.ShopNextOpenDays)
+	if err != nil {
+		return nil, err
+	}
+	next, ok := cal.nextOpen(from)
+	if !ok {
+		return statusVO, nil
+	}
+	if !statusVO.Paused && !next.After(now) {
+		statusVO.Status = constant.ShopOpen
+		return statusVO, nil
+	}
+	nextOpen := wrap.LocalTime(next)
+	statusVO.NextOpenTime = &nextOpen
+	return statusVO, nil
+}
+
+// CheckOpen 下单、加购前检查店铺是否正在营业
+func (s *ShopService) CheckOpen() error {
+	statusVO, err := s.Status()
+	if err != nil {
+		return err
+	}
+	if statusVO.Status == constant.ShopOpen {
+		return nil
+	}
+	if statusVO.Paused {
+		return errs.New(constant.CodeBusinessError, constant.MsgShopPaused)
+	}
+	return errs.New(constant.CodeBusinessError, constant.MsgShopClosed)
+}
+
+// IsOpenAt 按营业日历判断某个时间是否在营业时间内，不考虑手动开关和暂停，用于校验预约时段
+func (s *ShopService) IsOpenAt(t time.Time) (bool, error) {
+	cal, err := s.loadCalendar(t, 1)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range cal.periodsOn(t) {
+		if !t.Before(p.open) && t.Before(p.close) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListHours 查询每周营业时间
+func (s *ShopService) ListHours() ([]*entity.BusinessHours, error) {
+	list, err := s.businessHoursDAO.List(global.DB)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return list, nil
+}
+
+// SaveHours 整体保存每周营业时间，未传的星期几视为休息；传空列表表示不限制营业时间
+func (s *ShopService) SaveHours(hoursDTOs []dto.BusinessHoursDTO) error {
+	// 同一天分多次传入时合并后再校验重叠
+	weekly := make(map[int][]dto.BusinessPeriodDTO)
+	for _, hoursDTO := range hoursDTOs {
+		weekly[hoursDTO.Weekday] = append(weekly[hoursDTO.Weekday], hoursDTO.Periods...)
+	}
+	list := make([]*entity.BusinessHours, 0)
+	for weekday, periods := range weekly {
+		if err := checkPeriods(periods); err != nil {
+			return err
+		}
+		for _, p := range periods {
+			list = append(list, &entity.BusinessHours{Weekday: weekday, OpenTime: p.OpenTime, CloseTime: p.CloseTime})
+		}
+	}
+	err := global.DB.Transaction(func(db *gorm.DB) error {
+		return s.businessHoursDAO.Replace(db, list)
+	})
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// ListSpecialDays 查询今天及以后的特殊营业日
+func (s *ShopService) ListSpecialDays() ([]*entity.SpecialDay, error) {
+	list, err := s.specialDayDAO.ListFrom(global.DB, time.Now().Format(time.DateOnly))
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return list, nil
+}
+
+// SaveSpecialDay 设置某天的营业安排，覆盖原有设置
+func (s *ShopService) SaveSpecialDay(dayDTO *dto.SpecialDayDTO) error {
+	if _, err := time.Parse(time.DateOnly, dayDTO.Date); err != nil {
+		return errs.New(constant.CodeBadRequest, constant.MsgSpecialDayError)
+	}
+	var list []*entity.SpecialDay
+	if dayDTO.Closed || len(dayDTO.Periods) == 0 {
+		list = append(list, &entity.SpecialDay{Date: dayDTO.Date, Closed: constant.SpecialDayClosed, Remark: dayDTO.Remark})
+	} else {
+		if err := checkPeriods(dayDTO.Periods); err != nil {
+			return err
+		}
+		for _, p := range dayDTO.Periods {
+			list = append(list, &entity.SpecialDay{
+				Date:      dayDTO.Date,
+				Closed:    constant.SpecialDayOpen,
+				OpenTime:  p.OpenTime,
+				CloseTime: p.CloseTime,
+				Remark:    dayDTO.Remark,
+			})
+		}
+	}
+	err := global.DB.Transaction(func(db *gorm.DB) error {
+		return s.specialDayDAO.ReplaceDate(db, dayDTO.Date, list)
+	})
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// DeleteSpecialDay 删除某天的营业安排，恢复按每周营业时间营业
+func (s *ShopService) DeleteSpecialDay(date string) error {
+	rows, err := s.specialDayDAO.DeleteDate(global.DB, date)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if rows == 0 {
+		return errs.New(constant.CodeNotFound, constant.MsgSpecialDayNotFound)
+	}
+	return nil
+}
+
+// Pause 忙碌时暂停接单，到时间后自动恢复
+func (s *ShopService) Pause(minutes int) error {
+	if err := s.shopDAO.SetPause(time.Now().Add(time.Duration(minutes) * time.Minute)); err != nil {
+		return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	return nil
+}
+
+// Resume 提前恢复接单
+func (s *ShopService) Resume() error {
+	if err := s.shopDAO.ClearPause(); err != nil {
+		return errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	return nil
+}
+
+// Settings 店铺营业设置的快照，用于审计日志
+func (s *ShopService) Settings() (map[string]any, error) {
+	status, err := s.shopDAO.GetStatus()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	resume, err := s.shopDAO.GetPause()
+	if err != nil {
+		return nil, err
+	}
+	hours, err := s.businessHoursDAO.List(global.DB)
+	if err != nil {
+		return nil, err
+	}
+	specialDays, err := s.specialDayDAO.ListFrom(global.DB, time.Now().Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+	settings := map[string]any{"status": status, "hours": hours, "specialDays": specialDays}
+	if !resume.IsZero() {
+		settings["resumeTime"] = wrap.LocalTime(resume)
+	}
+	return settings, nil
+}
+
+// businessPeriod 某天的一个营业时段，[open, close)
+type businessPeriod struct {
+	open, close time.Time
+}
+
+// businessCalendar 营业日历
+type businessCalendar struct {
+	weekly  map[time.Weekday][]*entity.BusinessHours
+	special map[string][]*entity.SpecialDay
+}
+
+// loadCalendar 加载每周营业时间和 from 起 days 天内的特殊营业日
+func (s *ShopService) loadCalendar(from time.Time, days int) (*businessCalendar, error) {
+	hours, err := s.businessHoursDAO.List(global.DB)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	specialDays, err := s.specialDayDAO.ListBetween(global.DB,
+		from.Format(time.DateOnly), from.AddDate(0, 0, days).Format(time.DateOnly))
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	cal := &businessCalendar{
+		weekly:  make(map[time.Weekday][]*entity.BusinessHours),
+		special: make(map[string][]*entity.SpecialDay),
+	}
+	for _, h := range hours {
+		cal.weekly[time.Weekday(h.Weekday)] = append(cal.weekly[time.Weekday(h.Weekday)], h)
+	}
+	for _, d := range specialDays {
+		cal.special[d.Date] = append(cal.special[d.Date], d)
+	}
+	return cal, nil
+}
+
+// periodsOn 某天的营业时段，特殊营业日优先；没有配置每周营业时间时全天营业
+func (c *businessCalendar) periodsOn(day time.Time) []businessPeriod {
+	var periods []businessPeriod
+	if days, ok := c.special[day.Format(time.DateOnly)]; ok {
+		for _, d := range days {
+			if d.Closed == constant.SpecialDayClosed {
+				return nil
+			}
+			periods = append(periods, businessPeriod{open: slotTime(day, d.OpenTime), close: slotTime(day, d.CloseTime)})
+		}
+	} else if len(c.weekly) == 0 {
+		begin := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+		return []businessPeriod{{open: begin, close: begin.AddDate(0, 0, 1)}}
+	} else {
+		for _, h := range c.weekly[day.Weekday()] {
+			periods = append(periods, businessPeriod{open: slotTime(day, h.OpenTime), close: slotTime(day, h.CloseTime)})
+		}
+	}
+	sort.Slice(periods, func(i, j int) bool { return periods[i].open.Before(periods[j].open) })
+	return periods
+}
+
+// nextOpen 从 from 开始最早的营业时间，from 处于营业时段内时返回 from
+func (c *businessCalendar) nextOpen(from time.Time) (time.Time, bool) {
+	for d := 0; d <= constant.ShopNextOpenDays; d++ {
+		for _, p := range c.periodsOn(from.AddDate(0, 0, d)) {
+			if !p.close.After(from) {
+				continue
+			}
+			if p.open.After(from) {
+				return p.open, true
+			}
+			return from, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// checkPeriods 校验营业时段格式，同一天的时段不能重叠，校验后统一为两位小时
+func checkPeriods(periods []dto.BusinessPeriodDTO) error {
+	parsed := make([]businessPeriod, 0, len(periods))
+	for i := range periods {
+		open, closeTime, ok := parseClockRange(periods[i].OpenTime, periods[i].CloseTime)
+		if !ok {
+			return errs.New(constant.CodeBadRequest, constant.MsgBusinessHoursError)
+		}
+		periods[i].OpenTime, periods[i].CloseTime = open.Format(slotClockFormat), closeTime.Format(slotClockFormat)
+		parsed = append(parsed, businessPeriod{open: open, close: closeTime})
+	}
+	sort.Slice(parsed, func(i, j int) bool { return parsed[i].open.Before(parsed[j].open) })
+	for i := 1; i < len(parsed); i++ {
+		if parsed[i].open.Before(parsed[i-1].close) {
+			return errs.New(constant.CodeBadRequest, constant.MsgBusinessHoursError)
+		}
+	}
+	return nil
+}
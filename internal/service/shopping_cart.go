@@ -1,7 +1,10 @@
 package service
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 	"takeout/common/constant"
 	"takeout/common/errs"
 	"takeout/common/global"
@@ -9,64 +12,111 @@ import (
 	"takeout/internal/dao"
 	"takeout/model/dto"
 	"takeout/model/entity"
+	"takeout/model/vo"
 )
 
 type ShoppingCartService struct {
 	shoppingCartDAO dao.ShoppingCartDAO
 	dishDAO         dao.DishDAO
 	setmealDAO      dao.SetmealDAO
+	shopService     ShopService
+	flavorService   FlavorService
+
+	priceCalculator     PriceCalculator
+	addressBookDAO      dao.AddressBookDAO
+	deliveryZoneService DeliveryZoneService
+	deliverySlotService DeliverySlotService
 }
 
-// Add 添加购物车
+// Add 添加购物车，菜品按口味组校验所选口味，单价包含口味加价
 func (s *ShoppingCartService) Add(ctx *gin.Context, addDTO *dto.ShoppingCartDTO) error {
 	userID, err := utils.GetId(ctx)
 	if err != nil {
 		return errs.Wrap(err, constant.CodeInternalError, constant.MsgGetIDFail)
 	}
-	cart := &entity.ShoppingCart{}
-	if err = utils.CopyProperties(addDTO, cart); err != nil {
-		return errs.Wrap(err, constant.CodeInternalError, constant.MsgCopyPropertiesFail)
+	if err = s.checkOpen(); err != nil {
+		return err
+	}
+	cart := &entity.ShoppingCart{UserID: userID, DishID: addDTO.DishID, SetmealID: addDTO.SetmealID}
+	if addDTO.DishID != 0 {
+		// 添加的是菜品
+		dish, e := s.dishDAO.GetById(addDTO.DishID)
+		if e != nil {
+			if errors.Is(e, gorm.ErrRecordNotFound) {
+				return errs.Wrap(e, constant.CodeBusinessError, constant.MsgGoodsUnavailable)
+			}
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		groups, e := s.flavorService.Groups(global.DB, dish.ID)
+		if e != nil {
+			return e
+		}
+		choice, e := s.flavorService.Resolve(groups, addDTO.FlavorChoices, addDTO.DishFlavor)
+		if e != nil {
+			return e
+		}
+		if cart.Flavors, e = choice.Snapshot(); e != nil {
+			return e
+		}
+		cart.DishFlavor = choice.Text
+		cart.Name = dish.Name
+		cart.Image = dish.Image
+		cart.Amount = dish.Price.Add(choice.Surcharge)
+	} else {
+		// 添加的是套餐
+		setmeal, e := s.setmealDAO.GetByID(global.DB, addDTO.SetmealID)
+		if e != nil {
+			if errors.Is(e, gorm.ErrRecordNotFound) {
+				return errs.Wrap(e, constant.CodeBusinessError, constant.MsgGoodsUnavailable)
+			}
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		cart.Name = setmeal.Name
+		cart.Image = setmeal.Image
+		cart.Amount = setmeal.Price
 	}
-	cart.UserID = userID
 
 	list, err := s.shoppingCartDAO.List(global.DB, cart)
 	if err != nil {
 		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
 	}
-	if len(list) > 0 {
-		c := list[0]
-		c.Number++
-		err = s.shoppingCartDAO.UpdateNumberByID(global.DB, c)
-		if err != nil {
-			return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	// 口味为空时 List 不按口味过滤，这里按口味文字精确匹配同一行
+	for _, c := range list {
+		if c.DishFlavor != cart.DishFlavor {
+			continue
 		}
-	} else {
-		if addDTO.DishID != 0 {
-			// 添加的是菜品
-			dish, e := s.dishDAO.GetById(addDTO.DishID)
-			if e != nil {
-				return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
-			}
-			cart.Name = dish.Name
-			cart.Image = dish.Image
-			cart.Amount = dish.Price
-		} else {
-			// 添加的是套餐
-			setmeal, e := s.setmealDAO.GetByID(global.DB, addDTO.SetmealID)
-			if e != nil {
-				return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
-			}
-			cart.Name = setmeal.Name
-			cart.Image = setmeal.Image
-			cart.Amount = setmeal.Price
+		if c.Number >= constant.CartMaxNumber {
+			return errs.New(constant.CodeBusinessError, constant.MsgCartNumberInvalid)
 		}
-		cart.Number = 1
-		err = s.shoppingCartDAO.Create(global.DB, cart)
+		c.Number++
+		err = s.shoppingCartDAO.UpdateNumberByID(global.DB, c)
 		if err != nil {
 			return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
 		}
+		return nil
 	}
+	cart.Number = 1
+	err = s.shoppingCartDAO.Create(global.DB, cart)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
 
+// checkOpen 不在营业时间内不能加购；打烊后仍有可预约的时段时允许加购，用于提前预约，
+// 立即送出的订单在下单时会再次校验营业状态
+func (s *ShoppingCartService) checkOpen() error {
+	openErr := s.shopService.CheckOpen()
+	if openErr == nil {
+		return nil
+	}
+	bookable, err := s.deliverySlotService.Bookable()
+	if err != nil {
+		return err
+	}
+	if !bookable {
+		return openErr
+	}
 	return nil
 }
 
@@ -96,26 +146,33 @@ func (s *ShoppingCartService) Clean(ctx *gin.Context) error {
 	return nil
 }
 
-// Sub 减少购物车中的一个商品
+// Sub 减少购物车中的一个商品，传入行ID时按行操作，否则按菜品、套餐和口味查找
 func (s *ShoppingCartService) Sub(ctx *gin.Context, subDTO *dto.ShoppingCartDTO) error {
 	userID, err := utils.GetId(ctx)
 	if err != nil {
 		return errs.Wrap(err, constant.CodeInternalError, constant.MsgGetIDFail)
 	}
-	cart := &entity.ShoppingCart{}
-	if err = utils.CopyProperties(subDTO, cart); err != nil {
-		return errs.Wrap(err, constant.CodeInternalError, constant.MsgCopyPropertiesFail)
-	}
-	cart.UserID = userID
-
-	list, err := s.shoppingCartDAO.List(global.DB, cart)
-	if err != nil {
-		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
-	}
-	if len(list) == 0 {
-		return errs.New(constant.CodeBusinessError, constant.MsgBadRequest)
+	var c *entity.ShoppingCart
+	if subDTO.ID != 0 {
+		if c, err = s.getLine(userID, subDTO.ID); err != nil {
+			return err
+		}
+	} else {
+		cart := &entity.ShoppingCart{UserID: userID, DishID: subDTO.DishID, SetmealID: subDTO.SetmealID, DishFlavor: subDTO.DishFlavor}
+		list, e := s.shoppingCartDAO.List(global.DB, cart)
+		if e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		for _, line := range list {
+			if line.DishFlavor == subDTO.DishFlavor {
+				c = line
+				break
+			}
+		}
+		if c == nil {
+			return errs.New(constant.CodeBusinessError, constant.MsgBadRequest)
+		}
 	}
-	c := list[0]
 	if c.Number != 1 {
 		c.Number--
 		err = s.shoppingCartDAO.UpdateNumberByID(global.DB, c)
@@ -130,3 +187,207 @@ func (s *ShoppingCartService) Sub(ctx *gin.Context, subDTO *dto.ShoppingCartDTO)
 	}
 	return nil
 }
+
+// UpdateNumber 修改购物车中一行的数量，数量为 0 时删除该行
+func (s *ShoppingCartService) UpdateNumber(ctx *gin.Context, numberDTO *dto.CartNumberDTO) error {
+	userID, err := utils.GetId(ctx)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeInternalError, constant.MsgGetIDFail)
+	}
+	if numberDTO.Number < 0 || numberDTO.Number > constant.CartMaxNumber {
+		return errs.New(constant.CodeBadRequest, constant.MsgCartNumberInvalid)
+	}
+	c, err := s.getLine(userID, numberDTO.ID)
+	if err != nil {
+		return err
+	}
+	if numberDTO.Number == 0 {
+		err = s.shoppingCartDAO.DeleteByID(global.DB, c)
+	} else {
+		c.Number = numberDTO.Number
+		err = s.shoppingCartDAO.UpdateNumberByID(global.DB, c)
+	}
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
+
+// Summary 按商品当前价格汇总购物车，标记不可售和价格变动的商品
+func (s *ShoppingCartService) Summary(ctx *gin.Context) (*vo.CartSummaryVO, error) {
+	userID, err := utils.GetId(ctx)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgGetIDFail)
+	}
+	summary, _, err := s.summarize(userID)
+	return summary, err
+}
+
+// Validate 下单前校验购物车，价格变动的商品更新为当前单价，用户确认后即可下单
+// 不可售的商品保留在购物车中，由用户自行删除
+func (s *ShoppingCartService) Validate(ctx *gin.Context) (*vo.CartSummaryVO, error) {
+	userID, err := utils.GetId(ctx)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgGetIDFail)
+	}
+	summary, list, err := s.summarize(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range summary.Lines {
+		if line.Status != constant.CartLineRepriced {
+			continue
+		}
+		list[i].Amount = line.CurrentAmount
+		if err = s.shoppingCartDAO.UpdateAmountByID(global.DB, list[i]); err != nil {
+			return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+	}
+	return summary, nil
+}
+
+// summarize 逐行按当前价格计算购物车，返回汇总和对应的购物车记录
+func (s *ShoppingCartService) summarize(userID int) (*vo.CartSummaryVO, []*entity.ShoppingCart, error) {
+	list, err := s.shoppingCartDAO.List(global.DB, &entity.ShoppingCart{UserID: userID})
+	if err != nil {
+		return nil, nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	summary := &vo.CartSummaryVO{Lines: make([]*vo.CartLineVO, 0, len(list)), Valid: true}
+	for _, cart := range list {
+		lineVO := &vo.CartLineVO{
+			ID:         cart.ID,
+			DishID:     cart.DishID,
+			SetmealID:  cart.SetmealID,
+			Name:       cart.Name,
+			Image:      cart.Image,
+			DishFlavor: cart.DishFlavor,
+			Number:     cart.Number,
+			Amount:     cart.Amount,
+		}
+		summary.Lines = append(summary.Lines, lineVO)
+		line, e := s.priceCalculator.Line(global.DB, cart)
+		if e != nil {
+			// 商品已删除、停售或口味已变更，其余错误直接返回
+			if errs.GetCode(e) != constant.CodeBusinessError {
+				return nil, nil, e
+			}
+			lineVO.Status = constant.CartLineUnavailable
+			lineVO.Message = errs.GetMessage(e)
+			summary.Valid = false
+			continue
+		}
+		lineVO.Name, lineVO.Image = line.Name, line.Image
+		lineVO.CurrentAmount = line.UnitPrice
+		lineVO.PackAmount = line.PackAmount
+		lineVO.TotalAmount = line.Amount
+		if !cart.Amount.Equal(line.UnitPrice) {
+			lineVO.Status = constant.CartLineRepriced
+			lineVO.Message = constant.MsgCartRepriced
+			summary.Valid = false
+		}
+		summary.Number += cart.Number
+		summary.GoodsAmount = summary.GoodsAmount.Add(line.UnitPrice.Mul(decimal.NewFromInt(int64(cart.Number))))
+		summary.PackAmount = summary.PackAmount.Add(line.PackAmount)
+	}
+	summary.GoodsAmount = summary.GoodsAmount.Round(2)
+	summary.PackAmount = summary.PackAmount.Round(2)
+	summary.Amount = summary.GoodsAmount.Add(summary.PackAmount)
+	return summary, list, nil
+}
+
+// getLine 查询当前用户购物车中的一行
+func (s *ShoppingCartService) getLine(userID, id int) (*entity.ShoppingCart, error) {
+	c, err := s.shoppingCartDAO.GetByID(global.DB, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.New(constant.CodeNotFound, constant.MsgCartLineNotFound)
+		}
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if c.UserID != userID {
+		return nil, errs.New(constant.CodeNotFound, constant.MsgCartLineNotFound)
+	}
+	return c, nil
+}
+
+// Preview 购物车结算预览，按收货地址所在配送区域计算配送费并判断能否下单
+// addressBookID 为 0 时使用默认地址
+func (s *ShoppingCartService) Preview(ctx *gin.Context, addressBookID int) (*vo.CartPreviewVO, error) {
+	userID, err := utils.GetId(ctx)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgGetIDFail)
+	}
+	cartList, err := s.shoppingCartDAO.List(global.DB, &entity.ShoppingCart{UserID: userID})
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	// 配送费与餐具费无关，预览时不计餐具
+	price, err := s.priceCalculator.Calculate(global.DB, cartList, constant.TablewareByNumber, 0)
+	if err != nil {
+		return nil, err
+	}
+	preview := &vo.CartPreviewVO{
+		GoodsAmount: price.GoodsAmount,
+		PackAmount:  price.PackAmount,
+		DeliveryFee: price.DeliveryFee,
+		Amount:      price.Amount,
+	}
+
+	address, err := s.previewAddress(userID, addressBookID)
+	if err != nil {
+		return nil, err
+	}
+	if address == nil {
+		preview.Message = constant.MsgNotExistDefaultAddress
+		return preview, nil
+	}
+	quote, err := s.deliveryZoneService.Quote(global.DB, address)
+	if err != nil {
+		// 超出范围、无法定位等业务原因作为提示返回，不作为请求失败
+		if errs.GetCode(err) != constant.CodeBusinessError {
+			return nil, err
+		}
+		preview.Message = errs.GetMessage(err)
+		return preview, nil
+	}
+	price.SetDeliveryFee(quote.DeliveryFee)
+	preview.DeliveryFee = price.DeliveryFee
+	preview.Amount = price.Amount
+	preview.MinAmount = quote.MinAmount
+	preview.ZoneName = quote.ZoneName
+	preview.Distance = quote.Distance
+	switch {
+	case len(cartList) == 0:
+		preview.Message = constant.MsgShoppingCartIsNull
+	case price.GoodsAmount.LessThan(quote.MinAmount):
+		preview.Message = constant.MsgBelowMinAmount
+	default:
+		preview.Deliverable = true
+	}
+	return preview, nil
+}
+
+// previewAddress 查询预览使用的收货地址，未设置默认地址时返回 nil
+func (s *ShoppingCartService) previewAddress(userID, addressBookID int) (*entity.AddressBook, error) {
+	if addressBookID == 0 {
+		list, err := s.addressBookDAO.List(global.DB, &entity.AddressBook{UserID: userID, IsDefault: constant.DefaultAddress})
+		if err != nil {
+			return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if len(list) == 0 {
+			return nil, nil
+		}
+		return list[0], nil
+	}
+	address, err := s.addressBookDAO.GetByID(global.DB, addressBookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.Wrap(err, constant.CodeBadRequest, constant.MsgBadRequest)
+		}
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if address.UserID != userID {
+		return nil, errs.New(constant.CodeBadRequest, constant.MsgBadRequest)
+	}
+	return address, nil
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"sort"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/logger"
+	"takeout/common/utils"
+	"takeout/internal/dao"
+	"takeout/model/entity"
+)
+
+// StockService 菜品库存服务
+// 只有菜品有库存，套餐按所含菜品及份数折算，库存为空的菜品不限量
+type StockService struct {
+	dishDAO        dao.DishDAO
+	setmealDishDAO dao.SetmealDishDAO
+	stockDAO       dao.StockDAO
+}
+
+// stockLine 需要扣减或归还库存的一行商品
+type stockLine struct {
+	DishID    int
+	SetmealID int
+	Number    int
+}
+
+// Deduct 下单时扣减购物车商品的库存并记录库存流水，需要与创建订单放在同一个事务中
+// 返回是否有菜品因售罄停售，调用方在事务提交后清除缓存
+func (s *StockService) Deduct(db *gorm.DB, orderID int, cartList []*entity.ShoppingCart) (bool, error) {
+	lines := make([]stockLine, 0, len(cartList))
+	for _, cart := range cartList {
+		lines = append(lines, stockLine{DishID: cart.DishID, SetmealID: cart.SetmealID, Number: cart.Number})
+	}
+	need, err := s.dishNumbers(db, lines)
+	if err != nil {
+		return false, err
+	}
+	dishes, err := s.dishDAO.ListByIDs(db, sortedKeys(need))
+	if err != nil {
+		return false, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	// 按ID顺序扣减，避免并发下单时互相等待行锁造成死锁
+	sort.Slice(dishes, func(i, j int) bool { return dishes[i].ID < dishes[j].ID })
+	limited := make([]int, 0, len(dishes))
+	logs := make([]*entity.StockLog, 0, len(dishes))
+	for _, dish := range dishes {
+		if dish.Stock == nil {
+			continue
+		}
+		rows, e := s.dishDAO.DeductStock(db, dish.ID, need[dish.ID])
+		if e != nil {
+			return false, errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if rows == 0 {
+			return false, errs.New(constant.CodeBusinessError, constant.MsgStockNotEnough+"："+dish.Name)
+		}
+		limited = append(limited, dish.ID)
+		logs = append(logs, &entity.StockLog{OrderID: orderID, DishID: dish.ID, Number: need[dish.ID], Status: constant.StockDeducted})
+	}
+	if len(limited) == 0 {
+		return false, nil
+	}
+	if err = s.stockDAO.BatchInsert(db, logs); err != nil {
+		return false, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+
+	// 卖完的菜品自动停售
+	rows, err := s.dishDAO.DisableSoldOut(db, limited)
+	if err != nil {
+		return false, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return rows > 0, nil
+}
+
+// Restore 订单取消时按库存流水归还下单时实际扣减的库存，因售罄停售的菜品重新起售
+// 下单后库存已被重置的菜品不再归还；返回是否有菜品重新起售，调用方在事务提交后清除缓存
+func (s *StockService) Restore(db *gorm.DB, orderID int) (bool, error) {
+	logs, err := s.stockDAO.ListDeducted(db, orderID)
+	if err != nil {
+		return false, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if len(logs) == 0 {
+		return false, nil
+	}
+	for _, log := range logs {
+		rows, e := s.stockDAO.MarkRestored(db, log.ID)
+		if e != nil {
+			return false, errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if rows == 0 {
+			continue
+		}
+		if e = s.dishDAO.RestoreStock(db, log.DishID, log.Number); e != nil {
+			return false, errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+	}
+	rows, err := s.dishDAO.EnableRestocked(db)
+	if err != nil {
+		return false, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return rows > 0, nil
+}
+
+// ResetDaily 按每日库存重置菜品库存，并起售因售罄停售的菜品
+// 重置前扣减的库存流水作废，之后取消的订单不会再把库存加到新的一天
+func (s *StockService) ResetDaily(db *gorm.DB) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.dishDAO.ResetDailyStock(tx); err != nil {
+			return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if err := s.stockDAO.ExpireDaily(tx); err != nil {
+			return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if _, err := s.dishDAO.EnableRestocked(tx); err != nil {
+			return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.cleanCache()
+	return nil
+}
+
+// dishNumbers 将商品折算为每个菜品需要的份数，套餐按所含菜品的份数展开
+func (s *StockService) dishNumbers(db *gorm.DB, lines []stockLine) (map[int]int, error) {
+	need := make(map[int]int)
+	setmealNumbers := make(map[int]int)
+	for _, line := range lines {
+		if line.DishID != 0 {
+			need[line.DishID] += line.Number
+		} else if line.SetmealID != 0 {
+			setmealNumbers[line.SetmealID] += line.Number
+		}
+	}
+	if len(setmealNumbers) == 0 {
+		return need, nil
+	}
+	setmealDishes, err := s.setmealDishDAO.ListBySetmealIDs(db, sortedKeys(setmealNumbers))
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	for _, sd := range setmealDishes {
+		need[sd.DishID] += sd.Copies * setmealNumbers[sd.SetmealID]
+	}
+	return need, nil
+}
+
+// 菜品起售状态变化后，用户端的菜品和套餐缓存都需要清除
+func (s *StockService) cleanCache() {
+	if err := utils.CleanCache(constant.CacheDishKey + "*"); err != nil {
+		logger.Error(constant.MsgCacheError, zap.Error(err))
+	}
+	if err := utils.CleanCache(constant.CacheSetmealKey + "*"); err != nil {
+		logger.Error(constant.MsgCacheError, zap.Error(err))
+	}
+}
+
+func sortedKeys(m map[int]int) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
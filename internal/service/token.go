@@ -0,0 +1,37 @@
+package service
+
+import (
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/utils"
+	"takeout/model/dto"
+	"takeout/model/vo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenService 三端共用的令牌刷新和退出登录
+type TokenService struct{}
+
+// Refresh 使用刷新令牌换取新的访问令牌和刷新令牌
+func (s *TokenService) Refresh(ctx *gin.Context, aud string, refreshDTO *dto.RefreshTokenDTO) (*vo.TokenVO, error) {
+	tokens, err := utils.RefreshTokens(ctx, aud, refreshDTO.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	return &vo.TokenVO{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}, nil
+}
+
+// Logout 注销当前会话，由 Jwt 中间件写入账号 ID 和会话 ID
+func (s *TokenService) Logout(ctx *gin.Context, aud string) error {
+	id := ctx.GetString(constant.ID)
+	sid := ctx.GetString(constant.SessionID)
+	if id == "" || sid == "" {
+		return errs.New(constant.CodeUnauthorized, constant.MsgGetAccountInfoFail)
+	}
+	return utils.RevokeSession(ctx, aud, id, sid)
+}
@@ -1,9 +1,9 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"gorm.io/gorm"
-	"strconv"
 	"takeout/common/constant"
 	"takeout/common/errs"
 	"takeout/common/global"
@@ -44,14 +44,16 @@ func (s *UserService) Login(userLoginDTO *dto.UserLoginDTO) (*vo.UserLoginVO, er
 		}
 	}
 	// 生成token
-	token, err := utils.GenerateToken(constant.UserID, strconv.Itoa(user.ID))
+	tokens, err := utils.IssueTokens(context.Background(), constant.AudienceUser, user.ID)
 	if err != nil {
-		return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgServerError)
+		return nil, err
 	}
 	// 返回参数
 	return &vo.UserLoginVO{
-		ID:     user.ID,
-		OpenID: openid,
-		Token:  token,
+		ID:           user.ID,
+		OpenID:       openid,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
 	}, nil
 }
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"takeout/common/constant"
+	"takeout/common/errs"
+	"takeout/common/global"
+	"takeout/common/logger"
+	"takeout/common/payment"
+	"takeout/common/utils"
+	"takeout/internal/dao"
+	"takeout/model/dto"
+	"takeout/model/entity"
+	"takeout/model/vo"
+)
+
+// WalletService 储值钱包服务，每次余额变动都锁定钱包行并写入流水
+type WalletService struct {
+	walletDAO dao.WalletDAO
+	userDAO   dao.UserDAO
+}
+
+// Info 查询余额
+func (s *WalletService) Info(ctx *gin.Context) (*vo.WalletVO, error) {
+	userID, err := utils.GetId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := s.walletDAO.GetBalance(global.DB, userID)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return &vo.WalletVO{Balance: balance}, nil
+}
+
+// Page 用户分页查询自己的余额流水
+func (s *WalletService) Page(ctx *gin.Context, queryDTO *dto.WalletPageQueryDTO) (*vo.PageResult, error) {
+	userID, err := utils.GetId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	queryDTO.UserID = userID
+	return s.PageQuery(queryDTO)
+}
+
+// PageQuery 商家分页查询余额流水
+func (s *WalletService) PageQuery(queryDTO *dto.WalletPageQueryDTO) (*vo.PageResult, error) {
+	total, list, err := s.walletDAO.PageByUser(global.DB, queryDTO.UserID, queryDTO.Page, queryDTO.PageSize)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return &vo.PageResult{Total: total, Records: list}, nil
+}
+
+// Recharge 创建充值单并向支付渠道预下单，支付成功后由回调入账
+func (s *WalletService) Recharge(ctx *gin.Context, rechargeDTO *dto.WalletRechargeDTO) (*vo.RechargeVO, error) {
+	userID, err := utils.GetId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	amount := rechargeDTO.Amount
+	if !amount.IsPositive() || !amount.Equal(amount.Round(2)) ||
+		amount.GreaterThan(decimal.NewFromInt(constant.RechargeMaxAmount)) {
+		return nil, errs.New(constant.CodeBadRequest, constant.MsgWalletAmountInvalid)
+	}
+	user, err := s.userDAO.GetByID(global.DB, userID)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	number, err := utils.NextSerialNumber("recharge", constant.RechargePrefix)
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeCacheError, constant.MsgCacheError)
+	}
+	recharge := &entity.WalletRecharge{
+		Number: number,
+		UserID: userID,
+		Amount: amount,
+		Status: constant.RechargeUnpaid,
+	}
+	if err = s.walletDAO.CreateRecharge(global.DB, recharge); err != nil {
+		return nil, errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	result, err := payment.GetProvider().Prepay(&payment.PrepayRequest{
+		OrderNumber: recharge.Number,
+		Description: "余额充值",
+		Amount:      recharge.Amount,
+		OpenID:      user.OpenID,
+	})
+	if err != nil {
+		return nil, errs.Wrap(err, constant.CodeInternalError, constant.MsgRechargeFail)
+	}
+	return &vo.RechargeVO{
+		Number: recharge.Number,
+		Amount: recharge.Amount,
+		Payment: &vo.OrderPaymentVO{
+			NonceStr:   result.NonceStr,
+			PaySign:    result.PaySign,
+			TimeStamp:  result.TimeStamp,
+			SignType:   result.SignType,
+			PackageStr: result.Package,
+		},
+	}, nil
+}
+
+// RechargeSuccess 充值单支付成功后入账，回调重复推送时只入账一次
+func (s *WalletService) RechargeSuccess(number string) error {
+	recharge, err := s.walletDAO.GetRechargeByNumber(global.DB, number)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.Wrap(err, constant.CodeBusinessError, constant.MsgRechargeNotFound)
+		}
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	if recharge.Status == constant.RechargePaid {
+		return nil
+	}
+	return global.DB.Transaction(func(tx *gorm.DB) error {
+		// 已关闭的充值单也可能在关闭后才收到付款，同样入账
+		from := []int{constant.RechargeUnpaid, constant.RechargeClosed}
+		rows, e := s.walletDAO.UpdateRechargeStatus(tx, recharge.ID, from, constant.RechargePaid)
+		if e != nil {
+			return errs.Wrap(e, constant.CodeDatabaseError, constant.MsgDatabaseError)
+		}
+		if rows == 0 {
+			return nil
+		}
+		return s.change(tx, &entity.WalletLog{
+			UserID: recharge.UserID,
+			Type:   constant.WalletRecharge,
+			Amount: recharge.Amount,
+			Number: recharge.Number,
+			Reason: "余额充值",
+		})
+	})
+}
+
+// CloseUnpaid 处理超时未支付的充值单：向支付渠道确认一次，已付款的入账，未付款的关闭
+func (s *WalletService) CloseUnpaid() error {
+	list, err := s.walletDAO.ListUnpaidRecharge(global.DB, time.Now().Add(-15*time.Minute))
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	for _, recharge := range list {
+		if result, e := payment.GetProvider().Query(recharge.Number); e == nil && result.Paid {
+			if e = s.RechargeSuccess(recharge.Number); e != nil {
+				logger.Error(constant.MsgRechargeFail, zap.String("number", recharge.Number), zap.Error(e))
+			}
+			continue
+		}
+		from := []int{constant.RechargeUnpaid}
+		if _, e := s.walletDAO.UpdateRechargeStatus(global.DB, recharge.ID, from, constant.RechargeClosed); e != nil {
+			logger.Error(constant.MsgUpdateFail, zap.String("number", recharge.Number), zap.Error(e))
+		}
+	}
+	return nil
+}
+
+// Adjust 商家人工调整余额，扣减后余额不能为负
+func (s *WalletService) Adjust(ctx *gin.Context, adjustDTO *dto.WalletAdjustDTO) error {
+	empID, err := utils.GetId(ctx)
+	if err != nil {
+		return err
+	}
+	reason := strings.TrimSpace(adjustDTO.Reason)
+	if reason == "" {
+		return errs.New(constant.CodeBadRequest, constant.MsgWalletReasonMissing)
+	}
+	if _, err = s.userDAO.GetByID(global.DB, adjustDTO.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.New(constant.CodeNotFound, constant.MsgUserNotFound)
+		}
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return global.DB.Transaction(func(tx *gorm.DB) error {
+		return s.change(tx, &entity.WalletLog{
+			UserID:     adjustDTO.UserID,
+			Type:       constant.WalletAdjust,
+			Amount:     adjustDTO.Amount,
+			Reason:     reason,
+			OperatorID: empID,
+		})
+	})
+}
+
+// Pay 使用余额支付订单的实付金额，需要与订单状态流转放在同一个事务中
+func (s *WalletService) Pay(db *gorm.DB, order *entity.Order) error {
+	// 优惠后实付为 0 的订单不需要扣款
+	if order.Amount.IsZero() {
+		return nil
+	}
+	return s.change(db, &entity.WalletLog{
+		UserID:  order.UserID,
+		Type:    constant.WalletPay,
+		Amount:  order.Amount.Neg(),
+		OrderID: order.ID,
+		Number:  order.Number,
+		Reason:  "订单支付",
+	})
+}
+
+// Refund 余额支付的订单退款时原路退回余额，立即到账，需要与取消订单放在同一个事务中
+func (s *WalletService) Refund(db *gorm.DB, order *entity.Order) error {
+	paid, err := s.walletDAO.SumByOrder(db, order.ID, constant.WalletPay)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	refunded, err := s.walletDAO.SumByOrder(db, order.ID, constant.WalletRefund)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	// 支付记录为负数，退款记录为正数
+	amount := paid.Neg().Sub(refunded)
+	if !amount.IsPositive() {
+		return nil
+	}
+	return s.change(db, &entity.WalletLog{
+		UserID:  order.UserID,
+		Type:    constant.WalletRefund,
+		Amount:  amount,
+		OrderID: order.ID,
+		Number:  order.Number,
+		Reason:  "订单退款",
+	})
+}
+
+// change 锁定钱包后修改余额并写入流水，余额不能为负
+func (s *WalletService) change(db *gorm.DB, log *entity.WalletLog) error {
+	log.Amount = log.Amount.Round(2)
+	if log.Amount.IsZero() {
+		return errs.New(constant.CodeBadRequest, constant.MsgWalletAmountInvalid)
+	}
+	wallet, err := s.walletDAO.GetForUpdate(db, log.UserID)
+	if err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	balance := wallet.Balance.Add(log.Amount)
+	if balance.IsNegative() {
+		return errs.New(constant.CodeBusinessError, constant.MsgWalletNotEnough)
+	}
+	if err = s.walletDAO.UpdateBalance(db, wallet.ID, balance); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	log.Balance = balance
+	if err = s.walletDAO.CreateLog(db, log); err != nil {
+		return errs.Wrap(err, constant.CodeDatabaseError, constant.MsgDatabaseError)
+	}
+	return nil
+}
@@ -5,7 +5,10 @@ import (
 	"takeout/common/constant"
 	"takeout/common/global"
 	"takeout/common/logger"
+	"takeout/common/payment"
 	"takeout/internal/dao"
+	"takeout/internal/service"
+	"takeout/model/entity"
 	"takeout/model/wrap"
 	"time"
 
@@ -24,12 +27,38 @@ func Init() error {
 		logger.Error("初始化定时任务失败", zap.Error(err))
 		return err
 	}
+	if _, err := timerTask.AddFunc("30 * * * * ?", orderTask.handleScheduledOrder); err != nil {
+		logger.Error("初始化定时任务失败", zap.Error(err))
+		return err
+	}
+	refundTask := NewRefundTask()
+	if _, err := timerTask.AddFunc("0 */5 * * * ?", refundTask.handleStuckRefund); err != nil {
+		logger.Error("初始化定时任务失败", zap.Error(err))
+		return err
+	}
+	walletTask := NewWalletTask()
+	if _, err := timerTask.AddFunc("15 * * * * ?", walletTask.handleUnpaidRecharge); err != nil {
+		logger.Error("初始化定时任务失败", zap.Error(err))
+		return err
+	}
+	printTask := NewPrintTask()
+	if _, err := timerTask.AddFunc("45 * * * * ?", printTask.handleFailedPrint); err != nil {
+		logger.Error("初始化定时任务失败", zap.Error(err))
+		return err
+	}
+	stockTask := NewStockTask()
+	if _, err := timerTask.AddFunc("0 0 0 * * ?", stockTask.handleDailyStock); err != nil {
+		logger.Error("初始化定时任务失败", zap.Error(err))
+		return err
+	}
 	timerTask.Start()
 	return nil
 }
 
 type OrderTask struct {
-	orderDAO dao.OrderDAO
+	orderDAO     dao.OrderDAO
+	stateMachine service.OrderStateMachine
+	orderService service.OrderService
 }
 
 func NewOrderTask() *OrderTask {
@@ -47,13 +76,26 @@ func (t *OrderTask) handleTimeoutOrder() {
 	}
 	if orders != nil && len(orders) != 0 {
 		for _, order := range orders {
-			order.Status = constant.Cancelled
-			order.CancelReason = "订单超时"
-			order.CancelTime = wrap.LocalTime(time.Now())
-			err = t.orderDAO.Update(global.DB, order)
+			// 关单前向支付渠道确认一次，避免支付回调丢失导致已付款的订单被取消
+			if result, e := payment.GetProvider().Query(order.Number); e == nil && result.Paid {
+				if e = t.orderService.PaySuccess(order.Number); e != nil {
+					logger.Error(constant.MsgUpdateFail, zap.Int("orderId", order.ID), zap.Error(e))
+				}
+				continue
+			}
+			err = t.stateMachine.Transit(global.DB, &service.OrderTransition{
+				Order:        order,
+				To:           constant.Cancelled,
+				OperatorType: constant.OperatorSystem,
+				Reason:       "订单超时",
+				Updates: &entity.Order{
+					CancelReason: "订单超时",
+					CancelTime:   wrap.LocalTime(time.Now()),
+				},
+			})
 			if err != nil {
-				logger.Error(constant.MsgDatabaseError, zap.Error(err))
-				return
+				// 订单可能刚好被支付，跳过继续处理其它订单
+				logger.Error(constant.MsgOrderCancelFail, zap.Int("orderId", order.ID), zap.Error(err))
 			}
 		}
 	}
@@ -69,11 +111,23 @@ func (t *OrderTask) handleDeliveryOrder() {
 	}
 	if orders != nil && len(orders) != 0 {
 		for _, order := range orders {
-			order.Status = constant.Completed
-			if err = t.orderDAO.Update(global.DB, order); err != nil {
-				logger.Error(constant.MsgDatabaseError, zap.Error(err))
-				return
+			err = t.stateMachine.Transit(global.DB, &service.OrderTransition{
+				Order:        order,
+				To:           constant.Completed,
+				OperatorType: constant.OperatorSystem,
+				Reason:       "派送超时自动完成",
+				Updates:      &entity.Order{DeliveryTime: wrap.LocalTime(time.Now())},
+			})
+			if err != nil {
+				logger.Error(constant.MsgUpdateFail, zap.Int("orderId", order.ID), zap.Error(err))
 			}
 		}
 	}
 }
+
+// 预约单到出餐提前期后推送给商家
+func (t *OrderTask) handleScheduledOrder() {
+	if err := t.orderService.ReleaseScheduled(); err != nil {
+		logger.Error("推送预约订单失败", zap.Error(err))
+	}
+}
@@ -0,0 +1,25 @@
+package task
+
+import (
+	"go.uber.org/zap"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/internal/service"
+	"time"
+)
+
+type PrintTask struct {
+	printService service.PrintService
+}
+
+func NewPrintTask() *PrintTask {
+	return &PrintTask{}
+}
+
+// 重试打印失败的小票
+func (t *PrintTask) handleFailedPrint() {
+	logger.Info("处理打印失败任务", zap.Time("time", time.Now()))
+	if err := t.printService.RetryFailed(); err != nil {
+		logger.Error(constant.MsgPrintFail, zap.Error(err))
+	}
+}
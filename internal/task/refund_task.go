@@ -0,0 +1,25 @@
+package task
+
+import (
+	"go.uber.org/zap"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/internal/service"
+	"time"
+)
+
+type RefundTask struct {
+	refundService service.RefundService
+}
+
+func NewRefundTask() *RefundTask {
+	return &RefundTask{}
+}
+
+// 重试卡住的退款单
+func (t *RefundTask) handleStuckRefund() {
+	logger.Info("处理未完成退款", zap.Time("time", time.Now()))
+	if err := t.refundService.RetryStuck(); err != nil {
+		logger.Error(constant.MsgRefundFail, zap.Error(err))
+	}
+}
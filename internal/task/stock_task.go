@@ -0,0 +1,25 @@
+package task
+
+import (
+	"go.uber.org/zap"
+	"takeout/common/global"
+	"takeout/common/logger"
+	"takeout/internal/service"
+	"time"
+)
+
+type StockTask struct {
+	stockService service.StockService
+}
+
+func NewStockTask() *StockTask {
+	return &StockTask{}
+}
+
+// 每天零点重置菜品库存
+func (t *StockTask) handleDailyStock() {
+	logger.Info("重置每日库存", zap.Time("time", time.Now()))
+	if err := t.stockService.ResetDaily(global.DB); err != nil {
+		logger.Error("重置每日库存失败", zap.Error(err))
+	}
+}
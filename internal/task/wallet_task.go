@@ -0,0 +1,25 @@
+package task
+
+import (
+	"go.uber.org/zap"
+	"takeout/common/constant"
+	"takeout/common/logger"
+	"takeout/internal/service"
+	"time"
+)
+
+type WalletTask struct {
+	walletService service.WalletService
+}
+
+func NewWalletTask() *WalletTask {
+	return &WalletTask{}
+}
+
+// 处理超时未支付的充值单
+func (t *WalletTask) handleUnpaidRecharge() {
+	logger.Info("处理未支付充值单", zap.Time("time", time.Now()))
+	if err := t.walletService.CloseUnpaid(); err != nil {
+		logger.Error(constant.MsgRechargeFail, zap.Error(err))
+	}
+}
@@ -1,13 +1,33 @@
 package websocket
 
 import (
-	"github.com/gin-gonic/gin"
-	"github.com/gorilla/websocket"
-	"go.uber.org/zap"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"sync"
 	"takeout/common/constant"
+	"takeout/common/global"
 	"takeout/common/logger"
+	"takeout/common/response"
+	"takeout/common/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	writeWait      = 10 * time.Second  // 单次写消息的超时时间
+	pongWait       = 60 * time.Second  // 超过这个时间没有收到 pong 视为连接已断开
+	pingPeriod     = pongWait * 9 / 10 // 发送 ping 的间隔，必须小于 pongWait
+	maxMessageSize = 512               // 客户端消息的最大长度
+	sendBufferSize = 64                // 每个连接的发送队列长度
+
+	ChannelMerchant = "merchant" // 商家端频道
+	channelUser     = "user:"    // 用户频道前缀，后接用户ID
+	channelRider    = "rider:"   // 骑手频道前缀，后接骑手ID
 )
 
 // 定义websocket的连接配置
@@ -20,103 +40,226 @@ var upGrader = websocket.Upgrader{
 	},
 }
 
-// Server websocketServer
-type Server struct {
-	conns   map[string]*websocket.Conn // 使用map来管理连接
-	handler WSEventHandler             // WebSocket事件处理程序
-	mutex   sync.Mutex                 // 互斥锁，用于保护conns的并发访问
+// Client 一个 websocket 连接，消息通过 send 队列交给独立的写协程发送
+type Client struct {
+	sid     string
+	channel string
+	conn    *websocket.Conn
+	send    chan []byte
+	once    sync.Once
 }
 
-// WSEventHandler 定义WebSocket事件处理接口
-type WSEventHandler interface {
-	OnOpen(clientId string, conn *websocket.Conn)
-	OnMessage(clientId string, message string)
-	OnClose(clientId string)
+// close 关闭发送队列，写协程随之退出并关闭连接，可以重复调用
+func (c *Client) close() {
+	c.once.Do(func() {
+		close(c.send)
+	})
 }
 
-// WSServer 创建websocketServer并初始化
-var WSServer = Server{
-	conns:   make(map[string]*websocket.Conn),
-	handler: &DefaultEventHandler{},
-	mutex:   sync.Mutex{},
+// Hub 按频道管理连接
+type Hub struct {
+	channels map[string]map[*Client]struct{}
+	mutex    sync.RWMutex
 }
 
-// DefaultEventHandler 基本的WebSocket事件处理程序实现
-type DefaultEventHandler struct{}
+// WSServer 全局的连接管理
+var WSServer = &Hub{
+	channels: make(map[string]map[*Client]struct{}),
+}
 
-func (h *DefaultEventHandler) OnOpen(clientId string, conn *websocket.Conn) {
-	logger.Info("websocket连接成功", zap.String("sid", clientId))
-	// 将连接存储到map中
-	WSServer.mutex.Lock()
-	defer WSServer.mutex.Unlock()
-	WSServer.conns[clientId] = conn
+func (h *Hub) register(c *Client) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.channels[c.channel] == nil {
+		h.channels[c.channel] = make(map[*Client]struct{})
+	}
+	h.channels[c.channel][c] = struct{}{}
+	logger.Info("websocket连接成功", zap.String("sid", c.sid), zap.String("channel", c.channel))
 }
 
-func (h *DefaultEventHandler) OnMessage(clientId string, message string) {
-	logger.Info("websocket客户端发送消息: %s", zap.String("sid", clientId), zap.String("message", message))
+func (h *Hub) unregister(c *Client) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if clients, ok := h.channels[c.channel]; ok {
+		if _, ok = clients[c]; ok {
+			delete(clients, c)
+			if len(clients) == 0 {
+				delete(h.channels, c.channel)
+			}
+			c.close()
+			logger.Info("websocket连接已断开", zap.String("sid", c.sid), zap.String("channel", c.channel))
+		}
+	}
 }
 
-func (h *DefaultEventHandler) OnClose(clientId string) {
-	WSServer.mutex.Lock()
-	defer WSServer.mutex.Unlock()
-	if conn, ok := WSServer.conns[clientId]; ok {
-		err := conn.Close() // 关闭连接
-		if err != nil {
-			logger.Error("连接关闭错误", zap.Error(err))
+// broadcast 将消息放入频道内每个连接的发送队列，不在锁内做网络写
+// 队列已满说明客户端处理不过来，直接断开
+func (h *Hub) broadcast(channel string, data []byte) {
+	var slow []*Client
+	h.mutex.RLock()
+	for c := range h.channels[channel] {
+		select {
+		case c.send <- data:
+		default:
+			slow = append(slow, c)
+		}
+	}
+	h.mutex.RUnlock()
+	for _, c := range slow {
+		logger.Info("websocket发送队列已满，已移除连接", zap.String("sid", c.sid))
+		h.unregister(c)
+	}
+}
+
+// SendToChannel 发送消息给频道内的所有连接
+func SendToChannel(channel string, jsonMsg any) {
+	data, err := json.Marshal(jsonMsg)
+	if err != nil {
+		logger.Error(constant.MsgMarshalFail, zap.Error(err))
+		return
+	}
+	WSServer.broadcast(channel, data)
+}
+
+// SendToMerchant 发送消息给商家端
+func SendToMerchant(jsonMsg any) {
+	SendToChannel(ChannelMerchant, jsonMsg)
+}
+
+// SendToUser 发送消息给指定用户
+func SendToUser(userID int, jsonMsg any) {
+	SendToChannel(channelUser+strconv.Itoa(userID), jsonMsg)
+}
+
+// SendToRider 发送消息给指定骑手
+func SendToRider(riderID int, jsonMsg any) {
+	SendToChannel(channelRider+strconv.Itoa(riderID), jsonMsg)
+}
+
+// PingPeriod 长连接的心跳间隔，SSE 推送复用
+const PingPeriod = pingPeriod
+
+// SubscribeUser 订阅指定用户的频道，供 SSE 等非 websocket 连接接收推送
+// 返回的通道在取消订阅或客户端处理过慢被移除时关闭，调用方结束时必须调用 cancel
+func SubscribeUser(sid string, userID int) (<-chan []byte, func()) {
+	client := &Client{
+		sid:     sid,
+		channel: channelUser + strconv.Itoa(userID),
+		send:    make(chan []byte, sendBufferSize),
+	}
+	WSServer.register(client)
+	return client.send, func() { WSServer.unregister(client) }
+}
+
+// authenticate 根据 token 确定连接所属的频道
+// 浏览器建立 websocket 连接时不能自定义请求头，token 也可以放在 query 参数中
+func authenticate(ctx *gin.Context) (string, error) {
+	jwtConfig := global.Config.JWT
+	roles := []struct {
+		header  string
+		aud     string
+		channel func(id string) string
+	}{
+		{jwtConfig.AdminTokenName, constant.AudienceAdmin, func(string) string { return ChannelMerchant }},
+		{jwtConfig.UserTokenName, constant.AudienceUser, func(id string) string { return channelUser + id }},
+		{jwtConfig.RiderTokenName, constant.AudienceRider, func(id string) string { return channelRider + id }},
+	}
+	for _, role := range roles {
+		token := ctx.GetHeader(role.header)
+		if token == "" {
+			token = ctx.Query("token")
+		}
+		if token == "" {
+			continue
+		}
+		if claims, err := utils.ParseToken(ctx, token, role.aud); err == nil {
+			return role.channel(claims.Subject), nil
 		}
-		delete(WSServer.conns, clientId) // 从map中删除连接
-		logger.Info("websocket连接已断开", zap.String("sid", clientId))
 	}
+	return "", errors.New(constant.MsgUnauthorized)
 }
 
 // WSHandler WebSocket处理器
 func WSHandler(ctx *gin.Context) {
+	clientId := ctx.Param("sid")
+	if clientId == "" {
+		logger.Error(constant.MsgMissingRequest)
+		response.BadRequest(ctx, constant.MsgMissingRequest)
+		return
+	}
+	channel, err := authenticate(ctx)
+	if err != nil {
+		logger.Error(constant.MsgJWTParseFail, zap.String("sid", clientId))
+		response.Unauthorized(ctx, constant.MsgUnauthorized)
+		return
+	}
+
 	// 升级HTTP连接到WebSocket连接
 	conn, err := upGrader.Upgrade(ctx.Writer, ctx.Request, nil)
 	if err != nil {
 		logger.Error("WebSocket升级失败", zap.Error(err))
 		return
 	}
-	clientId := ctx.Param("sid")
-	if clientId == "" {
-		logger.Error(constant.MsgMissingRequest)
-		return
+	client := &Client{
+		sid:     clientId,
+		channel: channel,
+		conn:    conn,
+		send:    make(chan []byte, sendBufferSize),
 	}
+	WSServer.register(client)
 
-	// 调用OnOpen事件
-	WSServer.handler.OnOpen(clientId, conn)
+	go client.writePump()
+	client.readPump()
+}
 
-	// 处理连接关闭
-	defer func() {
-		WSServer.handler.OnClose(clientId)
-	}()
+// readPump 读取客户端消息，并通过 pong 续期读超时，超时未收到 pong 的连接会被移除
+func (c *Client) readPump() {
+	defer WSServer.unregister(c)
 
-	// 监听客户端消息
+	c.conn.SetReadLimit(maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
 	for {
-		_, message, e := conn.ReadMessage()
-		if e != nil {
-			logger.Info("websocket读取消息失败", zap.String("sid", clientId), zap.Error(err))
-			break // 读取失败，跳出循环
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			logger.Info("websocket读取消息失败", zap.String("sid", c.sid), zap.Error(err))
+			return
 		}
-
-		// 调用OnMessage事件
-		WSServer.handler.OnMessage(clientId, string(message))
+		logger.Info("websocket客户端发送消息", zap.String("sid", c.sid), zap.String("message", string(message)))
 	}
 }
 
-// SendToAllClients 发送消息给所有客户端
-func SendToAllClients(jsonMsg any) {
-	WSServer.mutex.Lock()
-	defer WSServer.mutex.Unlock()
-
-	for clientId, conn := range WSServer.conns {
-		if err := conn.WriteJSON(jsonMsg); err != nil {
-			e := conn.Close()
-			if e != nil {
-				logger.Error("连接关闭错误", zap.Error(err))
+// writePump 每个连接独立的写协程，负责发送队列中的消息和定时 ping
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		if err := c.conn.Close(); err != nil {
+			logger.Error("连接关闭错误", zap.Error(err))
+		}
+	}()
+	for {
+		select {
+		case message, ok := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				// 发送队列已关闭
+				_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				logger.Info("websocket发送消息失败，已移除连接", zap.String("sid", c.sid), zap.Error(err))
+				WSServer.unregister(c)
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				WSServer.unregister(c)
+				return
 			}
-			delete(WSServer.conns, clientId)
-			logger.Info("websocket发送消息失败，已移除连接", zap.String("sid", clientId))
 		}
 	}
 }
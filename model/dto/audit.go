@@ -0,0 +1,13 @@
+package dto
+
+// AuditPageQueryDTO 操作日志分页查询，时间格式 yyyy-MM-dd HH:mm:ss
+type AuditPageQueryDTO struct {
+	OperatorID int    `form:"operatorId"`
+	EntityType string `form:"entityType"`
+	EntityID   int    `form:"entityId"`
+	Action     string `form:"action"`
+	BeginTime  string `form:"beginTime"`
+	EndTime    string `form:"endTime"`
+	Page       int    `form:"page"`
+	PageSize   int    `form:"pageSize"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"takeout/model/wrap"
+)
+
+// CouponTemplateDTO 新增、修改优惠券模板DTO
+type CouponTemplateDTO struct {
+	ID           int             `json:"id"`
+	Name         string          `json:"name" binding:"required"`
+	Type         int             `json:"type" binding:"required"`
+	Amount       decimal.Decimal `json:"amount"`
+	Discount     decimal.Decimal `json:"discount"`
+	MaxDiscount  decimal.Decimal `json:"maxDiscount"`
+	Threshold    decimal.Decimal `json:"threshold"`
+	ScopeType    int             `json:"scopeType"`
+	ScopeIDs     []int           `json:"scopeIds"`
+	ValidFrom    wrap.LocalTime  `json:"validFrom"`
+	ValidTo      wrap.LocalTime  `json:"validTo"`
+	ValidDays    int             `json:"validDays"`
+	TotalCount   int             `json:"totalCount"`
+	PerUserLimit int             `json:"perUserLimit"`
+}
+
+// CouponPageQueryDTO 优惠券模板分页查询DTO
+type CouponPageQueryDTO struct {
+	Name     string `form:"name"`
+	Status   int    `form:"status"`
+	Page     int    `form:"page" binding:"required"`
+	PageSize int    `form:"pageSize" binding:"required"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+// DeliverySlotDTO 新增、修改配送时段DTO
+type DeliverySlotDTO struct {
+	ID        int    `json:"id"`
+	StartTime string `json:"startTime" binding:"required"`
+	EndTime   string `json:"endTime" binding:"required"`
+	Capacity  int    `json:"capacity" binding:"required,min=1"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"takeout/common/geo"
+)
+
+// DeliveryZoneDTO 新增、修改配送区域DTO
+type DeliveryZoneDTO struct {
+	ID          int             `json:"id"`
+	Name        string          `json:"name" binding:"required"`
+	Type        int             `json:"type" binding:"required"`
+	Radius      int             `json:"radius"`
+	Polygon     []geo.Point     `json:"polygon"`
+	MinAmount   decimal.Decimal `json:"minAmount"`
+	DeliveryFee decimal.Decimal `json:"deliveryFee"`
+	Sort        int             `json:"sort"`
+}
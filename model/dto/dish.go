@@ -14,6 +14,8 @@ type DishDTO struct {
 	Image       string               `json:"image"`
 	Description string               `json:"description"`
 	Status      int                  `json:"status"`
+	Stock       *int                 `json:"stock"`      // 为空表示不限量，修改时不传即取消限量
+	DailyStock  *int                 `json:"dailyStock"` // 为空表示不自动重置
 	Flavors     []*entity.DishFlavor `json:"flavors"`
 }
 
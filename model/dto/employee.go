@@ -2,8 +2,10 @@ package dto
 
 // EmployeeLoginDTO 员工登录请求DTO
 type EmployeeLoginDTO struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Username    string `json:"username" binding:"required"`
+	Password    string `json:"password" binding:"required"`
+	CaptchaID   string `json:"captchaId"`   // 连续失败后需要验证码
+	CaptchaCode string `json:"captchaCode"` // 验证码答案
 }
 
 /*
@@ -28,6 +30,7 @@ type EmployeeCreateDTO struct {
 	Phone    string `json:"phone"`
 	Sex      string `json:"sex"`
 	IdNumber string `json:"idNumber"`
+	RoleID   int    `json:"roleId"` // 不传时默认为收银角色
 }
 
 // EmployeeUpdateDTO 员工信息更新请求DTO
@@ -38,12 +41,13 @@ type EmployeeUpdateDTO struct {
 	Phone    string `json:"phone"`
 	Sex      string `json:"sex"`
 	IdNumber string `json:"idNumber"`
+	RoleID   int    `json:"roleId"` // 不传时保持原角色
 }
 
 // EmployeePasswordDTO 员工密码修改请求DTO
 type EmployeePasswordDTO struct {
-	EmpId       int    `json:"empId" binding:"required"`
-	OldPassword string `json:"oldPassword" binding:"required"`
+	EmpId       int    `json:"empId"`       // 不传时修改自己的密码，修改他人密码需要 employee:password 权限
+	OldPassword string `json:"oldPassword"` // 修改自己的密码时必填
 	NewPassword string `json:"newPassword" binding:"required"`
 }
 
@@ -0,0 +1,15 @@
+package dto
+
+// KitchenStationDTO 新增、修改档口DTO
+type KitchenStationDTO struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name" binding:"required"`
+	Sort        int    `json:"sort"`
+	CategoryIDs []int  `json:"categoryIds"` // 负责的分类
+}
+
+// KitchenTicketQueryDTO 后厨工单查询
+type KitchenTicketQueryDTO struct {
+	StationID *int `form:"stationId"` // 不传时查询全部档口，0 为默认档口
+	Status    int  `form:"status"`    // 不传时查询待制作和制作中的工单
+}
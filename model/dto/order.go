@@ -17,6 +17,9 @@ type OrderSubmitDTO struct {
 	Remark                string          `json:"remark"`
 	TablewareNumber       int             `json:"tablewareNumber"`
 	TablewareStatus       int             `json:"tablewareStatus"`
+	CouponID              int             `json:"couponId"`       // 使用的用户优惠券，0 表示不使用
+	DeliverySlotID        int             `json:"deliverySlotId"` // 预约送达时选择的配送时段
+	Points                int             `json:"points"`         // 抵扣使用的积分，0 表示不使用
 }
 
 type OrderDTO struct {
@@ -53,6 +56,7 @@ type OrderPageQueryDTO struct {
 	Status    int    `form:"status"`
 	BeginTime string `form:"beginTime"`
 	EndTime   string `form:"endTime"` // query中的时间不太好绑定
+	Held      int    `form:"held"`    // 商家端：0 隐藏未到出餐时间的预约单，1 只查询这些预约单；用户端不过滤
 }
 
 // OrderPageQueryNonDTO 订单分页查询数据模型
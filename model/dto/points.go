@@ -0,0 +1,15 @@
+package dto
+
+// PointsPageQueryDTO 积分流水分页查询
+type PointsPageQueryDTO struct {
+	UserID   int `form:"userId"` // 商家端按用户筛选，用户端忽略
+	Page     int `form:"page" binding:"required"`
+	PageSize int `form:"pageSize" binding:"required"`
+}
+
+// PointsAdjustDTO 商家人工调整积分DTO
+type PointsAdjustDTO struct {
+	UserID int    `json:"userId" binding:"required"`
+	Change int    `json:"change" binding:"required"` // 正数增加，负数扣减
+	Reason string `json:"reason" binding:"required"`
+}
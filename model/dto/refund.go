@@ -0,0 +1,9 @@
+package dto
+
+// RefundPageQueryDTO 退款单分页查询
+type RefundPageQueryDTO struct {
+	OrderNumber string `form:"orderNumber"`
+	Status      int    `form:"status"` // 1 已申请 2 处理中 3 成功 4 失败，不传时查询全部
+	Page        int    `form:"page" binding:"required"`
+	PageSize    int    `form:"pageSize" binding:"required"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+// ReviewDTO 用户提交订单评价DTO
+type ReviewDTO struct {
+	OrderID int              `json:"orderId" binding:"required"`
+	Rating  int              `json:"rating" binding:"required"`
+	Content string           `json:"content"`
+	Images  []string         `json:"images"` // 通过上传接口得到的图片地址
+	Items   []*ReviewItemDTO `json:"items"`  // 对订单中商品的评分，可以只评部分商品
+}
+
+// ReviewItemDTO 单个商品的评分
+type ReviewItemDTO struct {
+	OrderDetailID int    `json:"orderDetailId" binding:"required"`
+	Rating        int    `json:"rating" binding:"required"`
+	Content       string `json:"content"`
+}
+
+// ReviewPageQueryDTO 商家分页查询评价
+type ReviewPageQueryDTO struct {
+	Rating   int  `form:"rating"`  // 按订单评分筛选，0 表示全部
+	DishID   int  `form:"dishId"`  // 只看包含该菜品评分的评价
+	Status   *int `form:"status"`  // 1 显示 0 隐藏，不传表示全部
+	Replied  *int `form:"replied"` // 1 已回复 0 未回复，不传表示全部
+	Page     int  `form:"page" binding:"required"`
+	PageSize int  `form:"pageSize" binding:"required"`
+}
+
+// ReviewReplyDTO 商家回复评价DTO
+type ReviewReplyDTO struct {
+	ID    int    `json:"id" binding:"required"`
+	Reply string `json:"reply" binding:"required"`
+}
+
+// LowRatedQueryDTO 低分菜品报表查询条件
+type LowRatedQueryDTO struct {
+	Threshold float64 `form:"threshold"` // 平均分低于该值，默认 3
+	MinCount  int     `form:"minCount"`  // 评分数不少于该值，默认 3
+	Begin     string  `form:"begin"`     // 评价时间范围，格式 yyyy-MM-dd，可不传
+	End       string  `form:"end"`
+}
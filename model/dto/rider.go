@@ -0,0 +1,35 @@
+package dto
+
+// RiderLoginDTO 骑手登录DTO
+type RiderLoginDTO struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RiderDTO 新增、修改骑手DTO
+type RiderDTO struct {
+	ID       int    `json:"id"`
+	Username string `json:"username" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Phone    string `json:"phone"`
+}
+
+// RiderPageQueryDTO 骑手分页查询DTO
+type RiderPageQueryDTO struct {
+	Name     string `form:"name"`
+	Status   int    `form:"status"`
+	Page     int    `form:"page" binding:"required"`
+	PageSize int    `form:"pageSize" binding:"required"`
+}
+
+// RiderLocationDTO 骑手上报位置DTO
+type RiderLocationDTO struct {
+	Latitude  float64 `json:"latitude" binding:"required"`
+	Longitude float64 `json:"longitude" binding:"required"`
+}
+
+// OrderAssignDTO 指派骑手DTO，RiderID 为 0 时自动指派
+type OrderAssignDTO struct {
+	OrderID int `json:"id" binding:"required"`
+	RiderID int `json:"riderId"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+// RoleDTO 新增和修改角色共用，修改时 Code 不生效
+type RoleDTO struct {
+	ID          int      `json:"id"`
+	Code        string   `json:"code" binding:"required,max=32"`
+	Name        string   `json:"name" binding:"required,max=32"`
+	Permissions []string `json:"permissions"`
+}
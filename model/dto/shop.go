@@ -0,0 +1,26 @@
+package dto
+
+// BusinessPeriodDTO 营业时段
+type BusinessPeriodDTO struct {
+	OpenTime  string `json:"openTime" binding:"required"`
+	CloseTime string `json:"closeTime" binding:"required"`
+}
+
+// BusinessHoursDTO 某个星期几的营业时段，没有时段表示当天休息
+type BusinessHoursDTO struct {
+	Weekday int                 `json:"weekday" binding:"min=0,max=6"`
+	Periods []BusinessPeriodDTO `json:"periods" binding:"dive"`
+}
+
+// SpecialDayDTO 设置特殊日期的营业安排
+type SpecialDayDTO struct {
+	Date    string              `json:"date" binding:"required"`
+	Closed  bool                `json:"closed"` // 全天休息，为 false 时按 periods 营业
+	Periods []BusinessPeriodDTO `json:"periods" binding:"dive"`
+	Remark  string              `json:"remark"`
+}
+
+// ShopPauseDTO 忙碌暂停接单
+type ShopPauseDTO struct {
+	Minutes int `json:"minutes" binding:"required,min=1,max=240"`
+}
@@ -2,7 +2,21 @@ package dto
 
 // ShoppingCartDTO 添加购物车DTO
 type ShoppingCartDTO struct {
-	DishID     int    `json:"dishId"`
-	SetmealID  int    `json:"setmealId"`
-	DishFlavor string `json:"dishFlavor"`
+	ID            int                `json:"id"` // 购物车行ID，减少商品时传入则按行操作
+	DishID        int                `json:"dishId"`
+	SetmealID     int                `json:"setmealId"`
+	DishFlavor    string             `json:"dishFlavor"` // 旧版客户端传入逗号分隔的选项名称，传了 flavors 时忽略
+	FlavorChoices []*FlavorChoiceDTO `json:"flavors"`
+}
+
+// FlavorChoiceDTO 一个口味组中选择的选项
+type FlavorChoiceDTO struct {
+	Name    string   `json:"name"`    // 口味组名称
+	Options []string `json:"options"` // 选项名称，单选时最多一个
+}
+
+// CartNumberDTO 修改购物车商品数量DTO
+type CartNumberDTO struct {
+	ID     int `json:"id" binding:"required"`
+	Number int `json:"number"` // 为 0 时删除该行
 }
@@ -0,0 +1,6 @@
+package dto
+
+// RefreshTokenDTO 刷新令牌请求
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+// WalletPageQueryDTO 余额流水分页查询
+type WalletPageQueryDTO struct {
+	UserID   int `form:"userId"` // 商家端按用户筛选，用户端忽略
+	Page     int `form:"page" binding:"required"`
+	PageSize int `form:"pageSize" binding:"required"`
+}
+
+// WalletRechargeDTO 余额充值DTO
+type WalletRechargeDTO struct {
+	Amount decimal.Decimal `json:"amount"` // 充值金额，元
+}
+
+// WalletAdjustDTO 商家人工调整余额DTO
+type WalletAdjustDTO struct {
+	UserID int             `json:"userId" binding:"required"`
+	Amount decimal.Decimal `json:"amount"` // 正数增加，负数扣减
+	Reason string          `json:"reason" binding:"required"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "takeout/model/wrap"
+
+// AuditLog 后台操作审计日志，只追加不修改
+// Before、After 只保存发生变化的字段，新增时 Before 为空，删除时 After 为空
+type AuditLog struct {
+	ID         int            `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	OperatorID int            `json:"operatorId" gorm:"column:operator_id;index"`
+	Action     string         `json:"action" gorm:"size:32;not null"`
+	EntityType string         `json:"entityType" gorm:"column:entity_type;size:32;not null;index:idx_audit_entity"`
+	EntityID   int            `json:"entityId" gorm:"column:entity_id;index:idx_audit_entity"` // 新增时为 0
+	Before     string         `json:"before" gorm:"type:text"`
+	After      string         `json:"after" gorm:"type:text"`
+	IP         string         `json:"ip" gorm:"size:64"`
+	RequestID  string         `json:"requestId" gorm:"column:request_id;size:64"`
+	CreateTime wrap.LocalTime `json:"createTime" gorm:"column:create_time;autoCreateTime;index"`
+}
+
+// TableName 设置表名
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
@@ -0,0 +1,29 @@
+package entity
+
+// BusinessHours 每周营业时间，同一天可以有多个时段，时段不跨天
+type BusinessHours struct {
+	ID        int    `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Weekday   int    `json:"weekday" gorm:"not null"`                            // 0 周日，1-6 周一至周六
+	OpenTime  string `json:"openTime" gorm:"column:open_time;size:5;not null"`   // HH:MM
+	CloseTime string `json:"closeTime" gorm:"column:close_time;size:5;not null"` // HH:MM
+}
+
+// TableName 设置表名
+func (BusinessHours) TableName() string {
+	return "business_hours"
+}
+
+// SpecialDay 节假日、特殊日期的营业安排，覆盖当天的每周营业时间
+type SpecialDay struct {
+	ID        int    `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Date      string `json:"date" gorm:"size:10;index;not null"`      // 2006-01-02
+	Closed    int    `json:"closed" gorm:"default:0"`                 // 1 全天休息
+	OpenTime  string `json:"openTime" gorm:"column:open_time;size:5"` // 营业时 HH:MM
+	CloseTime string `json:"closeTime" gorm:"column:close_time;size:5"`
+	Remark    string `json:"remark" gorm:"size:64"`
+}
+
+// TableName 设置表名
+func (SpecialDay) TableName() string {
+	return "special_day"
+}
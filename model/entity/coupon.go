@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"github.com/shopspring/decimal"
+	"takeout/model/wrap"
+)
+
+// CouponTemplate 优惠券模板数据模型，用户领取后生成 UserCoupon
+type CouponTemplate struct {
+	ID           int             `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Name         string          `json:"name" gorm:"not null"`
+	Type         int             `json:"type" gorm:"not null"`                                      // 1 立减 2 折扣 3 满减 4 免打包费
+	Amount       decimal.Decimal `json:"amount" gorm:"type:decimal(10,2)"`                          // 立减、满减的优惠金额
+	Discount     decimal.Decimal `json:"discount" gorm:"type:decimal(4,2)"`                         // 折扣率，如 0.85 表示八五折
+	MaxDiscount  decimal.Decimal `json:"maxDiscount" gorm:"column:max_discount;type:decimal(10,2)"` // 折扣券最多优惠金额，0 表示不限
+	Threshold    decimal.Decimal `json:"threshold" gorm:"type:decimal(10,2)"`                       // 适用商品金额门槛，0 表示无门槛
+	ScopeType    int             `json:"scopeType" gorm:"column:scope_type;default:0"`              // 0 全部商品 1 指定分类 2 指定菜品
+	ValidFrom    wrap.LocalTime  `json:"validFrom" gorm:"column:valid_from"`                        // 活动开始时间
+	ValidTo      wrap.LocalTime  `json:"validTo" gorm:"column:valid_to"`                            // 活动结束时间
+	ValidDays    int             `json:"validDays" gorm:"column:valid_days"`                        // 领取后有效天数，0 表示到活动结束为止
+	TotalCount   int             `json:"totalCount" gorm:"column:total_count"`                      // 发放总量，0 表示不限
+	ClaimedCount int             `json:"claimedCount" gorm:"column:claimed_count;default:0"`        // 已领取数量
+	PerUserLimit int             `json:"perUserLimit" gorm:"column:per_user_limit;default:1"`       // 每人限领数量
+	Status       int             `json:"status" gorm:"default:0"`                                   // 1 启用 0 停用
+	CreateTime   wrap.LocalTime  `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+	UpdateTime   wrap.LocalTime  `json:"updateTime" gorm:"column:update_time;autoUpdateTime"`
+	CreateUser   int             `json:"createUser" gorm:"column:create_user;default:null"`
+	UpdateUser   int             `json:"updateUser" gorm:"column:update_user;default:null"`
+}
+
+// TableName 设置表名
+func (CouponTemplate) TableName() string {
+	return "coupon_template"
+}
+
+// CouponScope 优惠券适用范围，TargetID 按模板的 ScopeType 为分类ID或菜品ID
+type CouponScope struct {
+	ID         int `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	TemplateID int `json:"templateId" gorm:"column:template_id;index"`
+	TargetID   int `json:"targetId" gorm:"column:target_id"`
+}
+
+// TableName 设置表名
+func (CouponScope) TableName() string {
+	return "coupon_scope"
+}
+
+// UserCoupon 用户领取的优惠券
+type UserCoupon struct {
+	ID         int            `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	UserID     int            `json:"userId" gorm:"column:user_id;index"`
+	TemplateID int            `json:"templateId" gorm:"column:template_id;index"`
+	Status     int            `json:"status"`                             // 1 未使用 2 已使用
+	OrderID    int            `json:"orderId" gorm:"column:order_id"`     // 使用该券的订单
+	ValidFrom  wrap.LocalTime `json:"validFrom" gorm:"column:valid_from"` // 生效时间
+	ValidTo    wrap.LocalTime `json:"validTo" gorm:"column:valid_to"`     // 过期时间
+	UseTime    wrap.LocalTime `json:"useTime" gorm:"column:use_time"`
+	CreateTime wrap.LocalTime `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+}
+
+// TableName 设置表名
+func (UserCoupon) TableName() string {
+	return "user_coupon"
+}
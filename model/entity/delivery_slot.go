@@ -0,0 +1,21 @@
+package entity
+
+import "takeout/model/wrap"
+
+// DeliverySlot 配送时段，每天按相同的时段开放预约
+type DeliverySlot struct {
+	ID         int            `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	StartTime  string         `json:"startTime" gorm:"column:start_time;size:5;not null"` // 开始时间，HH:MM
+	EndTime    string         `json:"endTime" gorm:"column:end_time;size:5;not null"`     // 结束时间，HH:MM
+	Capacity   int            `json:"capacity" gorm:"not null"`                           // 每天该时段最多可预约的订单数
+	Status     int            `json:"status" gorm:"default:0"`                            // 1 启用 0 停用
+	CreateTime wrap.LocalTime `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+	UpdateTime wrap.LocalTime `json:"updateTime" gorm:"column:update_time;autoUpdateTime"`
+	CreateUser int            `json:"createUser" gorm:"column:create_user;default:null"`
+	UpdateUser int            `json:"updateUser" gorm:"column:update_user;default:null"`
+}
+
+// TableName 设置表名
+func (DeliverySlot) TableName() string {
+	return "delivery_slot"
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"github.com/shopspring/decimal"
+	"takeout/model/wrap"
+)
+
+// DeliveryZone 配送区域，按配送距离或多边形范围划分，每个区域有各自的起送金额和配送费
+type DeliveryZone struct {
+	ID          int             `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Name        string          `json:"name" gorm:"size:32;not null"`
+	Type        int             `json:"type" gorm:"not null"`                                      // 1 按距离 2 按多边形
+	Radius      int             `json:"radius"`                                                    // 按距离时的最大配送距离，米
+	Polygon     string          `json:"polygon" gorm:"type:text"`                                  // 按多边形时的顶点，JSON 数组 [{"lat":..,"lng":..}]
+	MinAmount   decimal.Decimal `json:"minAmount" gorm:"column:min_amount;type:decimal(10,2)"`     // 起送金额，按商品金额计算
+	DeliveryFee decimal.Decimal `json:"deliveryFee" gorm:"column:delivery_fee;type:decimal(10,2)"` // 配送费
+	Sort        int             `json:"sort" gorm:"default:0"`                                     // 多个区域都匹配时取 sort 最小的
+	Status      int             `json:"status" gorm:"default:0"`                                   // 1 启用 0 停用
+	CreateTime  wrap.LocalTime  `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+	UpdateTime  wrap.LocalTime  `json:"updateTime" gorm:"column:update_time;autoUpdateTime"`
+	CreateUser  int             `json:"createUser" gorm:"column:create_user;default:null"`
+	UpdateUser  int             `json:"updateUser" gorm:"column:update_user;default:null"`
+}
+
+// TableName 设置表名
+func (DeliveryZone) TableName() string {
+	return "delivery_zone"
+}
@@ -14,6 +14,9 @@ type Dish struct {
 	Image       string          `json:"image"`
 	Description string          `json:"description"`
 	Status      int             `json:"status" gorm:"default:1"`
+	Stock       *int            `json:"stock" gorm:"column:stock"`                    // 剩余库存，为空表示不限量
+	DailyStock  *int            `json:"dailyStock" gorm:"column:daily_stock"`         // 每日重置的库存数量，为空表示不自动重置
+	SoldOut     bool            `json:"soldOut" gorm:"column:sold_out;default:false"` // 是否因售罄被自动停售
 	CreateTime  wrap.LocalTime  `json:"createTime" gorm:"column:create_time;autoCreateTime"`
 	UpdateTime  wrap.LocalTime  `json:"updateTime" gorm:"column:update_time;autoUpdateTime"`
 	CreateUser  int             `json:"createUser" gorm:"column:create_user;default:null"`
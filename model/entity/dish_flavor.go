@@ -1,14 +1,47 @@
 package entity
 
-// DishFlavor 菜品口味关系数据模型
+import "github.com/shopspring/decimal"
+
+// DishFlavor 菜品口味关系数据模型，每条记录是一个口味选项组，如“辣度”
 type DishFlavor struct {
-	ID     int    `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
-	DishID int    `json:"dishId" gorm:"column:dish_id;not null"`
-	Name   string `json:"name"`
-	Value  string `json:"value"`
+	ID       int                 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	DishID   int                 `json:"dishId" gorm:"column:dish_id;not null"`
+	Name     string              `json:"name"`
+	Value    string              `json:"value"`                     // 选项名称的 JSON 数组，兼容旧客户端，保存时由选项生成
+	Multiple int                 `json:"multiple" gorm:"default:0"` // 0 单选 1 多选
+	Required int                 `json:"required" gorm:"default:0"` // 1 必须选择
+	Sort     int                 `json:"sort" gorm:"default:0"`     // 组的展示顺序
+	Options  []*DishFlavorOption `json:"options" gorm:"-"`          // 组内选项
 }
 
 // TableName 设置表名
 func (DishFlavor) TableName() string {
 	return "dish_flavor"
 }
+
+// DishFlavorOption 口味选项，可以设置加价
+type DishFlavorOption struct {
+	ID        int             `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	FlavorID  int             `json:"flavorId" gorm:"column:flavor_id;not null;index"`
+	DishID    int             `json:"dishId" gorm:"column:dish_id;not null;index"`
+	Name      string          `json:"name" gorm:"size:32;not null"`
+	Surcharge decimal.Decimal `json:"surcharge" gorm:"type:decimal(10,2);default:0"` // 每份加价
+	Sort      int             `json:"sort" gorm:"default:0"`
+}
+
+// TableName 设置表名
+func (DishFlavorOption) TableName() string {
+	return "dish_flavor_option"
+}
+
+// SelectedFlavor 已选的口味，保存在购物车和订单明细中作为快照
+type SelectedFlavor struct {
+	Name    string            `json:"name"`
+	Options []*SelectedOption `json:"options"`
+}
+
+// SelectedOption 已选的口味选项及下单时的加价
+type SelectedOption struct {
+	Name      string          `json:"name"`
+	Surcharge decimal.Decimal `json:"surcharge"`
+}
@@ -0,0 +1,57 @@
+package entity
+
+import "takeout/model/wrap"
+
+// KitchenStation 后厨档口，按分类分配菜品，未分配档口的菜品归入默认档口
+type KitchenStation struct {
+	ID          int            `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Name        string         `json:"name" gorm:"size:32;not null"`
+	Sort        int            `json:"sort" gorm:"default:0"`
+	CategoryIDs []int          `json:"categoryIds" gorm:"-"` // 负责的分类
+	CreateTime  wrap.LocalTime `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+	UpdateTime  wrap.LocalTime `json:"updateTime" gorm:"column:update_time;autoUpdateTime"`
+	CreateUser  int            `json:"createUser" gorm:"column:create_user;default:null"`
+	UpdateUser  int            `json:"updateUser" gorm:"column:update_user;default:null"`
+}
+
+// TableName 设置表名
+func (KitchenStation) TableName() string {
+	return "kitchen_station"
+}
+
+// KitchenStationCategory 档口负责的分类，一个分类只能分配给一个档口
+type KitchenStationCategory struct {
+	ID         int `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	StationID  int `json:"stationId" gorm:"column:station_id;not null;index"`
+	CategoryID int `json:"categoryId" gorm:"column:category_id;not null;uniqueIndex"`
+}
+
+// TableName 设置表名
+func (KitchenStationCategory) TableName() string {
+	return "kitchen_station_category"
+}
+
+// KitchenTicket 后厨工单，商家接单后按订单明细拆分，套餐按套餐菜品和份数拆分
+type KitchenTicket struct {
+	ID            int            `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	OrderID       int            `json:"orderId" gorm:"column:order_id;not null;index"`
+	OrderNumber   string         `json:"orderNumber" gorm:"column:order_number"`
+	OrderDetailID int            `json:"orderDetailId" gorm:"column:order_detail_id"`
+	StationID     int            `json:"stationId" gorm:"column:station_id;index"` // 0 为默认档口
+	DishID        int            `json:"dishId" gorm:"column:dish_id"`
+	SetmealID     int            `json:"setmealId" gorm:"column:setmeal_id"`
+	SetmealName   string         `json:"setmealName" gorm:"column:setmeal_name"` // 所属套餐，单点菜品为空
+	Name          string         `json:"name"`
+	DishFlavor    string         `json:"dishFlavor" gorm:"column:dish_flavor"`
+	Number        int            `json:"number"`
+	Remark        string         `json:"remark"`                                 // 订单备注
+	Status        int            `json:"status" gorm:"not null;default:1;index"` // 1 待制作 2 制作中 3 已出餐
+	StartTime     wrap.LocalTime `json:"startTime" gorm:"column:start_time"`
+	ReadyTime     wrap.LocalTime `json:"readyTime" gorm:"column:ready_time"`
+	CreateTime    wrap.LocalTime `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+}
+
+// TableName 设置表名
+func (KitchenTicket) TableName() string {
+	return "kitchen_ticket"
+}
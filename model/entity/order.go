@@ -10,7 +10,7 @@ import (
 // Order 订单数据模型
 type Order struct {
 	ID                    int             `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
-	Number                string          `json:"number"`
+	Number                string          `json:"number" gorm:"column:number;size:64;uniqueIndex"`
 	Status                int             `json:"status"`
 	UserID                int             `json:"userId" gorm:"column:user_id"`
 	AddressBookID         int             `json:"addressBookId" gorm:"column:address_book_id"`
@@ -21,7 +21,14 @@ type Order struct {
 	DeliveryTime          wrap.LocalTime  `json:"deliveryTime" gorm:"column:delivery_time"`
 	PayMethod             int             `json:"payMethod" gorm:"column:pay_method"`
 	PayStatus             int             `json:"payStatus" gorm:"column:pay_status;default:0"`
-	Amount                decimal.Decimal `json:"amount"`
+	Amount                decimal.Decimal `json:"amount"`                                                    // 实付金额
+	GoodsAmount           decimal.Decimal `json:"goodsAmount" gorm:"column:goods_amount;type:decimal(10,2)"` // 商品金额
+	DeliveryFee           decimal.Decimal `json:"deliveryFee" gorm:"column:delivery_fee;type:decimal(10,2)"`
+	TablewareAmount       decimal.Decimal `json:"tablewareAmount" gorm:"column:tableware_amount;type:decimal(10,2)"`
+	DiscountAmount        decimal.Decimal `json:"discountAmount" gorm:"column:discount_amount;type:decimal(10,2)"` // 优惠金额
+	CouponID              int             `json:"couponId" gorm:"column:coupon_id"`                                // 使用的用户优惠券
+	PointsUsed            int             `json:"pointsUsed" gorm:"column:points_used;default:0"`                  // 抵扣使用的积分
+	PointsAmount          decimal.Decimal `json:"pointsAmount" gorm:"column:points_amount;type:decimal(10,2)"`     // 积分抵扣金额，已含在优惠金额中
 	Remark                string          `json:"remark"`
 	Username              string          `json:"username" gorm:"column:user_name"`
 	Phone                 string          `json:"phone"`
@@ -33,6 +40,13 @@ type Order struct {
 	PackAmount            decimal.Decimal `json:"packAmount" gorm:"column:pack_amount"`
 	TablewareNumber       int             `json:"tablewareNumber" gorm:"column:tableware_number"`
 	TablewareStatus       int             `json:"tablewareStatus" gorm:"column:tableware_status"`
+	RiderID               int             `json:"riderId" gorm:"column:rider_id;index"`
+	RiderStatus           int             `json:"riderStatus" gorm:"column:rider_status;default:0"`    // 骑手配送进度
+	DeliverySlotID        int             `json:"deliverySlotId" gorm:"column:delivery_slot_id;index"` // 预约的配送时段
+	ReleaseTime           wrap.LocalTime  `json:"releaseTime" gorm:"column:release_time"`              // 预约单进入后厨队列的时间
+	Held                  int             `json:"held" gorm:"column:held;default:0"`                   // 1 表示预约单尚未进入后厨队列
+	MealReady             int             `json:"mealReady" gorm:"column:meal_ready;default:0"`        // 1 表示后厨工单已全部出餐，等待取餐
+	ReadyTime             wrap.LocalTime  `json:"readyTime" gorm:"column:ready_time"`                  // 全部出餐的时间
 }
 
 // TableName 指定表名
@@ -4,15 +4,19 @@ import "github.com/shopspring/decimal"
 
 // OrderDetail 订单明细数据模型
 type OrderDetail struct {
-	ID         int             `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
-	Name       string          `json:"name"`
-	OrderID    int             `json:"orderId" gorm:"column:order_id"`
-	DishID     int             `json:"dishId" gorm:"column:dish_id"`
-	SetmealID  int             `json:"setmealId" gorm:"column:setmeal_id"`
-	DishFlavor string          `json:"dishFlavor" gorm:"column:dish_flavor"`
-	Number     int             `json:"number"`
-	Amount     decimal.Decimal `json:"amount"`
-	Image      string          `json:"image"`
+	ID          int             `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Name        string          `json:"name"`
+	OrderID     int             `json:"orderId" gorm:"column:order_id"`
+	DishID      int             `json:"dishId" gorm:"column:dish_id"`
+	SetmealID   int             `json:"setmealId" gorm:"column:setmeal_id"`
+	DishFlavor  string          `json:"dishFlavor" gorm:"column:dish_flavor"`
+	Flavors     string          `json:"flavors" gorm:"column:flavors;type:text"`               // 已选口味快照，[]SelectedFlavor 的 JSON
+	FlavorFee   decimal.Decimal `json:"flavorFee" gorm:"column:flavor_fee;type:decimal(10,2)"` // 每份口味加价，已含在单价中
+	Number      int             `json:"number"`
+	Amount      decimal.Decimal `json:"amount"`                                                    // 下单时的单价，含口味加价
+	PackAmount  decimal.Decimal `json:"packAmount" gorm:"column:pack_amount;type:decimal(10,2)"`   // 该行打包费
+	TotalAmount decimal.Decimal `json:"totalAmount" gorm:"column:total_amount;type:decimal(10,2)"` // 该行小计
+	Image       string          `json:"image"`
 }
 
 // TableName 指定表名
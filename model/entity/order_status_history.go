@@ -0,0 +1,20 @@
+package entity
+
+import "takeout/model/wrap"
+
+// OrderStatusHistory 订单状态流转历史数据模型
+type OrderStatusHistory struct {
+	ID           int            `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	OrderID      int            `json:"orderId" gorm:"column:order_id;not null;index"`
+	FromStatus   int            `json:"fromStatus" gorm:"column:from_status"`
+	ToStatus     int            `json:"toStatus" gorm:"column:to_status"`
+	OperatorType int            `json:"operatorType" gorm:"column:operator_type"` // 0 系统 1 用户 2 员工
+	OperatorID   int            `json:"operatorId" gorm:"column:operator_id"`
+	Reason       string         `json:"reason"`
+	CreateTime   wrap.LocalTime `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+}
+
+// TableName 指定表名
+func (OrderStatusHistory) TableName() string {
+	return "order_status_history"
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "takeout/model/wrap"
+
+// PointsLog 积分流水，只追加不修改
+type PointsLog struct {
+	ID         int            `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	UserID     int            `json:"userId" gorm:"column:user_id;not null;index"`
+	Type       int            `json:"type" gorm:"not null"`                 // 1 消费获得 2 下单抵扣 3 退款扣回 4 退款退还 5 人工调整
+	Change     int            `json:"change" gorm:"not null"`               // 变动数量，扣减为负
+	Balance    int            `json:"balance" gorm:"not null"`              // 变动后的余额
+	OrderID    int            `json:"orderId" gorm:"column:order_id;index"` // 关联订单，人工调整为 0
+	Reason     string         `json:"reason" gorm:"size:128"`               // 变动原因
+	OperatorID int            `json:"operatorId" gorm:"column:operator_id"` // 人工调整的员工ID
+	CreateTime wrap.LocalTime `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+}
+
+// TableName 设置表名
+func (PointsLog) TableName() string {
+	return "points_log"
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "takeout/model/wrap"
+
+// PrintJob 打印任务，保存渲染好的 ESC/POS 指令，发送失败时由定时任务原样重发
+type PrintJob struct {
+	ID          int            `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	OrderID     int            `json:"orderId" gorm:"column:order_id;not null;index"`
+	OrderNumber string         `json:"orderNumber" gorm:"column:order_number"`
+	Type        int            `json:"type" gorm:"not null"`                   // 1 顾客小票 2 后厨单
+	Content     []byte         `json:"-" gorm:"type:blob"`                     // ESC/POS 指令
+	Status      int            `json:"status" gorm:"not null;default:1;index"` // 1 待打印 2 已发送 3 失败
+	FailReason  string         `json:"failReason" gorm:"column:fail_reason"`
+	RetryCount  int            `json:"retryCount" gorm:"column:retry_count;default:0"`
+	PrintTime   wrap.LocalTime `json:"printTime" gorm:"column:print_time"`
+	CreateTime  wrap.LocalTime `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+	UpdateTime  wrap.LocalTime `json:"updateTime" gorm:"column:update_time;autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (PrintJob) TableName() string {
+	return "print_job"
+}
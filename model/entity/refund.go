@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"github.com/shopspring/decimal"
+	"takeout/model/wrap"
+)
+
+// Refund 退款单数据模型，一个订单可以对应多次退款申请
+type Refund struct {
+	ID          int             `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Number      string          `json:"number" gorm:"column:number;size:64;not null;uniqueIndex"` // 退款单号，区别于订单号
+	OrderID     int             `json:"orderId" gorm:"column:order_id;not null;index"`
+	OrderNumber string          `json:"orderNumber" gorm:"column:order_number"`
+	Amount      decimal.Decimal `json:"amount" gorm:"type:decimal(10,2)"` // 退款金额
+	Total       decimal.Decimal `json:"total" gorm:"type:decimal(10,2)"`  // 原订单金额
+	Status      int             `json:"status"`
+	Reason      string          `json:"reason"`
+	FailReason  string          `json:"failReason" gorm:"column:fail_reason"`
+	RetryCount  int             `json:"retryCount" gorm:"column:retry_count;default:0"`
+	SuccessTime wrap.LocalTime  `json:"successTime" gorm:"column:success_time"`
+	CreateTime  wrap.LocalTime  `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+	UpdateTime  wrap.LocalTime  `json:"updateTime" gorm:"column:update_time;autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (Refund) TableName() string {
+	return "refund"
+}
@@ -0,0 +1,44 @@
+package entity
+
+import "takeout/model/wrap"
+
+// Review 订单评价，每个订单只能评价一次
+type Review struct {
+	ID         int            `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	OrderID    int            `json:"orderId" gorm:"column:order_id;not null;uniqueIndex"`
+	UserID     int            `json:"userId" gorm:"column:user_id;not null;index"`
+	Rating     int            `json:"rating" gorm:"not null"`           // 订单整体评分，1-5 星
+	Content    string         `json:"content" gorm:"size:500"`          // 评价内容
+	Images     string         `json:"images" gorm:"type:text"`          // 图片地址的 JSON 数组
+	Status     int            `json:"status" gorm:"not null;default:1"` // 1 显示 0 隐藏
+	Reply      string         `json:"reply" gorm:"size:500"`            // 商家回复
+	ReplyTime  wrap.LocalTime `json:"replyTime" gorm:"column:reply_time;default:null"`
+	ReplyUser  int            `json:"replyUser" gorm:"column:reply_user;default:null"`
+	CreateTime wrap.LocalTime `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+	UpdateTime wrap.LocalTime `json:"updateTime" gorm:"column:update_time;autoUpdateTime"`
+}
+
+// TableName 设置表名
+func (Review) TableName() string {
+	return "review"
+}
+
+// ReviewItem 订单中单个商品的评分，对应一条订单明细
+type ReviewItem struct {
+	ID            int            `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	ReviewID      int            `json:"reviewId" gorm:"column:review_id;not null;index"`
+	OrderID       int            `json:"orderId" gorm:"column:order_id;not null"`
+	OrderDetailID int            `json:"orderDetailId" gorm:"column:order_detail_id;not null"`
+	DishID        int            `json:"dishId" gorm:"column:dish_id;index"`
+	SetmealID     int            `json:"setmealId" gorm:"column:setmeal_id"`
+	Name          string         `json:"name"`
+	Rating        int            `json:"rating" gorm:"not null"`
+	Content       string         `json:"content" gorm:"size:255"`
+	Status        int            `json:"status" gorm:"not null;default:1"` // 与所属评价同步，隐藏的评价不计入菜品评分
+	CreateTime    wrap.LocalTime `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+}
+
+// TableName 设置表名
+func (ReviewItem) TableName() string {
+	return "review_item"
+}
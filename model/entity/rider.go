@@ -0,0 +1,26 @@
+package entity
+
+import "takeout/model/wrap"
+
+// Rider 骑手数据模型
+type Rider struct {
+	ID           int            `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Username     string         `json:"username" gorm:"size:64;uniqueIndex;not null"`
+	Password     string         `json:"-" gorm:"not null"`
+	Name         string         `json:"name" gorm:"not null"`
+	Phone        string         `json:"phone"`
+	Status       int            `json:"status" gorm:"default:1"`                        // 1 启用 0 禁用
+	WorkStatus   int            `json:"workStatus" gorm:"column:work_status;default:0"` // 1 接单中 0 休息
+	Latitude     float64        `json:"latitude"`                                       // 最近一次上报的纬度
+	Longitude    float64        `json:"longitude"`                                      // 最近一次上报的经度
+	LocationTime wrap.LocalTime `json:"locationTime" gorm:"column:location_time"`       // 最近一次上报位置的时间
+	CreateTime   wrap.LocalTime `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+	UpdateTime   wrap.LocalTime `json:"updateTime" gorm:"column:update_time;autoUpdateTime"`
+	CreateUser   int            `json:"createUser" gorm:"column:create_user;default:null"`
+	UpdateUser   int            `json:"updateUser" gorm:"column:update_user;default:null"`
+}
+
+// TableName 设置表名
+func (Rider) TableName() string {
+	return "rider"
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "takeout/model/wrap"
+
+// Role 后台员工角色，员工通过 Employee.RoleID 关联角色
+type Role struct {
+	ID         int            `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Code       string         `json:"code" gorm:"size:32;uniqueIndex;not null"`
+	Name       string         `json:"name" gorm:"size:32;not null"`
+	Builtin    bool           `json:"builtin" gorm:"default:false"` // 内置角色不能删除
+	CreateTime wrap.LocalTime `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+	UpdateTime wrap.LocalTime `json:"updateTime" gorm:"column:update_time;autoUpdateTime"`
+	CreateUser int            `json:"createUser" gorm:"column:create_user;default:null"`
+	UpdateUser int            `json:"updateUser" gorm:"column:update_user;default:null"`
+}
+
+// TableName 设置表名
+func (Role) TableName() string {
+	return "role"
+}
+
+// RolePermission 角色拥有的权限码
+type RolePermission struct {
+	ID         int    `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	RoleID     int    `json:"roleId" gorm:"column:role_id;index"`
+	Permission string `json:"permission" gorm:"size:64;not null"`
+}
+
+// TableName 设置表名
+func (RolePermission) TableName() string {
+	return "role_permission"
+}
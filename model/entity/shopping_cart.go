@@ -13,9 +13,10 @@ type ShoppingCart struct {
 	UserID     int             `json:"userId" gorm:"column:user_id;not null"`
 	DishID     int             `json:"dishId" gorm:"column:dish_id"`
 	SetmealID  int             `json:"setmealId" gorm:"column:setmeal_id"`
-	DishFlavor string          `json:"dishFlavor" gorm:"column:dish_flavor"`
+	DishFlavor string          `json:"dishFlavor" gorm:"column:dish_flavor"`    // 已选口味的展示文字，如“微辣,加蛋”
+	Flavors    string          `json:"flavors" gorm:"column:flavors;type:text"` // 已选口味快照，[]SelectedFlavor 的 JSON
 	Number     int             `json:"number" gorm:"not null;default:1"`
-	Amount     decimal.Decimal `json:"amount" gorm:"type:decimal(10,2);not null"`
+	Amount     decimal.Decimal `json:"amount" gorm:"type:decimal(10,2);not null"` // 加入时的单价，含口味加价
 	CreateTime wrap.LocalTime  `json:"createTime" gorm:"column:create_time;autoCreateTime"`
 }
 
@@ -0,0 +1,19 @@
+package entity
+
+import "takeout/model/wrap"
+
+// StockLog 库存流水，记录每个订单实际扣减的菜品份数，取消订单时按流水归还
+type StockLog struct {
+	ID         int            `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	OrderID    int            `json:"orderId" gorm:"column:order_id;not null;index"`
+	DishID     int            `json:"dishId" gorm:"column:dish_id;not null;index"`
+	Number     int            `json:"number" gorm:"not null"`           // 扣减的份数
+	Status     int            `json:"status" gorm:"not null;default:1"` // 1 已扣减 2 已归还 3 库存已重置
+	CreateTime wrap.LocalTime `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+	UpdateTime wrap.LocalTime `json:"updateTime" gorm:"column:update_time;autoUpdateTime"`
+}
+
+// TableName 设置表名
+func (StockLog) TableName() string {
+	return "stock_log"
+}
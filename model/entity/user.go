@@ -10,6 +10,7 @@ type User struct {
 	Sex        string         `json:"sex"`
 	IdNumber   string         `json:"idNumber" gorm:"column:id_number"`
 	Avatar     string         `json:"avatar" `
+	Points     int            `json:"points" gorm:"default:0"` // 积分余额，变动记录在 points_log
 	CreateTime wrap.LocalTime `json:"createTime" gorm:"column:create_time;autoCreateTime"`
 }
 
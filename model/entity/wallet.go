@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"github.com/shopspring/decimal"
+	"takeout/model/wrap"
+)
+
+// Wallet 用户储值钱包，每个用户一条，首次充值或调整时创建
+type Wallet struct {
+	ID         int             `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	UserID     int             `json:"userId" gorm:"column:user_id;not null;uniqueIndex"`
+	Balance    decimal.Decimal `json:"balance" gorm:"type:decimal(10,2);not null;default:0"` // 余额，变动记录在 wallet_log
+	CreateTime wrap.LocalTime  `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+	UpdateTime wrap.LocalTime  `json:"updateTime" gorm:"column:update_time;autoUpdateTime"`
+}
+
+// TableName 设置表名
+func (Wallet) TableName() string {
+	return "wallet"
+}
+
+// WalletLog 余额流水，只追加不修改
+type WalletLog struct {
+	ID         int             `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	UserID     int             `json:"userId" gorm:"column:user_id;not null;index"`
+	Type       int             `json:"type" gorm:"not null"`                       // 1 充值 2 支付 3 退款 4 人工调整
+	Amount     decimal.Decimal `json:"amount" gorm:"type:decimal(10,2);not null"`  // 变动金额，扣减为负
+	Balance    decimal.Decimal `json:"balance" gorm:"type:decimal(10,2);not null"` // 变动后的余额
+	OrderID    int             `json:"orderId" gorm:"column:order_id;index"`       // 关联订单，充值和人工调整为 0
+	Number     string          `json:"number" gorm:"size:64"`                      // 充值单号或订单号
+	Reason     string          `json:"reason" gorm:"size:128"`                     // 变动原因
+	OperatorID int             `json:"operatorId" gorm:"column:operator_id"`       // 人工调整的员工ID
+	CreateTime wrap.LocalTime  `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+}
+
+// TableName 设置表名
+func (WalletLog) TableName() string {
+	return "wallet_log"
+}
+
+// WalletRecharge 充值单，通过支付渠道付款，支付成功后入账
+type WalletRecharge struct {
+	ID         int             `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Number     string          `json:"number" gorm:"column:number;size:64;not null;uniqueIndex"` // 充值单号，以 WR 开头，区别于订单号
+	UserID     int             `json:"userId" gorm:"column:user_id;not null;index"`
+	Amount     decimal.Decimal `json:"amount" gorm:"type:decimal(10,2);not null"`
+	Status     int             `json:"status" gorm:"not null;default:0"` // 0 待支付 1 已入账 2 已关闭
+	PayTime    wrap.LocalTime  `json:"payTime" gorm:"column:pay_time"`
+	CreateTime wrap.LocalTime  `json:"createTime" gorm:"column:create_time;autoCreateTime"`
+}
+
+// TableName 设置表名
+func (WalletRecharge) TableName() string {
+	return "wallet_recharge"
+}
@@ -0,0 +1,18 @@
+package vo
+
+import "takeout/model/wrap"
+
+// AuditLogVO 操作日志，附带操作人姓名
+type AuditLogVO struct {
+	ID           int            `json:"id"`
+	OperatorID   int            `json:"operatorId"`
+	OperatorName string         `json:"operatorName"`
+	Action       string         `json:"action"`
+	EntityType   string         `json:"entityType"`
+	EntityID     int            `json:"entityId"`
+	Before       string         `json:"before"`
+	After        string         `json:"after"`
+	IP           string         `json:"ip"`
+	RequestID    string         `json:"requestId"`
+	CreateTime   wrap.LocalTime `json:"createTime"`
+}
@@ -0,0 +1,31 @@
+package vo
+
+import (
+	"github.com/shopspring/decimal"
+	"takeout/model/entity"
+)
+
+// CouponTemplateVO 优惠券模板视图对象
+type CouponTemplateVO struct {
+	entity.CouponTemplate
+	ScopeIDs []int `json:"scopeIds"` // 适用的分类ID或菜品ID
+}
+
+// UserCouponVO 用户优惠券视图对象
+type UserCouponVO struct {
+	entity.UserCoupon
+	Name        string          `json:"name"`
+	Type        int             `json:"type"`
+	Amount      decimal.Decimal `json:"amount"`
+	Discount    decimal.Decimal `json:"discount"`
+	MaxDiscount decimal.Decimal `json:"maxDiscount" gorm:"column:max_discount"`
+	Threshold   decimal.Decimal `json:"threshold"`
+	ScopeType   int             `json:"scopeType" gorm:"column:scope_type"`
+}
+
+// CouponPreviewVO 购物车最优优惠券预览
+type CouponPreviewVO struct {
+	UserCouponID   int             `json:"userCouponId"`
+	Name           string          `json:"name"`
+	DiscountAmount decimal.Decimal `json:"discountAmount"` // 可优惠金额
+}
@@ -0,0 +1,14 @@
+package vo
+
+import "takeout/model/wrap"
+
+// AvailableSlotVO 用户可预约的配送时段
+type AvailableSlotVO struct {
+	SlotID       int            `json:"slotId"`
+	Date         string         `json:"date"` // 2006-01-02
+	StartTime    string         `json:"startTime"`
+	EndTime      string         `json:"endTime"`
+	DeliveryTime wrap.LocalTime `json:"deliveryTime"` // 下单时作为 estimatedDeliveryTime 传回
+	Remaining    int            `json:"remaining"`    // 剩余可预约数量
+	Available    bool           `json:"available"`    // 已过预约截止时间或已约满时为 false
+}
@@ -0,0 +1,34 @@
+package vo
+
+import (
+	"github.com/shopspring/decimal"
+	"takeout/common/geo"
+	"takeout/model/wrap"
+)
+
+// DeliveryZoneVO 配送区域
+type DeliveryZoneVO struct {
+	ID          int             `json:"id"`
+	Name        string          `json:"name"`
+	Type        int             `json:"type"`
+	Radius      int             `json:"radius"`
+	Polygon     []geo.Point     `json:"polygon"`
+	MinAmount   decimal.Decimal `json:"minAmount"`
+	DeliveryFee decimal.Decimal `json:"deliveryFee"`
+	Sort        int             `json:"sort"`
+	Status      int             `json:"status"`
+	UpdateTime  wrap.LocalTime  `json:"updateTime"`
+}
+
+// CartPreviewVO 购物车结算预览，不含餐具费和优惠
+type CartPreviewVO struct {
+	GoodsAmount decimal.Decimal `json:"goodsAmount"`
+	PackAmount  decimal.Decimal `json:"packAmount"`
+	DeliveryFee decimal.Decimal `json:"deliveryFee"`
+	Amount      decimal.Decimal `json:"amount"`
+	MinAmount   decimal.Decimal `json:"minAmount"` // 所在配送区域的起送金额
+	ZoneName    string          `json:"zoneName"`
+	Distance    int             `json:"distance"`    // 配送距离，米；按多边形匹配时为 0
+	Deliverable bool            `json:"deliverable"` // 能否下单
+	Message     string          `json:"message"`     // 不能下单的原因
+}
@@ -15,9 +15,14 @@ type DishVO struct {
 	Image        string               `json:"image"`
 	Description  string               `json:"description"`
 	Status       int                  `json:"status"`
+	Stock        *int                 `json:"stock"`
+	DailyStock   *int                 `json:"dailyStock" gorm:"column:daily_stock"`
+	SoldOut      bool                 `json:"soldOut" gorm:"column:sold_out"`
 	UpdateTime   wrap.LocalTime       `json:"updateTime" gorm:"column:update_time"`
 	CategoryName string               `json:"categoryName" gorm:"column:category_name"`
 	Flavors      []*entity.DishFlavor `json:"flavors" gorm:"-"`
+	Rating       float64              `json:"rating" gorm:"-"`      // 平均评分，没有评分时为 0
+	ReviewCount  int64                `json:"reviewCount" gorm:"-"` // 评分数
 }
 
 // DishItem 套餐内菜品信息
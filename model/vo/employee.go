@@ -7,10 +7,14 @@ import (
 
 // EmployeeLoginVO 员工登录响应VO
 type EmployeeLoginVO struct {
-	ID       int    `json:"id"`
-	Username string `json:"username"`
-	Name     string `json:"name"`
-	Token    string `json:"token"`
+	ID           int    `json:"id"`
+	Username     string `json:"username"`
+	Name         string `json:"name"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"` // 访问令牌有效期，秒
+	// MustChangePassword 为 true 时除修改密码和退出登录外的接口都会被拒绝
+	MustChangePassword bool `json:"mustChangePassword"`
 }
 
 // EmployeeDetailVO 员工详情响应VO
@@ -23,6 +27,7 @@ type EmployeeDetailVO struct {
 	Sex        string `json:"sex"`
 	IdNumber   string `json:"idNumber"`
 	Status     int    `json:"status"`
+	RoleID     int    `json:"roleId"`
 	CreateTime string `json:"createTime"`
 	UpdateTime string `json:"updateTime"`
 	CreateUser int    `json:"createUser"`
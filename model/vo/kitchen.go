@@ -0,0 +1,10 @@
+package vo
+
+import "takeout/model/entity"
+
+// KitchenStationTicketsVO 档口及其工单
+type KitchenStationTicketsVO struct {
+	StationID   int                     `json:"stationId"`
+	StationName string                  `json:"stationName"`
+	Tickets     []*entity.KitchenTicket `json:"tickets"`
+}
@@ -26,6 +26,18 @@ type OrderVO struct {
 	entity.Order
 	OrderDishes     string                `json:"orderDishes"`
 	OrderDetailList []*entity.OrderDetail `json:"orderDetailList"`
+	StatusTimeline  []*OrderStatusVO      `json:"statusTimeline,omitempty"` // 状态流转时间线，仅详情返回
+	Rider           *OrderRiderVO         `json:"rider,omitempty"`          // 配送骑手及最新位置，仅详情返回
+}
+
+// OrderStatusVO 订单状态流转记录，用户端不返回操作人 ID
+type OrderStatusVO struct {
+	FromStatus   int            `json:"fromStatus"`
+	ToStatus     int            `json:"toStatus"`
+	OperatorType int            `json:"operatorType"` // 0 系统 1 用户 2 员工 3 骑手
+	OperatorID   int            `json:"operatorId,omitempty"`
+	Reason       string         `json:"reason"`
+	CreateTime   wrap.LocalTime `json:"createTime"`
 }
 
 // OrderStatisticsVO 订单数量统计返回数据模型
@@ -0,0 +1,17 @@
+package vo
+
+import "github.com/shopspring/decimal"
+
+// PointsInfoVO 用户积分余额及会员等级进度
+type PointsInfoVO struct {
+	Points         int             `json:"points"`         // 积分余额
+	RedeemRate     int             `json:"redeemRate"`     // 多少积分抵扣 1 元，为 0 时不能抵扣
+	MaxRedeemRatio float64         `json:"maxRedeemRatio"` // 积分最多抵扣应付金额的比例
+	Tier           string          `json:"tier"`           // 当前等级
+	Multiplier     float64         `json:"multiplier"`     // 当前等级的积分倍率
+	Spend          decimal.Decimal `json:"spend"`          // 统计周期内的消费金额
+	WindowDays     int             `json:"windowDays"`     // 统计周期，天
+	NextTier       string          `json:"nextTier"`       // 下一等级，已是最高等级时为空
+	NextTierSpend  decimal.Decimal `json:"nextTierSpend"`  // 下一等级的消费门槛
+	Remaining      decimal.Decimal `json:"remaining"`      // 距下一等级还需消费的金额
+}
@@ -0,0 +1,37 @@
+package vo
+
+import "takeout/model/wrap"
+
+// ReviewVO 订单评价
+type ReviewVO struct {
+	ID          int             `json:"id"`
+	OrderID     int             `json:"orderId"`
+	OrderNumber string          `json:"orderNumber"`
+	UserID      int             `json:"userId"`
+	Rating      int             `json:"rating"`
+	Content     string          `json:"content"`
+	Images      []string        `json:"images"`
+	Status      int             `json:"status"`
+	Reply       string          `json:"reply"`
+	ReplyTime   wrap.LocalTime  `json:"replyTime"`
+	CreateTime  wrap.LocalTime  `json:"createTime"`
+	Items       []*ReviewItemVO `json:"items"`
+}
+
+// ReviewItemVO 商品评分
+type ReviewItemVO struct {
+	OrderDetailID int    `json:"orderDetailId"`
+	DishID        int    `json:"dishId"`
+	SetmealID     int    `json:"setmealId"`
+	Name          string `json:"name"`
+	Rating        int    `json:"rating"`
+	Content       string `json:"content"`
+}
+
+// DishRatingVO 菜品评分汇总
+type DishRatingVO struct {
+	DishID      int     `json:"dishId" gorm:"column:dish_id"`
+	Name        string  `json:"name" gorm:"column:name"`
+	Rating      float64 `json:"rating" gorm:"column:rating"` // 平均分，保留一位小数
+	ReviewCount int64   `json:"reviewCount" gorm:"column:review_count"`
+}
@@ -0,0 +1,32 @@
+package vo
+
+import (
+	"takeout/model/entity"
+	"takeout/model/wrap"
+)
+
+// RiderLoginVO 骑手登录响应VO
+type RiderLoginVO struct {
+	ID           int    `json:"id"`
+	Username     string `json:"username"`
+	Name         string `json:"name"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"`
+}
+
+// RiderVO 骑手及其进行中的订单数量
+type RiderVO struct {
+	entity.Rider
+	ActiveOrders int `json:"activeOrders" gorm:"column:active_orders"`
+}
+
+// OrderRiderVO 订单详情中展示的骑手信息和最新位置
+type OrderRiderVO struct {
+	ID           int            `json:"id"`
+	Name         string         `json:"name"`
+	Phone        string         `json:"phone"`
+	Latitude     float64        `json:"latitude"`
+	Longitude    float64        `json:"longitude"`
+	LocationTime wrap.LocalTime `json:"locationTime"`
+}
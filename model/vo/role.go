@@ -0,0 +1,16 @@
+package vo
+
+// RoleVO 角色及其权限
+type RoleVO struct {
+	ID          int      `json:"id"`
+	Code        string   `json:"code"`
+	Name        string   `json:"name"`
+	Builtin     bool     `json:"builtin"`
+	Permissions []string `json:"permissions"`
+}
+
+// PermissionVO 可分配的权限
+type PermissionVO struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
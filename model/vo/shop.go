@@ -0,0 +1,12 @@
+package vo
+
+import "takeout/model/wrap"
+
+// ShopStatusVO 店铺当前营业状态
+type ShopStatusVO struct {
+	Status       int             `json:"status"`                 // 综合营业时间、暂停和手动开关后的状态，1 营业中 0 打烊
+	Manual       int             `json:"manual"`                 // 手动设置的营业开关
+	Paused       bool            `json:"paused"`                 // 是否忙碌暂停中
+	ResumeTime   *wrap.LocalTime `json:"resumeTime,omitempty"`   // 暂停自动恢复的时间
+	NextOpenTime *wrap.LocalTime `json:"nextOpenTime,omitempty"` // 打烊时下次开始营业的时间，手动打烊时为空
+}
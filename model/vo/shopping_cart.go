@@ -0,0 +1,30 @@
+package vo
+
+import "github.com/shopspring/decimal"
+
+// CartLineVO 购物车中的一行，按商品当前价格计算
+type CartLineVO struct {
+	ID            int             `json:"id"`
+	DishID        int             `json:"dishId"`
+	SetmealID     int             `json:"setmealId"`
+	Name          string          `json:"name"`
+	Image         string          `json:"image"`
+	DishFlavor    string          `json:"dishFlavor"`
+	Number        int             `json:"number"`
+	Amount        decimal.Decimal `json:"amount"`        // 加入购物车时的单价
+	CurrentAmount decimal.Decimal `json:"currentAmount"` // 当前单价，商品不可售时为 0
+	PackAmount    decimal.Decimal `json:"packAmount"`    // 该行打包费
+	TotalAmount   decimal.Decimal `json:"totalAmount"`   // 该行小计 = 当前单价 * 数量 + 打包费
+	Status        int             `json:"status"`        // 0 正常 1 不可售 2 价格变动
+	Message       string          `json:"message"`
+}
+
+// CartSummaryVO 购物车汇总，不可售的商品不计入金额，不含配送费和餐具费
+type CartSummaryVO struct {
+	Lines       []*CartLineVO   `json:"lines"`
+	Number      int             `json:"number"` // 可售商品总份数
+	GoodsAmount decimal.Decimal `json:"goodsAmount"`
+	PackAmount  decimal.Decimal `json:"packAmount"`
+	Amount      decimal.Decimal `json:"amount"`
+	Valid       bool            `json:"valid"` // 所有商品均可售且价格未变动
+}
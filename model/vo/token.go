@@ -0,0 +1,14 @@
+package vo
+
+// TokenVO 刷新令牌响应
+type TokenVO struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"` // 访问令牌有效期，秒
+}
+
+// CaptchaVO 登录验证码
+type CaptchaVO struct {
+	CaptchaID string `json:"captchaId"`
+	Image     string `json:"image"` // PNG 图片的 data URI
+}
@@ -1,7 +1,9 @@
 package vo
 
 type UserLoginVO struct {
-	ID     int    `json:"id"`
-	Token  string `json:"token"`
-	OpenID string `json:"openid"`
+	ID           int    `json:"id"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"`
+	OpenID       string `json:"openid"`
 }
@@ -0,0 +1,15 @@
+package vo
+
+import "github.com/shopspring/decimal"
+
+// WalletVO 用户余额
+type WalletVO struct {
+	Balance decimal.Decimal `json:"balance"`
+}
+
+// RechargeVO 充值单及调起支付的参数
+type RechargeVO struct {
+	Number  string          `json:"number"` // 充值单号
+	Amount  decimal.Decimal `json:"amount"`
+	Payment *OrderPaymentVO `json:"payment"`
+}
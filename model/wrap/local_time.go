@@ -69,6 +69,10 @@ func (t LocalTime) Value() (driver.Value, error) {
 
 // Scan 实现sql.Scanner接口
 func (t *LocalTime) Scan(v any) error {
+	if v == nil {
+		*t = LocalTime(time.Time{})
+		return nil
+	}
 	value, ok := v.(time.Time)
 	if ok {
 		*t = LocalTime(value)
@@ -32,4 +32,26 @@ func (r *AdminRouter) RegisterRoutes() {
 	r.reportRouter()
 	// 注册工作台路由
 	r.workSpaceRouter()
+	// 注册优惠券路由
+	r.couponRouter()
+	// 注册骑手路由
+	r.riderRouter()
+	// 注册角色路由
+	r.roleRouter()
+	// 注册操作日志路由
+	r.auditRouter()
+	// 注册配送时段路由
+	r.deliverySlotRouter()
+	// 注册配送区域路由
+	r.deliveryZoneRouter()
+	// 注册评价路由
+	r.reviewRouter()
+	// 注册会员积分路由
+	r.pointsRouter()
+	// 注册用户余额路由
+	r.walletRouter()
+	// 注册后厨路由
+	r.kitchenRouter()
+	// 注册退款路由
+	r.refundRouter()
 }
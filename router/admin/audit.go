@@ -0,0 +1,17 @@
+package admin
+
+import (
+	"takeout/common/constant"
+	"takeout/internal/control/admin"
+	"takeout/internal/middleware"
+)
+
+func (r *AdminRouter) auditRouter() {
+	audit := r.admin.Group("audit")
+	audit.Use(middleware.JwtAdmin(), middleware.RequirePermission(constant.PermAuditView))
+	{
+		auditController := admin.NewAuditController()
+		// 操作日志分页查询
+		audit.GET("/page", auditController.PageQuery)
+	}
+}
@@ -1,6 +1,7 @@
 package admin
 
 import (
+	"takeout/common/constant"
 	"takeout/internal/control/admin"
 	"takeout/internal/middleware"
 )
@@ -12,16 +13,16 @@ func (r *AdminRouter) categoryRouter() {
 	{
 		categoryController := admin.NewCategoryController()
 		// 新增分类
-		category.POST("", categoryController.Create)
+		category.POST("", middleware.RequirePermission(constant.PermMenuManage), middleware.Audit(constant.AuditCategory, constant.AuditCreate), categoryController.Create)
 		// 启用/禁用分类
-		category.POST("/status/:status", categoryController.UpdateStatus)
+		category.POST("/status/:status", middleware.RequirePermission(constant.PermMenuManage), middleware.Audit(constant.AuditCategory, constant.AuditStatus), categoryController.UpdateStatus)
 		// 修改分类
-		category.PUT("", categoryController.Update)
+		category.PUT("", middleware.RequirePermission(constant.PermMenuManage), middleware.Audit(constant.AuditCategory, constant.AuditUpdate), categoryController.Update)
 		// 分类分页查询
-		category.GET("/page", categoryController.PageQuery)
+		category.GET("/page", middleware.RequirePermission(constant.PermMenuView), categoryController.PageQuery)
 		// 根据类型查询分类
-		category.GET("/list", categoryController.List)
+		category.GET("/list", middleware.RequirePermission(constant.PermMenuView), categoryController.List)
 		// 删除分类
-		category.DELETE("", categoryController.Delete)
+		category.DELETE("", middleware.RequirePermission(constant.PermMenuManage), middleware.Audit(constant.AuditCategory, constant.AuditDelete), categoryController.Delete)
 	}
 }
@@ -1,6 +1,7 @@
 package admin
 
 import (
+	"takeout/common/constant"
 	"takeout/internal/control/admin"
 	"takeout/internal/middleware"
 )
@@ -15,6 +16,6 @@ func (r *AdminRouter) commonRouter() {
 	common.Use(middleware.JwtAdmin())
 	{
 		// 文件上传
-		common.POST("/upload", commonController.Upload)
+		common.POST("/upload", middleware.RequirePermission(constant.PermMenuManage), commonController.Upload)
 	}
 }
@@ -0,0 +1,27 @@
+package admin
+
+import (
+	"takeout/common/constant"
+	"takeout/internal/control/admin"
+	"takeout/internal/middleware"
+)
+
+func (r *AdminRouter) couponRouter() {
+	coupon := r.admin.Group("coupon")
+	coupon.Use(middleware.JwtAdmin(), middleware.RequirePermission(constant.PermCouponManage))
+	{
+		couponController := admin.NewCouponController()
+		// 新增优惠券
+		coupon.POST("", couponController.Create)
+		// 修改优惠券
+		coupon.PUT("", couponController.Update)
+		// 优惠券分页查询
+		coupon.GET("/page", couponController.PageQuery)
+		// 查询优惠券详情
+		coupon.GET("/:id", couponController.GetByID)
+		// 启用、停用优惠券
+		coupon.POST("/status/:status", couponController.UpdateStatus)
+		// 批量删除优惠券
+		coupon.DELETE("", couponController.BatchDelete)
+	}
+}
@@ -0,0 +1,25 @@
+package admin
+
+import (
+	"takeout/common/constant"
+	"takeout/internal/control/admin"
+	"takeout/internal/middleware"
+)
+
+func (r *AdminRouter) deliverySlotRouter() {
+	slot := r.admin.Group("/deliverySlot")
+	slot.Use(middleware.JwtAdmin(), middleware.RequirePermission(constant.PermShopManage))
+	{
+		slotController := admin.NewDeliverySlotController()
+		// 查询全部配送时段
+		slot.GET("/list", slotController.List)
+		// 新增配送时段
+		slot.POST("", slotController.Create)
+		// 修改配送时段
+		slot.PUT("", slotController.Update)
+		// 启用、停用配送时段
+		slot.POST("/status/:status", slotController.UpdateStatus)
+		// 删除配送时段
+		slot.DELETE("/:id", slotController.Delete)
+	}
+}
@@ -0,0 +1,25 @@
+package admin
+
+import (
+	"takeout/common/constant"
+	"takeout/internal/control/admin"
+	"takeout/internal/middleware"
+)
+
+func (r *AdminRouter) deliveryZoneRouter() {
+	zone := r.admin.Group("/deliveryZone")
+	zone.Use(middleware.JwtAdmin(), middleware.RequirePermission(constant.PermShopManage))
+	{
+		zoneController := admin.NewDeliveryZoneController()
+		// 查询全部配送区域
+		zone.GET("/list", zoneController.List)
+		// 新增配送区域
+		zone.POST("", zoneController.Create)
+		// 修改配送区域
+		zone.PUT("", zoneController.Update)
+		// 启用、停用配送区域
+		zone.POST("/status/:status", zoneController.UpdateStatus)
+		// 删除配送区域
+		zone.DELETE("/:id", zoneController.Delete)
+	}
+}
@@ -1,6 +1,7 @@
 package admin
 
 import (
+	"takeout/common/constant"
 	"takeout/internal/control/admin"
 	"takeout/internal/middleware"
 )
@@ -12,18 +13,18 @@ func (r *AdminRouter) dishRouter() {
 	dish.Use(middleware.JwtAdmin())
 	{
 		// 新增菜品
-		dish.POST("", dishController.Create)
+		dish.POST("", middleware.RequirePermission(constant.PermMenuManage), middleware.Audit(constant.AuditDish, constant.AuditCreate), dishController.Create)
 		// 分页查询菜品
-		dish.GET("/page", dishController.PageQuery)
+		dish.GET("/page", middleware.RequirePermission(constant.PermMenuView), dishController.PageQuery)
 		// 批量删除菜品
-		dish.DELETE("", dishController.Delete)
+		dish.DELETE("", middleware.RequirePermission(constant.PermMenuManage), middleware.Audit(constant.AuditDish, constant.AuditDelete), dishController.Delete)
 		// 根据 id 查询菜品信息
-		dish.GET("/:id", dishController.GetByID)
+		dish.GET("/:id", middleware.RequirePermission(constant.PermMenuView), dishController.GetByID)
 		// 修改菜品信息
-		dish.PUT("", dishController.Update)
+		dish.PUT("", middleware.RequirePermission(constant.PermMenuManage), middleware.Audit(constant.AuditDish, constant.AuditUpdate), dishController.Update)
 		// 菜品起售、停售
-		dish.POST("/status/:status", dishController.UpdateStatus)
+		dish.POST("/status/:status", middleware.RequirePermission(constant.PermMenuManage), middleware.Audit(constant.AuditDish, constant.AuditStatus), dishController.UpdateStatus)
 		// 根据分类ID查询菜品列表
-		dish.GET("/list", dishController.ListByCategoryID)
+		dish.GET("/list", middleware.RequirePermission(constant.PermMenuView), dishController.ListByCategoryID)
 	}
 }
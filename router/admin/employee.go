@@ -1,6 +1,7 @@
 package admin
 
 import (
+	"takeout/common/constant"
 	"takeout/internal/control/admin"
 	"takeout/internal/middleware"
 )
@@ -10,23 +11,29 @@ func (r *AdminRouter) employeeRouter() {
 	// 员工相关路由
 	employeeController := admin.NewEmployeeController()
 	r.admin.POST("/employee/login", employeeController.Login)
+	// 登录验证码
+	r.admin.GET("/employee/captcha", employeeController.Captcha)
+	// 刷新令牌
+	r.admin.POST("/employee/refresh", employeeController.Refresh)
 
 	// 需要JWT认证的路由
 	employee := r.admin.Group("/employee")
 	employee.Use(middleware.JwtAdmin())
 	{
 		// 新增员工
-		employee.POST("", employeeController.Create)
+		employee.POST("", middleware.RequirePermission(constant.PermEmployeeManage), middleware.Audit(constant.AuditEmployee, constant.AuditCreate), employeeController.Create)
 		// 根据id查询员工信息
-		employee.GET("/:id", employeeController.GetById)
+		employee.GET("/:id", middleware.RequirePermission(constant.PermEmployeeView), employeeController.GetById)
 		// 分页查询员工信息
-		employee.GET("/page", employeeController.Page)
+		employee.GET("/page", middleware.RequirePermission(constant.PermEmployeeView), employeeController.Page)
 		// 更新员工信息
-		employee.PUT("", employeeController.Update)
+		employee.PUT("", middleware.RequirePermission(constant.PermEmployeeManage), middleware.Audit(constant.AuditEmployee, constant.AuditUpdate), employeeController.Update)
 		// 更新员工状态 (通过查询参数接收员工ID)
-		employee.POST("/status/:status", employeeController.UpdateStatus)
+		employee.POST("/status/:status", middleware.RequirePermission(constant.PermEmployeeManage), middleware.Audit(constant.AuditEmployee, constant.AuditStatus), employeeController.UpdateStatus)
+		// 解除登录锁定
+		employee.POST("/unlock/:id", middleware.RequirePermission(constant.PermEmployeeManage), middleware.Audit(constant.AuditEmployee, constant.AuditUnlock), employeeController.Unlock)
 		// 修改密码
-		employee.PUT("/editPassword", employeeController.UpdatePassword)
+		employee.PUT("/editPassword", middleware.Audit(constant.AuditEmployee, constant.AuditPassword), employeeController.UpdatePassword)
 		// 退出登录
 		employee.POST("/logout", employeeController.Logout)
 	}
@@ -0,0 +1,27 @@
+package admin
+
+import (
+	"takeout/common/constant"
+	"takeout/internal/control/admin"
+	"takeout/internal/middleware"
+)
+
+func (r *AdminRouter) kitchenRouter() {
+	kitchen := r.admin.Group("/kitchen")
+	kitchen.Use(middleware.JwtAdmin())
+	{
+		kitchenController := admin.NewKitchenController()
+		// 查询全部档口
+		kitchen.GET("/station/list", middleware.RequirePermission(constant.PermKitchenOperate), kitchenController.ListStations)
+		// 新增档口
+		kitchen.POST("/station", middleware.RequirePermission(constant.PermMenuManage), kitchenController.CreateStation)
+		// 修改档口
+		kitchen.PUT("/station", middleware.RequirePermission(constant.PermMenuManage), kitchenController.UpdateStation)
+		// 删除档口
+		kitchen.DELETE("/station/:id", middleware.RequirePermission(constant.PermMenuManage), kitchenController.DeleteStation)
+		// 按档口查询工单
+		kitchen.GET("/tickets", middleware.RequirePermission(constant.PermKitchenOperate), kitchenController.Tickets)
+		// 开始制作、出餐
+		kitchen.POST("/ticket/status/:status", middleware.RequirePermission(constant.PermKitchenOperate), kitchenController.UpdateTicketStatus)
+	}
+}
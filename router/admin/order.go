@@ -1,6 +1,7 @@
 package admin
 
 import (
+	"takeout/common/constant"
 	"takeout/internal/control/admin"
 	"takeout/internal/middleware"
 )
@@ -11,20 +12,24 @@ func (r *AdminRouter) orderRouter() {
 	{
 		orderController := admin.NewOrderController()
 		// 搜索订单
-		order.GET("/conditionSearch", orderController.Search)
+		order.GET("/conditionSearch", middleware.RequirePermission(constant.PermOrderView), orderController.Search)
 		// 各个状态的订单统计
-		order.GET("/statistics", orderController.Statistics)
+		order.GET("/statistics", middleware.RequirePermission(constant.PermOrderView), orderController.Statistics)
 		// 查询订单详情
-		order.GET("/details/:id", orderController.Detail)
+		order.GET("/details/:id", middleware.RequirePermission(constant.PermOrderView), orderController.Detail)
+		// 补打小票
+		order.POST("/reprint/:id", middleware.RequirePermission(constant.PermOrderOperate), middleware.Audit(constant.AuditOrder, constant.AuditReprint), orderController.Reprint)
 		// 接单
-		order.PUT("/confirm", orderController.Confirm)
+		order.PUT("/confirm", middleware.RequirePermission(constant.PermOrderOperate), middleware.Audit(constant.AuditOrder, constant.AuditConfirm), middleware.Idempotent(), orderController.Confirm)
 		// 拒单
-		order.PUT("/rejection", orderController.Reject)
+		order.PUT("/rejection", middleware.RequirePermission(constant.PermOrderCancel), middleware.Audit(constant.AuditOrder, constant.AuditReject), middleware.Idempotent(), orderController.Reject)
 		// 取消订单
-		order.PUT("/cancel", orderController.Cancel)
+		order.PUT("/cancel", middleware.RequirePermission(constant.PermOrderCancel), middleware.Audit(constant.AuditOrder, constant.AuditCancel), middleware.Idempotent(), orderController.Cancel)
 		// 派送订单
-		order.PUT("/delivery/:id", orderController.Delivery)
+		order.PUT("/delivery/:id", middleware.RequirePermission(constant.PermOrderOperate), middleware.Audit(constant.AuditOrder, constant.AuditDelivery), middleware.Idempotent(), orderController.Delivery)
 		// 完成订单
-		order.PUT("/complete/:id", orderController.Complete)
+		order.PUT("/complete/:id", middleware.RequirePermission(constant.PermOrderOperate), middleware.Audit(constant.AuditOrder, constant.AuditComplete), middleware.Idempotent(), orderController.Complete)
+		// 指派骑手
+		order.PUT("/assign", middleware.RequirePermission(constant.PermOrderOperate), middleware.Audit(constant.AuditOrder, constant.AuditAssign), orderController.AssignRider)
 	}
 }
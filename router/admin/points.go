@@ -0,0 +1,19 @@
+package admin
+
+import (
+	"takeout/common/constant"
+	"takeout/internal/control/admin"
+	"takeout/internal/middleware"
+)
+
+func (r *AdminRouter) pointsRouter() {
+	points := r.admin.Group("/points")
+	points.Use(middleware.JwtAdmin())
+	{
+		pointsController := admin.NewPointsController()
+		// 分页查询积分流水
+		points.GET("/page", middleware.RequirePermission(constant.PermMemberManage), pointsController.Page)
+		// 人工调整积分
+		points.POST("/adjust", middleware.RequirePermission(constant.PermMemberManage), middleware.Audit(constant.AuditMember, constant.AuditAdjust), pointsController.Adjust)
+	}
+}
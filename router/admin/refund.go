@@ -0,0 +1,19 @@
+package admin
+
+import (
+	"takeout/common/constant"
+	"takeout/internal/control/admin"
+	"takeout/internal/middleware"
+)
+
+func (r *AdminRouter) refundRouter() {
+	refund := r.admin.Group("/refund")
+	refund.Use(middleware.JwtAdmin())
+	{
+		refundController := admin.NewRefundController()
+		// 分页查询退款单
+		refund.GET("/page", middleware.RequirePermission(constant.PermOrderView), refundController.Page)
+		// 重新提交退款
+		refund.POST("/resubmit/:id", middleware.RequirePermission(constant.PermOrderCancel), middleware.Audit(constant.AuditOrder, constant.AuditRefund), middleware.Idempotent(), refundController.Resubmit)
+	}
+}
@@ -1,6 +1,7 @@
 package admin
 
 import (
+	"takeout/common/constant"
 	"takeout/internal/control/admin"
 	"takeout/internal/middleware"
 )
@@ -10,10 +11,11 @@ func (r *AdminRouter) reportRouter() {
 	report.Use(middleware.JwtAdmin())
 	{
 		reportController := admin.NewReportController()
-		report.GET("/turnoverStatistics", reportController.TurnoverStatistics)
-		report.GET("/userStatistics", reportController.UserStatistics)
-		report.GET("/ordersStatistics", reportController.OrderStatistics)
-		report.GET("/top10", reportController.SalesTop10Statistics)
-		report.GET("/export", reportController.Export)
+		report.GET("/turnoverStatistics", middleware.RequirePermission(constant.PermReportView), reportController.TurnoverStatistics)
+		report.GET("/userStatistics", middleware.RequirePermission(constant.PermReportView), reportController.UserStatistics)
+		report.GET("/ordersStatistics", middleware.RequirePermission(constant.PermReportView), reportController.OrderStatistics)
+		report.GET("/top10", middleware.RequirePermission(constant.PermReportView), reportController.SalesTop10Statistics)
+		report.GET("/export", middleware.RequirePermission(constant.PermReportExport), reportController.Export)
+		report.GET("/lowRatedDishes", middleware.RequirePermission(constant.PermReportView), reportController.LowRatedDishes)
 	}
 }
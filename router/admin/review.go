@@ -0,0 +1,21 @@
+package admin
+
+import (
+	"takeout/common/constant"
+	"takeout/internal/control/admin"
+	"takeout/internal/middleware"
+)
+
+func (r *AdminRouter) reviewRouter() {
+	review := r.admin.Group("/review")
+	review.Use(middleware.JwtAdmin())
+	{
+		reviewController := admin.NewReviewController()
+		// 分页查询评价
+		review.GET("/page", middleware.RequirePermission(constant.PermOrderView), reviewController.Page)
+		// 回复评价
+		review.PUT("/reply", middleware.RequirePermission(constant.PermReviewManage), reviewController.Reply)
+		// 显示、隐藏评价
+		review.POST("/status/:status", middleware.RequirePermission(constant.PermReviewManage), reviewController.UpdateStatus)
+	}
+}
@@ -0,0 +1,25 @@
+package admin
+
+import (
+	"takeout/common/constant"
+	"takeout/internal/control/admin"
+	"takeout/internal/middleware"
+)
+
+func (r *AdminRouter) riderRouter() {
+	rider := r.admin.Group("rider")
+	rider.Use(middleware.JwtAdmin())
+	{
+		riderController := admin.NewRiderController()
+		// 新增骑手
+		rider.POST("", middleware.RequirePermission(constant.PermRiderManage), riderController.Create)
+		// 修改骑手信息
+		rider.PUT("", middleware.RequirePermission(constant.PermRiderManage), riderController.Update)
+		// 骑手分页查询
+		rider.GET("/page", middleware.RequirePermission(constant.PermRiderView), riderController.PageQuery)
+		// 启用、禁用骑手
+		rider.POST("/status/:status", middleware.RequirePermission(constant.PermRiderManage), riderController.UpdateStatus)
+		// 查询接单中的骑手
+		rider.GET("/online", middleware.RequirePermission(constant.PermRiderView), riderController.ListOnline)
+	}
+}
@@ -0,0 +1,27 @@
+package admin
+
+import (
+	"takeout/common/constant"
+	"takeout/internal/control/admin"
+	"takeout/internal/middleware"
+)
+
+func (r *AdminRouter) roleRouter() {
+	role := r.admin.Group("role")
+	role.Use(middleware.JwtAdmin(), middleware.RequirePermission(constant.PermRoleManage))
+	{
+		roleController := admin.NewRoleController()
+		// 查询可分配的权限
+		role.GET("/permissions", roleController.Permissions)
+		// 查询全部角色
+		role.GET("/list", roleController.List)
+		// 查询角色详情
+		role.GET("/:id", roleController.GetByID)
+		// 新增角色
+		role.POST("", middleware.Audit(constant.AuditRole, constant.AuditCreate), roleController.Create)
+		// 修改角色
+		role.PUT("", middleware.Audit(constant.AuditRole, constant.AuditUpdate), roleController.Update)
+		// 删除角色
+		role.DELETE("/:id", middleware.Audit(constant.AuditRole, constant.AuditDelete), roleController.Delete)
+	}
+}
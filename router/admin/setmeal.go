@@ -1,6 +1,7 @@
 package admin
 
 import (
+	"takeout/common/constant"
 	"takeout/internal/control/admin"
 	"takeout/internal/middleware"
 )
@@ -12,16 +13,16 @@ func (r *AdminRouter) setmealRouter() {
 	setmeal.Use(middleware.JwtAdmin())
 	{
 		// 新增套餐
-		setmeal.POST("", setmealService.Create)
+		setmeal.POST("", middleware.RequirePermission(constant.PermMenuManage), middleware.Audit(constant.AuditSetmeal, constant.AuditCreate), setmealService.Create)
 		// 根据ID获取套餐的详细信息
-		setmeal.GET("/:id", setmealService.GetByID)
+		setmeal.GET("/:id", middleware.RequirePermission(constant.PermMenuView), setmealService.GetByID)
 		// 批量删除
-		setmeal.DELETE("", setmealService.BatchDelete)
+		setmeal.DELETE("", middleware.RequirePermission(constant.PermMenuManage), middleware.Audit(constant.AuditSetmeal, constant.AuditDelete), setmealService.BatchDelete)
 		// 分页查询
-		setmeal.GET("/page", setmealService.PageQuery)
+		setmeal.GET("/page", middleware.RequirePermission(constant.PermMenuView), setmealService.PageQuery)
 		// 修改套餐
-		setmeal.PUT("", setmealService.Update)
+		setmeal.PUT("", middleware.RequirePermission(constant.PermMenuManage), middleware.Audit(constant.AuditSetmeal, constant.AuditUpdate), setmealService.Update)
 		// 更改套餐状态
-		setmeal.POST("/status/:status", setmealService.UpdateStatus)
+		setmeal.POST("/status/:status", middleware.RequirePermission(constant.PermMenuManage), middleware.Audit(constant.AuditSetmeal, constant.AuditStatus), setmealService.UpdateStatus)
 	}
 }
@@ -1,6 +1,7 @@
 package admin
 
 import (
+	"takeout/common/constant"
 	"takeout/internal/control/admin"
 	"takeout/internal/middleware"
 )
@@ -11,8 +12,20 @@ func (r *AdminRouter) shopRouter() {
 	{
 		shopController := admin.NewShopController()
 		// 设置店铺状态
-		shop.PUT("/:status", shopController.SetStatus)
+		shop.PUT("/:status", middleware.RequirePermission(constant.PermShopManage), middleware.Audit(constant.AuditShop, constant.AuditStatus), shopController.SetStatus)
 		// 获取店铺状态
 		shop.GET("/status", shopController.GetStatus)
+		// 获取实际营业状态
+		shop.GET("/effectiveStatus", shopController.GetEffectiveStatus)
+		// 每周营业时间
+		shop.GET("/hours", middleware.RequirePermission(constant.PermShopManage), shopController.ListHours)
+		shop.PUT("/hours", middleware.RequirePermission(constant.PermShopManage), middleware.Audit(constant.AuditShop, constant.AuditUpdate), shopController.SaveHours)
+		// 节假日、特殊日期营业安排
+		shop.GET("/specialDay", middleware.RequirePermission(constant.PermShopManage), shopController.ListSpecialDays)
+		shop.PUT("/specialDay", middleware.RequirePermission(constant.PermShopManage), middleware.Audit(constant.AuditShop, constant.AuditUpdate), shopController.SaveSpecialDay)
+		shop.DELETE("/specialDay", middleware.RequirePermission(constant.PermShopManage), middleware.Audit(constant.AuditShop, constant.AuditUpdate), shopController.DeleteSpecialDay)
+		// 忙碌暂停接单、恢复接单
+		shop.POST("/pause", middleware.RequirePermission(constant.PermShopManage), middleware.Audit(constant.AuditShop, constant.AuditStatus), shopController.Pause)
+		shop.DELETE("/pause", middleware.RequirePermission(constant.PermShopManage), middleware.Audit(constant.AuditShop, constant.AuditStatus), shopController.Resume)
 	}
 }
@@ -0,0 +1,19 @@
+package admin
+
+import (
+	"takeout/common/constant"
+	"takeout/internal/control/admin"
+	"takeout/internal/middleware"
+)
+
+func (r *AdminRouter) walletRouter() {
+	wallet := r.admin.Group("/wallet")
+	wallet.Use(middleware.JwtAdmin())
+	{
+		walletController := admin.NewWalletController()
+		// 分页查询余额流水
+		wallet.GET("/page", middleware.RequirePermission(constant.PermMemberManage), walletController.Page)
+		// 人工调整余额
+		wallet.POST("/adjust", middleware.RequirePermission(constant.PermMemberManage), middleware.Audit(constant.AuditWallet, constant.AuditAdjust), walletController.Adjust)
+	}
+}
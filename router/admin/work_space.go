@@ -1,13 +1,14 @@
 package admin
 
 import (
+	"takeout/common/constant"
 	"takeout/internal/control/admin"
 	"takeout/internal/middleware"
 )
 
 func (r *AdminRouter) workSpaceRouter() {
 	workspace := r.admin.Group("/workspace")
-	workspace.Use(middleware.JwtAdmin())
+	workspace.Use(middleware.JwtAdmin(), middleware.RequirePermission(constant.PermReportView))
 	{
 		workSpaceController := admin.NewWorkSpaceController()
 		workspace.GET("/businessData", workSpaceController.BusinessData)
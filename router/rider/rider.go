@@ -0,0 +1,47 @@
+package rider
+
+import (
+	"takeout/internal/control/rider"
+	"takeout/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RiderRouter 骑手端路由
+type RiderRouter struct {
+	rider *gin.RouterGroup
+}
+
+func NewRiderRouter(rider *gin.RouterGroup) *RiderRouter {
+	return &RiderRouter{rider: rider}
+}
+
+// RegisterRouters 骑手端注册路由
+func (r *RiderRouter) RegisterRouters() {
+	riderController := rider.NewRiderController()
+	// 骑手登录
+	r.rider.POST("/login", riderController.Login)
+	// 刷新令牌
+	r.rider.POST("/refresh", riderController.Refresh)
+
+	auth := r.rider.Group("")
+	auth.Use(middleware.JwtRider())
+	{
+		// 退出登录
+		auth.POST("/logout", riderController.Logout)
+		// 切换接单、休息状态
+		auth.PUT("/status/:status", riderController.UpdateWorkStatus)
+		// 上报位置
+		auth.POST("/location", riderController.ReportLocation)
+		// 查询指派给自己的订单
+		auth.GET("/order/list", riderController.Orders)
+		// 接受指派
+		auth.PUT("/order/accept/:id", middleware.Idempotent(), riderController.Accept)
+		// 拒绝指派
+		auth.PUT("/order/reject/:id", middleware.Idempotent(), riderController.Reject)
+		// 到店取餐
+		auth.PUT("/order/pickup/:id", middleware.Idempotent(), riderController.Pickup)
+		// 确认送达
+		auth.PUT("/order/deliver/:id", middleware.Idempotent(), riderController.Deliver)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"takeout/internal/websocket"
 	"takeout/router/admin"
 	"takeout/router/notify"
+	"takeout/router/rider"
 	"takeout/router/user"
 
 	"github.com/gin-gonic/gin"
@@ -20,7 +21,7 @@ func InitRouter() *gin.Engine {
 	r := gin.New()
 
 	// 使用自定义中间件
-	r.Use(middleware2.LoggerMiddleware(), middleware2.RecoveryMiddleware())
+	r.Use(middleware2.RequestID(), middleware2.LoggerMiddleware(), middleware2.RecoveryMiddleware())
 
 	// 健康检查
 	//r.GET("/ping", func(c *gin.Context) {
@@ -46,6 +47,13 @@ func InitRouter() *gin.Engine {
 			userRouter.RegisterRouters()
 		}
 
+		// 骑手端API
+		riderGroup := api.Group("/rider")
+		{
+			riderRouter := rider.NewRiderRouter(riderGroup)
+			riderRouter.RegisterRouters()
+		}
+
 		// 微信回调API
 		notifyGroup := api.Group("/notify")
 		{
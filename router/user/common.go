@@ -0,0 +1,16 @@
+package user
+
+import (
+	"takeout/internal/control/admin"
+	"takeout/internal/middleware"
+)
+
+func (r *UserRouter) commonRouter() {
+	common := r.user.Group("/common")
+	common.Use(middleware.JwtUser())
+	{
+		// 与商家端共用上传接口，用于评价图片
+		commonController := admin.NewCommonController()
+		common.POST("/upload", commonController.Upload)
+	}
+}
@@ -0,0 +1,20 @@
+package user
+
+import (
+	"takeout/internal/control/user"
+	"takeout/internal/middleware"
+)
+
+func (r *UserRouter) couponRouter() {
+	coupon := r.user.Group("/coupon")
+	coupon.Use(middleware.JwtUser())
+	{
+		couponController := user.NewCouponController()
+		// 查询可领取的优惠券
+		coupon.GET("/claimable", couponController.Claimable)
+		// 领取优惠券
+		coupon.POST("/claim/:id", couponController.Claim)
+		// 查询我的优惠券
+		coupon.GET("/list", couponController.List)
+	}
+}
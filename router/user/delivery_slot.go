@@ -0,0 +1,14 @@
+package user
+
+import (
+	"takeout/internal/control/user"
+)
+
+func (r *UserRouter) deliverySlotRouter() {
+	slot := r.user.Group("/deliverySlot")
+	{
+		slotController := user.NewDeliverySlotController()
+		// 查询今天和明天可预约的配送时段
+		slot.GET("/list", slotController.Available)
+	}
+}
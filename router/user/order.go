@@ -11,9 +11,9 @@ func (r *UserRouter) orderRouter() {
 	{
 		orderController := user.NewOrderController()
 		// 提交订单
-		order.POST("/submit", orderController.Submit)
+		order.POST("/submit", middleware.Idempotent(), orderController.Submit)
 		// 订单支付
-		order.PUT("/payment", orderController.Payment)
+		order.PUT("/payment", middleware.Idempotent(), orderController.Payment)
 		// 历史订单查询
 		order.GET("/historyOrders", orderController.Page)
 		// 查询订单详细信息
@@ -24,5 +24,7 @@ func (r *UserRouter) orderRouter() {
 		order.POST("/repetition/:id", orderController.Repetition)
 		// 用户催单
 		order.GET("/reminder/:id", orderController.Reminder)
+		// 订单状态推送(SSE)
+		order.GET("/events", orderController.Events)
 	}
 }
@@ -0,0 +1,18 @@
+package user
+
+import (
+	"takeout/internal/control/user"
+	"takeout/internal/middleware"
+)
+
+func (r *UserRouter) pointsRouter() {
+	points := r.user.Group("/points")
+	points.Use(middleware.JwtUser())
+	{
+		pointsController := user.NewPointsController()
+		// 查询积分余额和会员等级
+		points.GET("", pointsController.Info)
+		// 分页查询积分流水
+		points.GET("/page", pointsController.Page)
+	}
+}
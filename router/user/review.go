@@ -0,0 +1,18 @@
+package user
+
+import (
+	"takeout/internal/control/user"
+	"takeout/internal/middleware"
+)
+
+func (r *UserRouter) reviewRouter() {
+	review := r.user.Group("/review")
+	review.Use(middleware.JwtUser())
+	{
+		reviewController := user.NewReviewController()
+		// 评价已完成的订单
+		review.POST("", reviewController.Submit)
+		// 查看订单的评价
+		review.GET("/order/:orderId", reviewController.GetByOrder)
+	}
+}
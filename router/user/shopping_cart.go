@@ -18,5 +18,15 @@ func (r *UserRouter) shoppingCartRouter() {
 		shoppingCart.DELETE("/clean", shoppingCartController.Clean)
 		// 删除购物车中的一个商品
 		shoppingCart.POST("/sub", shoppingCartController.Sub)
+		// 修改购物车中一行的数量
+		shoppingCart.PUT("/number", shoppingCartController.UpdateNumber)
+		// 购物车汇总
+		shoppingCart.GET("/summary", shoppingCartController.Summary)
+		// 下单前校验购物车，确认变动的价格
+		shoppingCart.POST("/validate", shoppingCartController.Validate)
+		// 购物车最优优惠券预览
+		shoppingCart.GET("/bestCoupon", shoppingCartController.BestCoupon)
+		// 购物车结算预览，含配送费和起送金额
+		shoppingCart.GET("/preview", shoppingCartController.Preview)
 	}
 }
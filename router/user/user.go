@@ -28,4 +28,16 @@ func (r *UserRouter) RegisterRouters() {
 	r.addressBookRouter()
 	// 订单路由
 	r.orderRouter()
+	// 优惠券路由
+	r.couponRouter()
+	// 配送时段路由
+	r.deliverySlotRouter()
+	// 评价路由
+	r.reviewRouter()
+	// 会员积分路由
+	r.pointsRouter()
+	// 余额路由
+	r.walletRouter()
+	// 文件上传路由
+	r.commonRouter()
 }
@@ -0,0 +1,20 @@
+package user
+
+import (
+	"takeout/internal/control/user"
+	"takeout/internal/middleware"
+)
+
+func (r *UserRouter) walletRouter() {
+	wallet := r.user.Group("/wallet")
+	wallet.Use(middleware.JwtUser())
+	{
+		walletController := user.NewWalletController()
+		// 查询余额
+		wallet.GET("", walletController.Info)
+		// 分页查询余额流水
+		wallet.GET("/page", walletController.Page)
+		// 余额充值
+		wallet.POST("/recharge", middleware.Idempotent(), walletController.Recharge)
+	}
+}
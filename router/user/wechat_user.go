@@ -8,6 +8,7 @@ import (
 func (r *UserRouter) weChatUserRouter() {
 	userController := user.NewWeChatUserController()
 	r.user.POST("/user/login", userController.Login)
+	r.user.POST("/user/refresh", userController.Refresh)
 	weChatUser := r.user.Group("/user")
 	weChatUser.Use(middleware.JwtUser())
 	{